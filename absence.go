@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AbsenceRule marks the days with an all-day event whose summary contains
+// Keyword (case-insensitively) as absent, e.g. {"keyword": "Vacation",
+// "type": "pto"}. Absent days report no chunks and have no target hours.
+type AbsenceRule struct {
+	Keyword string `json:"keyword"`
+	// Type names the absence, the lowercased keyword if empty.
+	Type string `json:"type"`
+}
+
+func (r AbsenceRule) typ() string {
+	if r.Type != "" {
+		return r.Type
+	}
+	return strings.ToLower(r.Keyword)
+}
+
+// absenceOf returns the type of the first rule an all-day event of the day
+// matches, "" if the day isn't an absence.
+func absenceOf(items []*Event, rules []AbsenceRule) string {
+	for _, r := range rules {
+		for _, e := range items {
+			if e.AllDay && strings.Contains(strings.ToLower(e.Summary), strings.ToLower(r.Keyword)) {
+				return r.typ()
+			}
+		}
+	}
+	return ""
+}
+
+// absenceOutOfOffice is the type of the days an out-of-office event spans
+// the workday of.
+const absenceOutOfOffice = "out-of-office"
+
+// outOfOffice reports whether an out-of-office event spans the workday lo-hi.
+func outOfOffice(items []*Event, lo, hi time.Time) bool {
+	if !lo.Before(hi) {
+		return false
+	}
+	for _, e := range items {
+		if e.Type == eventOutOfOffice && e.Status != "cancelled" && !e.Start.After(lo) && !e.End.Before(hi) {
+			return true
+		}
+	}
+	return false
+}
+
+// absences are the absent days buildDay came across, by date in dateLayout.
+type absences struct {
+	mu   sync.Mutex
+	days map[string]string
+}
+
+// absences returns the options' absent days, creating them on first use.
+// buildDays does so before its workers share them.
+func (o *reportOptions) absences() *absences {
+	if o.absent == nil {
+		o.absent = &absences{days: map[string]string{}}
+	}
+	return o.absent
+}
+
+func (a *absences) set(date time.Time, typ string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.days[date.Format(dateLayout)] = typ
+}
+
+// absence returns the absence type of a day built with the options, "" if
+// it wasn't an absence.
+func (o *reportOptions) absence(date time.Time) string {
+	a := o.absences()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.days[date.Format(dateLayout)]
+}
+
+// absenceCounts describes the absent days of records by type, e.g. "2 days
+// of pto, 1 day of sick", "" if there are none.
+func absenceCounts(records []*DayRecord) string {
+	counts := map[string]int{}
+	for _, record := range records {
+		if record.Absence != "" {
+			counts[record.Absence]++
+		}
+	}
+	types := make([]string, 0, len(counts))
+	for typ := range counts {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	parts := make([]string, 0, len(types))
+	for _, typ := range types {
+		days := "days"
+		if counts[typ] == 1 {
+			days = "day"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s of %s", counts[typ], days, typ))
+	}
+	return strings.Join(parts, ", ")
+}
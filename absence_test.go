@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_absenceOf(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	rules := []AbsenceRule{{Keyword: "Sick"}, {Keyword: "Vacation", Type: "pto"}, {Keyword: "PTO"}}
+
+	tests := []struct {
+		name     string
+		items    []*Event
+		expected string
+	}{
+		{name: "no absence", items: []*Event{newAllDayEvent(date, "Offsite")}, expected: ""},
+		{name: "keyword as type", items: []*Event{newAllDayEvent(date, "Out sick")}, expected: "sick"},
+		{name: "named type", items: []*Event{newAllDayEvent(date, "Summer vacation")}, expected: "pto"},
+		{name: "first rule wins", items: []*Event{newAllDayEvent(date, "PTO"), newAllDayEvent(date, "Sick")}, expected: "sick"},
+		{name: "timed event", items: []*Event{newEvent(date.Add(9*time.Hour), date.Add(10*time.Hour), "Sick leave policy review", "accepted", true)}, expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if absence := absenceOf(test.items, rules); absence != test.expected {
+				t.Errorf("expected '%s', got '%s'", test.expected, absence)
+			}
+		})
+	}
+}
+
+func Test_absenceCounts(t *testing.T) {
+	records := []*DayRecord{{Absence: "sick"}, {Hours: 8}, {Absence: "pto"}, {Absence: "pto"}}
+	if counts := absenceCounts(records); counts != "2 days of pto, 1 day of sick" {
+		t.Errorf("expected '2 days of pto, 1 day of sick', got '%s'", counts)
+	}
+}
+
+func Test_reportOptions_absence(t *testing.T) {
+	dir := t.TempDir()
+	from := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	files := map[string]string{
+		"events.json": `[
+			{"summary": "Standup", "start": "2024-03-11T09:00:00Z", "end": "2024-03-11T09:15:00Z"},
+			{"summary": "Standup", "start": "2024-03-12T09:00:00Z", "end": "2024-03-12T09:15:00Z"},
+			{"summary": "Sick", "start": "2024-03-12", "end": "2024-03-13"}
+		]`,
+		"config.json": `{"absences": [{"keyword": "sick"}]}`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	opts := &reportOptions{input: filepath.Join(dir, "events.json"), config: filepath.Join(dir, "config.json"), history: filepath.Join(dir, "history.json"), tentative: tentativeInclude, workers: 2}
+	days, err := opts.buildDays(context.Background(), nil, from, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(days[0]) == 0 || opts.absence(from) != "" {
+		t.Errorf("expected Monday to be worked, got %d chunks and absence '%s'", len(days[0]), opts.absence(from))
+	}
+	if len(days[1]) != 0 || opts.absence(from.AddDate(0, 0, 1)) != "sick" {
+		t.Errorf("expected Tuesday to be a sick day without chunks, got %d chunks and absence '%s'", len(days[1]), opts.absence(from.AddDate(0, 0, 1)))
+	}
+}
+
+func Test_outOfOffice(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	lo, hi := date.Add(9*time.Hour), date.Add(17*time.Hour)
+
+	tests := []struct {
+		name     string
+		event    *Event
+		expected bool
+	}{
+		{name: "whole workday", event: &Event{Type: eventOutOfOffice, Start: date.Add(-24 * time.Hour), End: date.Add(48 * time.Hour)}, expected: true},
+		{name: "afternoon", event: &Event{Type: eventOutOfOffice, Start: date.Add(13 * time.Hour), End: hi}, expected: false},
+		{name: "cancelled", event: &Event{Type: eventOutOfOffice, Status: "cancelled", Start: lo, End: hi}, expected: false},
+		{name: "ordinary event", event: &Event{Start: lo, End: hi}, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if away := outOfOffice([]*Event{test.event}, lo, hi); away != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, away)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// runAnnotate implements the `annotate` subcommand, which names gap chunks
+// after the fact. Given a time and text it annotates the gap at that time,
+// otherwise it prompts for each gap of the day. Annotations are kept in the
+// local history so re-rendering the day keeps them.
+func runAnnotate(args []string) {
+	fs := flag.NewFlagSet("annotate", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `usage: chunkit annotate [-date date] [HH:MM "what the gap was"]`)
+		fs.PrintDefaults()
+	}
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+	date, err := opts.reportDate()
+	if err != nil {
+		fatal(err)
+	}
+	day := date.Format(dateLayout)
+
+	var annotations []TimedNote
+	if fs.NArg() > 0 {
+		if fs.NArg() < 2 {
+			fs.Usage()
+			os.Exit(exitUsage)
+		}
+		at, err := time.ParseInLocation("2006-01-02 15:04", day+" "+fs.Arg(0), date.Location())
+		if err != nil {
+			fatal(withExitCode(exitUsage, fmt.Errorf("invalid time %q: must be HH:MM", fs.Arg(0))))
+		}
+		annotations = append(annotations, TimedNote{At: at, Text: strings.Join(fs.Args()[1:], " ")})
+	} else {
+		if annotations, err = opts.promptGaps(); err != nil {
+			fatal(err)
+		}
+	}
+
+	err = updateStore(opts.history, func(s *Store) {
+		s.Annotations[day] = append(s.Annotations[day], annotations...)
+	})
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("Annotated %d gaps of %s.\n", len(annotations), day)
+}
+
+// promptGaps asks what each gap of the -date was, returning the answers. An
+// empty answer leaves a gap as it is.
+func (o *reportOptions) promptGaps() ([]TimedNote, error) {
+	_, chunks, err := o.build(signalContext())
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations []TimedNote
+	in := bufio.NewReader(os.Stdin)
+	for _, chunk := range chunks {
+		if chunk.Event != nil {
+			continue
+		}
+		fmt.Printf("%s-%s [%s]: ", formatTime(chunk.start), formatTime(chunk.end), chunk.notes)
+		line, err := in.ReadString('\n')
+		if text := strings.TrimSpace(line); text != "" {
+			annotations = append(annotations, TimedNote{At: chunk.start, Text: text})
+		}
+		if err != nil {
+			break // end of input
+		}
+	}
+	return annotations, nil
+}
+
+// fillGaps gives the gap chunks without notes or a project the defaults.
+func fillGaps(chunks []*Chunk, defaults GapDefault) {
+	for _, chunk := range chunks {
+		if chunk.Event != nil {
+			continue
+		}
+		filled := false
+		if chunk.notes == "" && defaults.Notes != "" {
+			chunk.notes = defaults.Notes
+			chunk.explain("named by the gap default")
+			filled = true
+		}
+		if chunk.project == "" && defaults.Project != "" {
+			chunk.project = defaults.Project
+			chunk.explain("project %s by the gap default", defaults.Project)
+			filled = true
+		}
+		if filled {
+			chunk.matched("gap")
+		}
+	}
+}
+
+// annotateGaps names the gap chunk covering each annotation's time after it,
+// replacing any notes it had; the latest annotation of a gap wins.
+func annotateGaps(chunks []*Chunk, annotations []TimedNote) {
+	for _, annotation := range annotations {
+		for _, chunk := range chunks {
+			if chunk.Event != nil || annotation.At.Before(chunk.start) || !annotation.At.Before(chunk.end) {
+				continue
+			}
+			chunk.notes = annotation.Text
+			chunk.explain("annotated as %q", annotation.Text)
+			break
+		}
+	}
+}
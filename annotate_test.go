@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_annotateGaps(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	standup := newEvent(date.Add(9*time.Hour), date.Add(10*time.Hour), "Standup", "accepted", true)
+	chunks := []*Chunk{
+		{Event: standup, start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Standup"},
+		{start: date.Add(10 * time.Hour), end: date.Add(12 * time.Hour), notes: "Standup"},
+		{start: date.Add(13 * time.Hour), end: date.Add(17 * time.Hour)},
+	}
+
+	annotateGaps(chunks, []TimedNote{
+		{At: date.Add(9*time.Hour + 30*time.Minute), Text: "ignored: not a gap"},
+		{At: date.Add(10 * time.Hour), Text: "Code review"},
+		{At: date.Add(14 * time.Hour), Text: "Deep work"},
+		{At: date.Add(15 * time.Hour), Text: "Deep work on the parser"},
+	})
+
+	expected := []string{"Standup", "Code review", "Deep work on the parser"}
+	for i, chunk := range chunks {
+		if chunk.notes != expected[i] {
+			t.Errorf("expected chunk %d to be %q, got %q", i, expected[i], chunk.notes)
+		}
+	}
+}
+
+func Test_fillGaps(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	standup := newEvent(date.Add(9*time.Hour), date.Add(10*time.Hour), "Standup", "accepted", true)
+	chunks := []*Chunk{
+		{Event: standup, start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Standup"},
+		{start: date.Add(10 * time.Hour), end: date.Add(12 * time.Hour), notes: "Standup"},
+		{start: date.Add(13 * time.Hour), end: date.Add(17 * time.Hour)},
+	}
+
+	fillGaps(chunks, GapDefault{Notes: "Development", Project: "ACME"})
+
+	expected := []struct{ notes, project string }{
+		{"Standup", ""},
+		{"Standup", "ACME"},
+		{"Development", "ACME"},
+	}
+	for i, chunk := range chunks {
+		if chunk.notes != expected[i].notes || chunk.project != expected[i].project {
+			t.Errorf("expected chunk %d to be %q under %q, got %q under %q", i, expected[i].notes, expected[i].project, chunk.notes, chunk.project)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// anomalyRatio is how far a workday's hours of events may stray from the
+	// usual ones, as a share of them, before it is flagged.
+	anomalyRatio = 0.5
+	// minUsualDays is how many recorded workdays it takes to know what's
+	// usual.
+	minUsualDays = 5
+)
+
+// usualHours is the median of the hours allocated to events on the recorded
+// workdays of the schedule, leaving out those in skip, absences and days
+// without events. ok is false while there are too few of them.
+func usualHours(store *Store, skip map[string]bool, schedule Schedule) (hours float64, ok bool) {
+	var totals []float64
+	for date, day := range store.Days {
+		d, err := time.Parse(dateLayout, date)
+		if err != nil || skip[date] || !schedule.works(d.Weekday()) || day.Absence != "" || day.Allocated == 0 {
+			continue
+		}
+		totals = append(totals, day.Allocated)
+	}
+	if len(totals) < minUsualDays {
+		return 0, false
+	}
+	sort.Float64s(totals)
+	mid := len(totals) / 2
+	if len(totals)%2 == 0 {
+		return (totals[mid-1] + totals[mid]) / 2, true
+	}
+	return totals[mid], true
+}
+
+// anomalies describes the recorded workdays, by date, whose hours allocated
+// to events stray far from the usual hours, a hint that their calendar is
+// incomplete: gaps fill the rest of the workday, so totals can't tell.
+// Absences are left alone.
+func anomalies(records map[string]*DayRecord, usual float64, schedule Schedule) []string {
+	dates := make([]string, 0, len(records))
+	for date := range records {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	var found []string
+	for _, date := range dates {
+		d, err := time.Parse(dateLayout, date)
+		record := records[date]
+		if err != nil || !schedule.works(d.Weekday()) || record.Absence != "" {
+			continue
+		}
+		if math.Abs(record.Allocated-usual) > usual*anomalyRatio {
+			found = append(found, fmt.Sprintf("%s has %.2f hours of events against your usual %.2f; is its calendar complete?", date, record.Allocated, usual))
+		}
+	}
+	return found
+}
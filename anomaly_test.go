@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_usualHours(t *testing.T) {
+	store := &Store{Days: map[string]*DayRecord{
+		"2024-03-04": {Hours: 8, Allocated: 6},
+		"2024-03-05": {Hours: 8, Allocated: 5.5},
+		"2024-03-06": {Hours: 8}, // no events
+		"2024-03-07": {Hours: 9, Allocated: 7},
+		"2024-03-08": {Hours: 8, Allocated: 6},
+		"2024-03-09": {Hours: 1, Allocated: 1}, // Saturday
+		"2024-03-10": {Hours: 6, Allocated: 6}, // Sunday, worked
+		"2024-03-11": {Hours: 8, Allocated: 2},
+		"2024-03-12": {Hours: 8, Allocated: 6.5},
+		"2024-03-13": {Absence: "pto"},
+	}}
+
+	usual, ok := usualHours(store, map[string]bool{"2024-03-11": true}, nil)
+	if !ok || usual != 6 {
+		t.Errorf("expected usual hours of 6, got %.2f (ok %t)", usual, ok)
+	}
+
+	// a schedule working Sundays counts them
+	usual, ok = usualHours(store, map[string]bool{"2024-03-11": true}, Schedule{"sun": {Start: "09:00", End: "17:00"}})
+	if !ok || usual != 6 {
+		t.Errorf("expected usual hours of 6, got %.2f (ok %t)", usual, ok)
+	}
+
+	if _, ok := usualHours(store, map[string]bool{"2024-03-11": true, "2024-03-12": true}, nil); ok {
+		t.Errorf("expected too few workdays to know the usual hours")
+	}
+}
+
+func Test_anomalies(t *testing.T) {
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	config := &Config{}
+	day := func(date time.Time, hours float64) *DayRecord {
+		d := time.Duration(hours * float64(time.Hour))
+		events := []*Event{newEvent(date.Add(9*time.Hour), date.Add(9*time.Hour+d), "Work", "accepted", true)}
+		return newDayRecord(date, Chunkify(date, events, Options{}), config, "")
+	}
+	records := map[string]*DayRecord{
+		"2024-03-11": day(monday, 6),
+		"2024-03-12": day(monday.AddDate(0, 0, 1), 2.5), // totals 8 with the gaps
+		"2024-03-13": day(monday.AddDate(0, 0, 2), 13),
+		"2024-03-14": newDayRecord(monday.AddDate(0, 0, 3), nil, config, "pto"),
+		"2024-03-15": newDayRecord(monday.AddDate(0, 0, 4), Chunkify(monday.AddDate(0, 0, 4), nil, Options{}), config, ""), // empty calendar
+		"2024-03-16": day(monday.AddDate(0, 0, 5), 1),                                                                      // Saturday
+	}
+
+	found := anomalies(records, 6, nil)
+
+	expected := []string{
+		"2024-03-12 has 2.50 hours of events against your usual 6.00; is its calendar complete?",
+		"2024-03-13 has 13.00 hours of events against your usual 6.00; is its calendar complete?",
+		"2024-03-15 has 0.00 hours of events against your usual 6.00; is its calendar complete?",
+	}
+	if len(found) != len(expected) {
+		t.Fatalf("expected %d anomalies, got %v", len(expected), found)
+	}
+	for i := range expected {
+		if found[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], found[i])
+		}
+	}
+}
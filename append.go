@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// appendHeader is the header of the -append CSV, a row per chunk of every
+// day appended to it.
+var appendHeader = []string{"date", "start", "end", "hours", "notes", "project", "event_id"}
+
+// appendCSV adds the reports' rows to the CSV at path, creating it if need
+// be, and returns how many it added. Rows already there, by date and event
+// ID, or start for gaps, are skipped, so appending a day again is harmless.
+// The file is replaced atomically like -o.
+func appendCSV(path string, reports []*Report, timeFormat string) (int, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("error reading the -append file: %v", err)
+	}
+	records, err := parseDataset(path, existing)
+	if err != nil {
+		return 0, err
+	}
+	seen := map[string]bool{}
+	for _, record := range records {
+		seen[appendKey(record[0], record[1], record[6])] = true
+	}
+
+	buf := bytes.NewBuffer(existing)
+	w := csv.NewWriter(buf)
+	if len(existing) == 0 {
+		w.Write(appendHeader)
+	}
+	added := 0
+	for _, r := range reports {
+		for _, row := range r.Rows {
+			record := datasetRecord(r.Date, row, timeFormat)
+			if seen[appendKey(record[0], record[1], record[6])] {
+				continue
+			}
+			w.Write(record)
+			added++
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return 0, fmt.Errorf("error writing the -append file: %v", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("error writing the -append file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("error writing the -append file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("error writing the -append file: %v", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return 0, fmt.Errorf("error writing the -append file: %v", err)
+	}
+	return added, nil
+}
+
+// datasetRecord is a row of a report as a row of the -append CSV.
+func datasetRecord(date time.Time, row Row, timeFormat string) []string {
+	return []string{
+		date.Format(dateLayout),
+		formatTimeAs(timeFormat, date, row.Start),
+		formatTimeAs(timeFormat, date, row.End),
+		fmt.Sprintf("%.2f", row.Duration().Hours()),
+		row.Notes,
+		row.Project,
+		row.EventID,
+	}
+}
+
+// parseDatasetTime parses a start or end of the day date saved in any
+// -time-format, to the minute.
+func parseDatasetTime(date time.Time, value string) (time.Time, error) {
+	at := func(minutes int) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), minutes/60, minutes%60, 0, 0, date.Location())
+	}
+	switch {
+	case strings.Contains(value, "T"):
+		t, err := time.Parse(time.RFC3339, value)
+		return t.In(date.Location()).Truncate(time.Minute), err
+	case strings.Contains(value, ":"):
+		clock, err := parseClock(value)
+		return at(int(clock.Minutes())), err
+	case strings.HasSuffix(value, "h") || strings.HasSuffix(value, "m"):
+		d, err := time.ParseDuration(value)
+		return date.Add(d), err
+	default:
+		hours, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q isn't a time", value)
+		}
+		return at(int(math.Round(hours * 60))), nil
+	}
+}
+
+// readDataset returns the rows of the -append CSV at path, without its
+// header.
+func readDataset(path string) ([][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the dataset: %v", err)
+	}
+	return parseDataset(path, data)
+}
+
+func parseDataset(path string, data []byte) ([][]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the dataset: %v", err)
+	}
+	if len(records) == 0 || len(records[0]) != len(appendHeader) || records[0][0] != appendHeader[0] {
+		return nil, fmt.Errorf("error reading the dataset: %s is not a chunkit dataset", path)
+	}
+	return records[1:], nil
+}
+
+// appendKey identifies a row of the -append CSV: a day's event, or its gap
+// starting at start.
+func appendKey(date, start, eventID string) string {
+	if eventID != "" {
+		return date + " " + eventID
+	}
+	return date + " gap " + start
+}
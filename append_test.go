@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_appendCSV(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Standup", project: "ACME", Event: &Event{ID: "abc"}},
+		{start: date.Add(10 * time.Hour), end: date.Add(11*time.Hour + 30*time.Minute)},
+	}
+	report := newReport(date, chunks, reportColumns{notes: true, project: true})
+	next := newReport(date.AddDate(0, 0, 1), chunks[:0], reportColumns{})
+	path := filepath.Join(t.TempDir(), "2024.csv")
+
+	tests := []struct {
+		name    string
+		reports []*Report
+		added   int
+	}{
+		{name: "new file", reports: []*Report{report}, added: 2},
+		{name: "same day again", reports: []*Report{report}, added: 0},
+		{name: "empty day", reports: []*Report{next}, added: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, err := appendCSV(path, tt.reports, timeDecimal)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if added != tt.added {
+				t.Errorf("expected %d rows added, got %d", tt.added, added)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `date,start,end,hours,notes,project,event_id
+2024-03-11,09.00,10.00,1.00,Standup,ACME,abc
+2024-03-11,10.00,11.50,1.50,,,
+`
+	if string(data) != expected {
+		t.Errorf("expected file:\n%s\ngot:\n%s", expected, data)
+	}
+
+	if err := os.WriteFile(path, []byte("start,end\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := appendCSV(path, []*Report{report}, timeDecimal); err == nil {
+		t.Errorf("expected an error for a CSV that isn't a dataset, got none")
+	}
+}
+
+func Test_parseDatasetTime(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	expected := date.Add(10*time.Hour + 20*time.Minute)
+	for _, layout := range []string{timeDecimal, timeClock, timeISO, timeDuration} {
+		value := formatTimeAs(layout, date, expected)
+		parsed, err := parseDatasetTime(date, value)
+		if err != nil || !parsed.Equal(expected) {
+			t.Errorf("expected %q in %s to parse as %s, got %s (%v)", value, layout, expected, parsed, err)
+		}
+	}
+	if _, err := parseDatasetTime(date, "soon"); err == nil {
+		t.Error("expected an error for a value that isn't a time")
+	}
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/calendar/v3"
+)
+
+// runAuth implements the `auth` subcommand, which (re-)authenticates and
+// saves a fresh token without generating a report.
+func runAuth(args []string) {
+	// `auth login` reads better in scripts recovering from a revoked login
+	if len(args) > 0 && args[0] == "login" {
+		args = args[1:]
+	}
+	fs := flag.NewFlagSet("auth", flag.ExitOnError)
+	credentialsPath := fs.String("credentials", filepath.Join(configDir(), "credentials.json"), "Path to the Google OAuth client credentials file")
+	tokenPath := fs.String("token", filepath.Join(configDir(), "token.json"), "Path to the cached OAuth token file")
+	noBrowser := fs.Bool("no-browser", false, "Paste the authorization code manually instead of running a local redirect server (for SSH sessions and containers)")
+	login := &loginOptions{}
+	login.register(fs)
+	reset := fs.Bool("reset", false, "Forget the scopes granted so far and only ask for read access to events; commands needing more ask again")
+	fs.Parse(args)
+
+	ctx := signalContext()
+	config, err := readConfig(*credentialsPath)
+	if err != nil {
+		fatal(withExitCode(exitAuth, err))
+	}
+
+	// keep the scopes granted so far
+	_, granted, err := readToken(*tokenPath)
+	if err != nil {
+		fatal(withExitCode(exitAuth, err))
+	}
+	if !*reset {
+		config.Scopes = unionScopes(granted, config.Scopes)
+	}
+
+	var tok *oauth2.Token
+	if *noBrowser {
+		tok, err = tokenFromPrompt(ctx, config, os.Stdin)
+	} else {
+		tok, err = tokenFromWeb(ctx, config, *login)
+	}
+	if err != nil {
+		fatal(withExitCode(exitAuth, err))
+	}
+
+	if err := saveToken(*tokenPath, tok, config.Scopes); err != nil {
+		fatal(withExitCode(exitAuth, err))
+	}
+	fmt.Printf("Token saved to %s\n", *tokenPath)
+}
+
+// configDir returns the directory holding the credentials and token files,
+// following the XDG base directory spec: $XDG_CONFIG_HOME/chunkit, falling
+// back to ~/.config/chunkit.
+func configDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "chunkit")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(home, ".config", "chunkit")
+}
+
+// defaultScope is the scope requested when a command does not need more.
+const defaultScope = calendar.CalendarEventsReadonlyScope
+
+// authenticateClient returns a client authorized for scopes (defaultScope if
+// none), asking for consent again if the cached token lacks any of them.
+func authenticateClient(ctx context.Context, credentialsPath, tokenPath string, login loginOptions, scopes ...string) (*http.Client, error) {
+	config, err := readConfig(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) > 0 {
+		config.Scopes = scopes
+	}
+
+	tok, granted, err := readToken(tokenPath)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := missingScopes(granted, config.Scopes)
+	if len(missing) == 0 && (tok.Valid() || tok.RefreshToken != "") {
+		fresh, err := config.TokenSource(ctx, tok).Token()
+		switch {
+		case err == nil:
+			// keep the refreshed token so the next run need not refresh it
+			if fresh.AccessToken != tok.AccessToken {
+				if err := saveToken(tokenPath, fresh, granted); err != nil {
+					return nil, err
+				}
+			}
+			return config.Client(ctx, fresh), nil
+		case !revoked(err):
+			return nil, fmt.Errorf("error refreshing the token: %v", err)
+		}
+		fmt.Fprintln(os.Stderr, "Your saved login was revoked or has expired, log in again.")
+	}
+
+	// ask for the scopes granted so far too, so other commands keep working
+	if len(granted) > 0 && len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "This command needs access you have not granted yet: %s\n", strings.Join(missing, ", "))
+	}
+	config.Scopes = unionScopes(granted, missing)
+	tok, err = tokenFromWeb(ctx, config, login)
+	if err != nil {
+		return nil, err
+	}
+
+	// save the token for future use
+	if err := saveToken(tokenPath, tok, config.Scopes); err != nil {
+		return nil, err
+	}
+
+	return config.Client(ctx, tok), nil
+}
+
+func readConfig(credentialsPath string) (*oauth2.Config, error) {
+	bytes, err := os.ReadFile(credentialsPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("credentials file not found at %s: download an OAuth client ID from the Google Cloud console or pass -credentials", credentialsPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the credentials file: %v", err)
+	}
+
+	config, err := google.ConfigFromJSON(bytes, defaultScope)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the OAuth2 config: %v", err)
+	}
+	return config, nil
+}
+
+// savedToken is the format of the token file: the token plus the scopes it
+// was granted.
+type savedToken struct {
+	*oauth2.Token
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// readToken returns the cached token and its scopes, or an empty token if we
+// have not authenticated yet.
+func readToken(tokenPath string) (*oauth2.Token, []string, error) {
+	saved := savedToken{Token: &oauth2.Token{}}
+	bytes, err := os.ReadFile(tokenPath)
+	if os.IsNotExist(err) {
+		return saved.Token, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading the token file: %v", err)
+	}
+	if len(bytes) == 0 {
+		return saved.Token, nil, nil
+	}
+	if err := json.Unmarshal(bytes, &saved); err != nil {
+		return nil, nil, fmt.Errorf("error decoding the token file %s (delete it to re-authenticate): %v", tokenPath, err)
+	}
+	// token files from before scopes were recorded only have the default
+	if len(saved.Scopes) == 0 {
+		saved.Scopes = []string{defaultScope}
+	}
+	return saved.Token, saved.Scopes, nil
+}
+
+// saveToken replaces the token file with the token, never leaving it half
+// written or holding a token without an access token.
+func saveToken(tokenPath string, tok *oauth2.Token, scopes []string) error {
+	if tok == nil || tok.AccessToken == "" {
+		return errors.New("error saving the token: Google returned no access token")
+	}
+	if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
+		return fmt.Errorf("error creating the token directory: %v", err)
+	}
+	bytes, err := json.Marshal(savedToken{Token: tok, Scopes: scopes})
+	if err != nil {
+		return fmt.Errorf("error encoding the token: %v", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(tokenPath), filepath.Base(tokenPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing the token file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(bytes); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing the token file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing the token file: %v", err)
+	}
+	if err := os.Rename(f.Name(), tokenPath); err != nil {
+		return fmt.Errorf("error writing the token file: %v", err)
+	}
+	return nil
+}
+
+// revoked reports whether err is Google refusing the refresh token, which
+// happens when access was revoked or the token went unused for months.
+func revoked(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
+}
+
+// impliedScopes are the scopes each scope grants besides itself.
+var impliedScopes = map[string][]string{
+	calendar.CalendarScope:         {calendar.CalendarReadonlyScope, calendar.CalendarEventsScope, calendar.CalendarEventsReadonlyScope, freeBusyScope},
+	calendar.CalendarReadonlyScope: {calendar.CalendarEventsReadonlyScope, freeBusyScope},
+	calendar.CalendarEventsScope:   {calendar.CalendarEventsReadonlyScope},
+}
+
+// missingScopes returns the needed scopes that the granted ones don't grant,
+// either themselves or by implying them.
+func missingScopes(granted, needed []string) []string {
+	var missing []string
+	for _, scope := range needed {
+		covered := slices.Contains(granted, scope)
+		for _, g := range granted {
+			covered = covered || slices.Contains(impliedScopes[g], scope)
+		}
+		if !covered {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// unionScopes returns the scopes in a followed by those only in b.
+func unionScopes(a, b []string) []string {
+	union := append([]string{}, a...)
+	for _, scope := range b {
+		if !slices.Contains(union, scope) {
+			union = append(union, scope)
+		}
+	}
+	return union
+}
+
+// loginOptions are the flags of the browser login.
+type loginOptions struct {
+	redirectPort int
+	timeout      time.Duration
+}
+
+func (l *loginOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&l.redirectPort, "redirect-port", 0, "Port of the local server the browser login redirects to (default the client's redirect URL's, or a free one)")
+	fs.DurationVar(&l.timeout, "login-timeout", 5*time.Minute, "How long to wait for the browser login before giving up")
+}
+
+// listen opens the local redirect server's listener and returns the
+// redirect URL pointing at it. An explicit -redirect-port must be free; the
+// port of the client's redirect URL is replaced by a free one if it isn't,
+// which Google allows for loopback addresses.
+func (l loginOptions) listen(redirectURL string) (net.Listener, string, error) {
+	u, err := url.Parse(redirectURL)
+	if err != nil || u.Hostname() == "" {
+		u = &url.URL{Scheme: "http", Host: "127.0.0.1", Path: "/"}
+	}
+	port := u.Port()
+	if l.redirectPort != 0 {
+		port = strconv.Itoa(l.redirectPort)
+	}
+	if port == "" {
+		port = "0"
+	}
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil && l.redirectPort != 0 {
+		return nil, "", fmt.Errorf("error listening for the login redirect on port %d, which may be in use: %v", l.redirectPort, err)
+	}
+	if err != nil {
+		slog.Debug("redirect port in use, picking a free one", "port", port, "error", err)
+		if ln, err = net.Listen("tcp", net.JoinHostPort(u.Hostname(), "0")); err != nil {
+			return nil, "", fmt.Errorf("error listening for the login redirect: %v", err)
+		}
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+	return ln, u.String(), nil
+}
+
+// tokenFromWeb runs a local redirect server and exchanges the code the
+// browser is sent back with, giving up after the -login-timeout.
+func tokenFromWeb(ctx context.Context, config *oauth2.Config, login loginOptions) (*oauth2.Token, error) {
+	ln, redirectURL, err := login.listen(config.RedirectURL)
+	if err != nil {
+		return nil, err
+	}
+	config.RedirectURL = redirectURL
+
+	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
+	fmt.Printf("Authenticate at this URL:\n\n%s\n\n", authURL)
+	fmt.Printf("No browser on this machine? Run `chunkit auth -no-browser` instead.\n")
+
+	type result struct{ code, err string }
+	ch := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case ch <- result{code: r.URL.Query().Get("code"), err: r.URL.Query().Get("error")}:
+		default:
+		}
+		w.Write([]byte("You can now close this window."))
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(ln)
+	defer server.Close()
+
+	var res result
+	select {
+	case res = <-ch:
+	case <-time.After(login.timeout):
+		return nil, fmt.Errorf("error authenticating: no login within %s", login.timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if res.code == "" {
+		return nil, fmt.Errorf("error authenticating: no authorization code was returned (%s)", res.err)
+	}
+	tok, err := config.Exchange(ctx, res.code)
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging the authorization code: %v", err)
+	}
+	return tok, nil
+}
+
+// tokenFromPrompt is the headless flow: the user opens the URL on any
+// machine, and once the browser fails to load the redirect, pastes either
+// the code or the whole address bar URL back into the terminal.
+func tokenFromPrompt(ctx context.Context, config *oauth2.Config, in io.Reader) (*oauth2.Token, error) {
+	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
+	fmt.Printf("Open this URL in a browser on any machine:\n\n%s\n\n", authURL)
+	fmt.Printf("After approving, the browser is redirected to a page that fails to load.\n")
+	fmt.Printf("Paste that page's URL (or just its code parameter) here: ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("error reading the authorization code: %v", err)
+	}
+
+	tok, err := config.Exchange(ctx, parseAuthCode(line))
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging the authorization code: %v", err)
+	}
+	return tok, nil
+}
+
+// parseAuthCode accepts either a bare authorization code or the redirect URL
+// carrying it in its query string.
+func parseAuthCode(input string) string {
+	input = strings.TrimSpace(input)
+	if u, err := url.Parse(input); err == nil {
+		if code := u.Query().Get("code"); code != "" {
+			return code
+		}
+	}
+	return input
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func Test_parseAuthCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "bare code",
+			input:    "4/0AbCdEf\n",
+			expected: "4/0AbCdEf",
+		},
+		{
+			name:     "redirect URL",
+			input:    "http://localhost:8080/?state=state-token&code=4/0AbCdEf&scope=calendar\n",
+			expected: "4/0AbCdEf",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := parseAuthCode(test.input); code != test.expected {
+				t.Errorf("expected code to be '%s', got '%s'", test.expected, code)
+			}
+		})
+	}
+}
+
+func Test_missingScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		needed   []string
+		expected []string
+	}{
+		{
+			name:     "nothing granted",
+			needed:   []string{defaultScope},
+			expected: []string{defaultScope},
+		},
+		{
+			name:    "granted itself",
+			granted: []string{defaultScope},
+			needed:  []string{defaultScope},
+		},
+		{
+			name:    "implied by write access",
+			granted: []string{calendar.CalendarEventsScope},
+			needed:  []string{defaultScope},
+		},
+		{
+			name:     "write access not implied by read access",
+			granted:  []string{defaultScope},
+			needed:   []string{calendar.CalendarEventsScope, freeBusyScope},
+			expected: []string{calendar.CalendarEventsScope, freeBusyScope},
+		},
+		{
+			name:    "free/busy implied by the full calendar",
+			granted: []string{calendar.CalendarScope},
+			needed:  []string{freeBusyScope, calendar.CalendarEventsScope},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			missing := missingScopes(test.granted, test.needed)
+			if strings.Join(missing, " ") != strings.Join(test.expected, " ") {
+				t.Errorf("expected %v to be missing, got %v", test.expected, missing)
+			}
+		})
+	}
+}
+
+func Test_revoked(t *testing.T) {
+	if !revoked(fmt.Errorf("error refreshing: %w", &oauth2.RetrieveError{ErrorCode: "invalid_grant"})) {
+		t.Errorf("expected invalid_grant to be revoked")
+	}
+	if revoked(errors.New("connection refused")) {
+		t.Errorf("expected a network error not to be revoked")
+	}
+}
+
+func Test_saveToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	if err := saveToken(path, &oauth2.Token{AccessToken: "old", RefreshToken: "refresh"}, []string{defaultScope}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := saveToken(path, &oauth2.Token{}, []string{defaultScope}); err == nil {
+		t.Errorf("expected an error saving a token without an access token")
+	}
+
+	tok, scopes, err := readToken(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tok.AccessToken != "old" || tok.RefreshToken != "refresh" || len(scopes) != 1 {
+		t.Errorf("expected the first token to be kept, got %+v with scopes %v", tok, scopes)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the token file, got %d files", len(entries))
+	}
+}
+
+func Test_loginOptions_listen(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		name        string
+		login       loginOptions
+		redirectURL string
+		expectErr   bool
+	}{
+		{name: "free port without one in the URL", redirectURL: "http://localhost"},
+		{name: "free port when the URL's is in use", redirectURL: fmt.Sprintf("http://127.0.0.1:%d/", busyPort)},
+		{name: "explicit port in use", login: loginOptions{redirectPort: busyPort}, redirectURL: "http://127.0.0.1", expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ln, redirectURL, err := test.login.listen(test.redirectURL)
+			if test.expectErr {
+				if err == nil {
+					ln.Close()
+					t.Errorf("expected an error, got %s", redirectURL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			defer ln.Close()
+			port := ln.Addr().(*net.TCPAddr).Port
+			if u, _ := url.Parse(redirectURL); port == busyPort || u.Port() != fmt.Sprint(port) {
+				t.Errorf("expected a redirect URL to a free port, got %s on port %d", redirectURL, port)
+			}
+		})
+	}
+}
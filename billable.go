@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// nonBillableTag marks an event as non-billable when put in its summary.
+const nonBillableTag = "[nb]"
+
+// nonBillablePattern matches nonBillableTag in any case.
+var nonBillablePattern = regexp.MustCompile(`(?i)` + regexp.QuoteMeta(nonBillableTag))
+
+// classifyBillable marks the chunks tagged [nb] or #nonbillable, or matching
+// a non-billable rule, as non-billable, dropping [nb] from their notes.
+// Chunks tagged #billable are billable whatever the rules say.
+func classifyBillable(chunks []*Chunk, rules []BillableRule) {
+	for _, chunk := range chunks {
+		if nonBillablePattern.MatchString(chunk.notes) {
+			chunk.notes = strings.Join(strings.Fields(nonBillablePattern.ReplaceAllString(chunk.notes, "")), " ")
+			chunk.nonBillable = true
+			chunk.explain("non-billable by the %s tag", nonBillableTag)
+			continue
+		}
+		switch {
+		case chunk.hasTag(nonBillableHashtag):
+			chunk.nonBillable = true
+			chunk.explain("non-billable by the %s tag", nonBillableHashtag)
+			continue
+		case chunk.hasTag(billableHashtag):
+			chunk.explain("billable by the %s tag", billableHashtag)
+			continue
+		}
+		i := matchBillableRule(rules, chunk)
+		if i < 0 {
+			continue
+		}
+		chunk.matched(fmt.Sprintf("billable #%d", i+1))
+		if !rules[i].Billable {
+			chunk.nonBillable = true
+			chunk.explain("non-billable by billable rule %d", i+1)
+		}
+	}
+}
+
+// matchBillableRule returns the index of the first rule matching the chunk,
+// or -1.
+func matchBillableRule(rules []BillableRule, chunk *Chunk) int {
+	for i, rule := range rules {
+		if !strings.Contains(strings.ToLower(chunk.notes), strings.ToLower(rule.Keyword)) {
+			continue
+		}
+		if rule.Project != "" && rule.Project != chunk.project {
+			continue
+		}
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_classifyBillable(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	rules := []BillableRule{
+		{Keyword: "ACME", Billable: true},
+		{Project: "Internal", Billable: false},
+		{Keyword: "sync", Billable: false},
+	}
+	tests := []struct {
+		name        string
+		notes       string
+		project     string
+		nonBillable bool
+		wantNotes   string
+	}{
+		{name: "tag", notes: "ACME review [NB] part 2", nonBillable: true, wantNotes: "ACME review part 2"},
+		{name: "tag after a dotted capital I", notes: "İstanbul office sync [nb]", nonBillable: true, wantNotes: "İstanbul office sync"},
+		{name: "tag after letters lowercased to longer ones", notes: "ȺȺȺȺ[nb]", nonBillable: true, wantNotes: "ȺȺȺȺ"},
+		{name: "first rule wins", notes: "ACME sync", project: "Internal", wantNotes: "ACME sync"},
+		{name: "project rule", notes: "Hiring", project: "Internal", nonBillable: true, wantNotes: "Hiring"},
+		{name: "keyword rule", notes: "Team sync", nonBillable: true, wantNotes: "Team sync"},
+		{name: "no rule", notes: "Design", wantNotes: "Design"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk := &Chunk{start: date, end: date.Add(time.Hour), notes: tt.notes, project: tt.project}
+
+			classifyBillable([]*Chunk{chunk}, rules)
+
+			if chunk.nonBillable != tt.nonBillable {
+				t.Errorf("expected non-billable %t, got %t", tt.nonBillable, chunk.nonBillable)
+			}
+			if chunk.notes != tt.wantNotes {
+				t.Errorf("expected notes %q, got %q", tt.wantNotes, chunk.notes)
+			}
+		})
+	}
+}
+
+func Test_Report_csv_billable(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := testChunks(date)
+	chunks[1].nonBillable = true
+
+	report := newReport(date, chunks, reportColumns{})
+	output := report.csv(timeDecimal)
+
+	expected := `
+CSV report for the date: 2024-03-11 with a total of 2.50 hours (1.00 hours billable, 1.50 hours non-billable).
+
+start,end,billable
+09.00,10.00,true
+10.00,11.50,false
+`
+	if output != expected {
+		t.Errorf("expected report:\n%s\ngot:\n%s", expected, output)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// groupTotal is the time spent with an organizer or attendee domain.
+type groupTotal struct {
+	name  string
+	total time.Duration
+}
+
+// totalBy adds up the chunks' time under each of their keys, the most time
+// first. A chunk's time counts in full under every key it has, so the totals
+// may add up to more than the time reported.
+func totalBy(chunks []*Chunk, keys func(*Chunk) []string) []groupTotal {
+	totals := map[string]time.Duration{}
+	var names []string
+	for _, chunk := range chunks {
+		for _, key := range keys(chunk) {
+			if _, ok := totals[key]; !ok {
+				names = append(names, key)
+			}
+			totals[key] += chunk.end.Sub(chunk.start)
+		}
+	}
+
+	groups := make([]groupTotal, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, groupTotal{name: name, total: totals[name]})
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].total > groups[j].total })
+	return groups
+}
+
+// organizerOf keys a chunk by its event's organizer, "" for gaps.
+func organizerOf(chunk *Chunk) []string {
+	if chunk.Event == nil || chunk.Event.Organizer.Email == "" {
+		return []string{""}
+	}
+	return []string{chunk.Event.Organizer.Email}
+}
+
+// domainsOf keys a chunk by the distinct email domains of its event's
+// attendees, meeting rooms aside, with your own marked "(internal)". Gaps
+// and events without attendees are keyed "".
+func domainsOf(chunk *Chunk) []string {
+	if chunk.Event == nil {
+		return []string{""}
+	}
+	own := ""
+	for _, attendee := range chunk.Event.Attendees {
+		if attendee.Self {
+			own = emailDomain(attendee.Email)
+		}
+	}
+
+	var domains []string
+	seen := map[string]bool{}
+	for _, attendee := range chunk.Event.Attendees {
+		domain := emailDomain(attendee.Email)
+		if attendee.Resource || domain == "" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		if domain == own {
+			domain += " (internal)"
+		}
+		domains = append(domains, domain)
+	}
+	if len(domains) == 0 {
+		return []string{""}
+	}
+	return domains
+}
+
+func emailDomain(email string) string {
+	if i := strings.LastIndex(email, "@"); i >= 0 {
+		return strings.ToLower(email[i+1:])
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_totalBy(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	review := &Event{
+		Organizer: Person{Email: "ann@clienta.com"},
+		Attendees: []*Attendee{
+			{Email: "me@example.com", Self: true},
+			{Email: "ann@ClientA.com"},
+			{Email: "bob@clienta.com"},
+			{Email: "room-1@resource.example.com", Resource: true},
+		},
+	}
+	standup := &Event{
+		Organizer: Person{Email: "me@example.com"},
+		Attendees: []*Attendee{{Email: "me@example.com", Self: true}, {Email: "sam@example.com"}},
+	}
+	chunks := []*Chunk{
+		{Event: standup, start: date.Add(9 * time.Hour), end: date.Add(9*time.Hour + 30*time.Minute)},
+		{Event: review, start: date.Add(10 * time.Hour), end: date.Add(12 * time.Hour)},
+		{start: date.Add(12 * time.Hour), end: date.Add(13 * time.Hour)},
+	}
+
+	tests := []struct {
+		name     string
+		keys     func(*Chunk) []string
+		expected []groupTotal
+	}{
+		{name: "organizer", keys: organizerOf, expected: []groupTotal{
+			{name: "ann@clienta.com", total: 2 * time.Hour},
+			{name: "", total: time.Hour},
+			{name: "me@example.com", total: 30 * time.Minute},
+		}},
+		{name: "domain", keys: domainsOf, expected: []groupTotal{
+			{name: "example.com (internal)", total: 150 * time.Minute},
+			{name: "clienta.com", total: 2 * time.Hour},
+			{name: "", total: time.Hour},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if groups := totalBy(chunks, tt.keys); !reflect.DeepEqual(groups, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, groups)
+			}
+		})
+	}
+}
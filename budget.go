@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// runBudget implements the `budget` subcommand, an advisory check of the
+// week's meetings against the weekly hour budgets in the config.
+func runBudget(args []string) {
+	fs := flag.NewFlagSet("budget", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	decline := fs.Bool("decline", false, "Offer to decline each over-budget meeting with the budget's message (asks to edit your calendar)")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+	if opts.freeBusy {
+		fatal(withExitCode(exitUsage, fmt.Errorf("budgets match event summaries, which -free-busy does not read")))
+	}
+	if len(opts.calendarIDs()) > 1 {
+		fatal(withExitCode(exitUsage, fmt.Errorf("budget reads and declines on a single -calendar")))
+	}
+	if *decline {
+		opts.scopes = []string{calendar.CalendarEventsScope}
+	}
+
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	if len(config.Budgets) == 0 {
+		fatal(withExitCode(exitConfig, fmt.Errorf("no budgets in the config file")))
+	}
+	date, _, err := opts.reportRange()
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	monday := startOfWeek(date)
+	items, err := opts.fetch(ctx, calendarService, monday, monday.AddDate(0, 0, 7))
+	if err != nil {
+		fatal(err)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for _, budget := range config.Budgets {
+		used, over := checkBudget(items, budget, time.Now())
+		fmt.Printf("%s: %.2f of %.2f hours in the week of %s.\n", budget.Name, used, budget.Hours, monday.Format(dateLayout))
+		for _, e := range over {
+			start := e.Start.In(date.Location())
+			fmt.Printf("  over budget: %s %s %s\n", start.Format("Mon"), formatTime(start), e.Summary)
+
+			if !*decline {
+				continue
+			}
+			message, err := declineMessage(budget, e)
+			if err != nil {
+				fatal(err)
+			}
+			if !confirm(in, fmt.Sprintf("  Decline with %q?", message)) {
+				continue
+			}
+			if err := declineEvent(ctx, calendarService, opts.calendar, e, message); err != nil {
+				fatal(err)
+			}
+		}
+	}
+}
+
+// startOfWeek returns midnight of the Monday of date's week.
+func startOfWeek(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return time.Date(date.Year(), date.Month(), date.Day()-offset, 0, 0, 0, 0, date.Location())
+}
+
+// selfAttending reports whether you are attending the event: you accepted or
+// have not declined it, or you created it without inviting anyone.
+func selfAttending(e *Event) bool {
+	if len(e.Attendees) == 0 {
+		return e.Creator.Self
+	}
+	for _, attendee := range e.Attendees {
+		if attendee.Self && attendee.Response != "declined" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBudget adds up the hours of the attended, timed events in the budget's
+// category, returning the total and the meetings after now that take it over
+// budget.
+func checkBudget(items []*Event, budget Budget, now time.Time) (float64, []*Event) {
+	used := 0.0
+	var over []*Event
+	for _, e := range items {
+		if e.AllDay || e.Status == "cancelled" || !selfAttending(e) {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(e.Summary), strings.ToLower(budget.Keyword)) {
+			continue
+		}
+		if budget.Recurring && !e.Recurring {
+			continue
+		}
+
+		used += e.End.Sub(e.Start).Hours()
+		if used > budget.Hours && e.Start.After(now) {
+			over = append(over, e)
+		}
+	}
+	return used, over
+}
+
+// declineMessage renders the budget's message template for the event.
+func declineMessage(budget Budget, e *Event) (string, error) {
+	text := budget.Message
+	if text == "" {
+		text = "Declining as I am over my weekly budget of {{.Budget.Hours}} hours for {{.Budget.Name}}, sorry!"
+	}
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid message for budget %q: %v", budget.Name, err)
+	}
+	buf := bytes.Buffer{}
+	if err := tmpl.Execute(&buf, map[string]any{"Budget": budget, "Event": e}); err != nil {
+		return "", fmt.Errorf("invalid message for budget %q: %v", budget.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// declineEvent sets your response to the event on the calendar to declined,
+// with a comment.
+func declineEvent(ctx context.Context, calendarService *calendar.Service, calendarID string, e *Event, comment string) error {
+	// patching replaces the attendees, so start from the calendar's own
+	event, err := calendarService.Events.Get(calendarID, e.ID).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("error declining %q: %v", e.Summary, err)
+	}
+	for _, attendee := range event.Attendees {
+		if attendee.Self {
+			attendee.ResponseStatus = "declined"
+			attendee.Comment = comment
+		}
+	}
+	_, err = calendarService.Events.Patch(calendarID, e.ID, &calendar.Event{Attendees: event.Attendees}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("error declining %q: %v", e.Summary, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_checkBudget(t *testing.T) {
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	sync := func(day int, summary string) *Event {
+		e := newEvent(monday.AddDate(0, 0, day).Add(10*time.Hour), monday.AddDate(0, 0, day).Add(12*time.Hour), summary, "accepted", true)
+		e.Recurring = true
+		return e
+	}
+	declined := sync(3, "Sales sync")
+	declined.Attendees[0].Response = "declined"
+	oneOff := newEvent(monday.Add(14*time.Hour), monday.Add(16*time.Hour), "Kickoff sync", "accepted", true)
+	items := []*Event{sync(0, "Platform sync"), oneOff, sync(1, "Design sync"), declined, sync(3, "Platform sync"), sync(4, "Retro")}
+
+	budget := Budget{Name: "Recurring syncs", Keyword: "sync", Recurring: true, Hours: 3}
+	now := monday.AddDate(0, 0, 1).Add(13 * time.Hour) // Tuesday afternoon
+
+	used, over := checkBudget(items, budget, now)
+
+	if used != 6 {
+		t.Errorf("expected 6 hours of recurring syncs, got %.2f", used)
+	}
+	// Tuesday's sync went over budget, but it is too late to decline it
+	if len(over) != 1 || over[0] != items[4] {
+		t.Fatalf("expected only Thursday's sync to be over budget, got %d meetings", len(over))
+	}
+}
+
+func Test_startOfWeek(t *testing.T) {
+	tests := map[string]string{
+		"2024-03-11": "2024-03-11", // Monday
+		"2024-03-14": "2024-03-11",
+		"2024-03-17": "2024-03-11", // Sunday
+	}
+	for date, expected := range tests {
+		d, _ := time.Parse(dateLayout, date)
+		if monday := startOfWeek(d).Format(dateLayout); monday != expected {
+			t.Errorf("expected the week of %s to start on %s, got %s", date, expected, monday)
+		}
+	}
+}
+
+func Test_declineMessage(t *testing.T) {
+	budget := Budget{Name: "syncs", Hours: 6, Message: "Over my {{.Budget.Hours}}h of {{.Budget.Name}}, skipping {{.Event.Summary}}"}
+	message, err := declineMessage(budget, &Event{Summary: "Platform sync"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if message != "Over my 6h of syncs, skipping Platform sync" {
+		t.Errorf("expected rendered message, got '%s'", message)
+	}
+}
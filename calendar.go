@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// calendarPusher mirrors the chunks as events on a Google Calendar, keeping
+// the events it pushed earlier for the day in sync.
+type calendarPusher struct {
+	target  *string
+	service *calendar.Service
+}
+
+func newCalendarPusher(fs *flag.FlagSet) pusher {
+	return &calendarPusher{
+		target: fs.String("target", "", "ID of the calendar to write to, e.g. a dedicated \"Worked time\" calendar (not your primary one)"),
+	}
+}
+
+func (p *calendarPusher) useCalendar(calendarService *calendar.Service) {
+	p.service = calendarService
+}
+
+// chunkit tags the events it pushes with these private extended properties
+const (
+	pushedProperty     = "chunkit"
+	pushedDateProperty = "chunkitDate"
+)
+
+func (p *calendarPusher) push(ctx context.Context, config *Config, date time.Time, chunks []*Chunk, dryRun bool) error {
+	if *p.target == "" {
+		return errors.New("-target is required")
+	}
+	if *p.target == primaryCalendar {
+		return errors.New("-target must not be your primary calendar, which the chunks are computed from")
+	}
+
+	report := newReport(date, chunks, reportColumns{
+		notes:   !config.redacts("calendar", "notes"),
+		project: !config.redacts("calendar", "project"),
+		onCall:  !config.redacts("calendar", "on_call"),
+		// mirror the projects' colors so the calendar doubles as a legend
+		projectColors: config.ProjectColors,
+	})
+	desired := make([]*calendar.Event, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		desired = append(desired, chunkEvent(date, row))
+	}
+
+	var existing []*calendar.Event
+	err := p.service.Events.List(*p.target).
+		PrivateExtendedProperty(pushedDateProperty+"="+date.Format(dateLayout)).
+		SingleEvents(true).
+		Pages(ctx, func(page *calendar.Events) error {
+			existing = append(existing, page.Items...)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("error listing the pushed events: %v", err)
+	}
+
+	inserts, updates, deletes := diffEvents(existing, desired)
+	if dryRun {
+		for _, e := range inserts {
+			fmt.Printf("(dry run) Create %s-%s %q\n", e.Start.DateTime, e.End.DateTime, e.Summary)
+		}
+		for _, e := range updates {
+			fmt.Printf("(dry run) Update %s-%s %q\n", e.Start.DateTime, e.End.DateTime, e.Summary)
+		}
+		for _, e := range deletes {
+			fmt.Printf("(dry run) Delete %s-%s %q\n", e.Start.DateTime, e.End.DateTime, e.Summary)
+		}
+		return nil
+	}
+	sent := 0
+	for _, e := range inserts {
+		if _, err := p.service.Events.Insert(*p.target, e).Context(ctx).Do(); err != nil {
+			return partial(sent, fmt.Errorf("error creating %q: %v", e.Summary, err))
+		}
+		sent++
+	}
+	for _, e := range updates {
+		if _, err := p.service.Events.Update(*p.target, e.Id, e).Context(ctx).Do(); err != nil {
+			return partial(sent, fmt.Errorf("error updating %q: %v", e.Summary, err))
+		}
+		sent++
+	}
+	for _, e := range deletes {
+		if err := p.service.Events.Delete(*p.target, e.Id).Context(ctx).Do(); err != nil {
+			return partial(sent, fmt.Errorf("error deleting %q: %v", e.Summary, err))
+		}
+		sent++
+	}
+	fmt.Printf("Created %d, updated %d and deleted %d events on %s\n", len(inserts), len(updates), len(deletes), *p.target)
+	return nil
+}
+
+// chunkEvent is the calendar event mirroring a report row.
+func chunkEvent(date time.Time, row Row) *calendar.Event {
+	var description []string
+	if row.Project != "" {
+		description = append(description, "Project: "+row.Project)
+	}
+	if row.OnCall {
+		description = append(description, "On call")
+	}
+	color, _ := findColor(row.Color)
+	return &calendar.Event{
+		Summary:      row.title(),
+		ColorId:      color.id,
+		Description:  strings.Join(description, "\n"),
+		Start:        &calendar.EventDateTime{DateTime: row.Start.Format(time.RFC3339)},
+		End:          &calendar.EventDateTime{DateTime: row.End.Format(time.RFC3339)},
+		Transparency: "transparent",
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{pushedProperty: "1", pushedDateProperty: date.Format(dateLayout)},
+		},
+	}
+}
+
+// diffEvents pairs the previously pushed events with the desired ones by
+// start time, returning the desired events to insert, the existing ones to
+// update (with their content replaced) and the existing ones to delete.
+func diffEvents(existing, desired []*calendar.Event) (inserts, updates, deletes []*calendar.Event) {
+	byStart := map[int64]*calendar.Event{}
+	for _, e := range existing {
+		key, err := eventStart(e)
+		if err != nil || byStart[key] != nil {
+			deletes = append(deletes, e)
+			continue
+		}
+		byStart[key] = e
+	}
+
+	for _, e := range desired {
+		key, _ := eventStart(e)
+		old, ok := byStart[key]
+		if !ok {
+			inserts = append(inserts, e)
+			continue
+		}
+		delete(byStart, key)
+		if sameEvent(old, e) {
+			continue
+		}
+		e.Id = old.Id
+		updates = append(updates, e)
+	}
+
+	for _, e := range existing {
+		key, err := eventStart(e)
+		if err == nil && byStart[key] == e {
+			deletes = append(deletes, e)
+		}
+	}
+	return inserts, updates, deletes
+}
+
+func eventStart(e *calendar.Event) (int64, error) {
+	t, err := time.Parse(time.RFC3339, e.Start.DateTime)
+	return t.Unix(), err
+}
+
+// sameEvent reports whether a pushed event already matches the desired one.
+func sameEvent(old, e *calendar.Event) bool {
+	oldEnd, err := time.Parse(time.RFC3339, old.End.DateTime)
+	if err != nil {
+		return false
+	}
+	end, _ := time.Parse(time.RFC3339, e.End.DateTime)
+	return oldEnd.Equal(end) && old.Summary == e.Summary && old.Description == e.Description && old.ColorId == e.ColorId
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func Test_diffEvents(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	row := func(start, end time.Duration, notes string) Row {
+		return Row{Start: date.Add(start), End: date.Add(end), Notes: notes}
+	}
+	pushed := func(id string, r Row) *calendar.Event {
+		e := chunkEvent(date, r)
+		e.Id = id
+		// the API answers in the calendar's timezone
+		e.Start.DateTime = r.Start.In(time.FixedZone("CET", 3600)).Format(time.RFC3339)
+		return e
+	}
+
+	existing := []*calendar.Event{
+		pushed("unchanged", row(9*time.Hour, 10*time.Hour, "Standup")),
+		pushed("renamed", row(10*time.Hour, 11*time.Hour, "")),
+		pushed("gone", row(12*time.Hour, 13*time.Hour, "Cancelled meeting")),
+	}
+	desired := []*calendar.Event{
+		chunkEvent(date, row(9*time.Hour, 10*time.Hour, "Standup")),
+		chunkEvent(date, row(10*time.Hour, 11*time.Hour, "Design review")),
+		chunkEvent(date, row(11*time.Hour, 17*time.Hour, "")),
+	}
+
+	inserts, updates, deletes := diffEvents(existing, desired)
+
+	if len(inserts) != 1 || inserts[0] != desired[2] {
+		t.Errorf("expected the new chunk to be inserted, got %d inserts", len(inserts))
+	}
+	if len(updates) != 1 || updates[0].Id != "renamed" || updates[0].Summary != "Design review" {
+		t.Errorf("expected the renamed chunk to be updated, got %d updates", len(updates))
+	}
+	if len(deletes) != 1 || deletes[0].Id != "gone" {
+		t.Errorf("expected the stale chunk to be deleted, got %d deletes", len(deletes))
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// fetchCalendars lists the events of several calendars overlapping from-to
+// at once, as you see them, merging them into one list in start time order
+// with the owners' copies of mirrored events.
+func fetchCalendars(ctx context.Context, calendarService *calendar.Service, calendarIDs, owners []string, from, to time.Time) ([]*Event, error) {
+	lists := make([][]*Event, len(calendarIDs))
+	errs := make([]error, len(calendarIDs))
+	var wg sync.WaitGroup
+	for i, id := range calendarIDs {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			items, err := listEvents(ctx, calendarService, id, from, to)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			lists[i] = fromCalendar(items, id, from.Location())
+		}(i, id)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mergeCalendars(calendarIDs, lists, owners), nil
+}
+
+// mergeCalendars merges the events of the calendars in start time order. An
+// event on more than one of them, by iCalUID and start, is kept once, as the
+// highest ranked of the owners has it, or else the first of calendarIDs.
+func mergeCalendars(calendarIDs []string, lists [][]*Event, owners []string) []*Event {
+	order := make([]int, len(lists))
+	for i := range order {
+		order[i] = i
+	}
+	rank := func(i int) int {
+		for r, owner := range owners {
+			if owner == calendarIDs[i] {
+				return r
+			}
+		}
+		return len(owners)
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rank(order[a]) < rank(order[b]) })
+
+	kept := map[string]*Event{}
+	var merged []*Event
+	for _, i := range order {
+		for _, e := range lists[i] {
+			if e.ICalUID != "" {
+				key := e.ICalUID + " " + e.Start.UTC().Format(time.RFC3339)
+				if owner, ok := kept[key]; ok {
+					owner.Mirrors = append(owner.Mirrors, calendarIDs[i])
+					continue
+				}
+				kept[key] = e
+			}
+			merged = append(merged, e)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Start.Before(merged[j].Start) })
+	return merged
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+func Test_mergeCalendars(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	event := func(uid string, hour int, calendarID string) *Event {
+		return &Event{ICalUID: uid, Summary: uid, Start: date.Add(time.Duration(hour) * time.Hour), Calendar: calendarID}
+	}
+	lists := func() [][]*Event {
+		return [][]*Event{
+			{event("standup", 9, "primary"), event("standup", 10, "primary"), event("review", 14, "primary")},
+			{event("planning", 8, "team"), event("review", 14, "team"), event("", 15, "team"), event("", 15, "team")},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		owners   []string
+		expected []string
+	}{
+		{
+			name:     "first calendar listed",
+			expected: []string{"planning 8 team", "standup 9 primary", "standup 10 primary", "review 14 primary", " 15 team", " 15 team"},
+		},
+		{
+			name:     "owner",
+			owners:   []string{"team"},
+			expected: []string{"planning 8 team", "standup 9 primary", "standup 10 primary", "review 14 team", " 15 team", " 15 team"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged := mergeCalendars([]string{"primary", "team"}, lists(), test.owners)
+			if len(merged) != len(test.expected) {
+				t.Fatalf("expected %d events, got %d", len(test.expected), len(merged))
+			}
+			for i, e := range merged {
+				if got := fmt.Sprintf("%s %d %s", e.ICalUID, e.Start.Hour(), e.Calendar); got != test.expected[i] {
+					t.Errorf("expected event %d to be '%s', got '%s'", i, test.expected[i], got)
+				}
+			}
+			if review := merged[3]; len(review.Mirrors) != 1 || review.Mirrors[0] == review.Calendar {
+				t.Errorf("expected the review to be mirrored on the other calendar, got %v", review.Mirrors)
+			}
+		})
+	}
+}
+
+func Test_fetchCalendars(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/calendars/primary/"):
+			w.Write([]byte(`{"items": [{"id": "a", "iCalUID": "sync@example.com", "summary": "Sync", "start": {"dateTime": "2024-03-11T10:00:00Z"}, "end": {"dateTime": "2024-03-11T11:00:00Z"}}]}`))
+		case strings.Contains(r.URL.Path, "/calendars/team@example.com/"):
+			w.Write([]byte(`{"items": [
+				{"id": "b", "iCalUID": "sync@example.com", "summary": "Sync", "start": {"dateTime": "2024-03-11T10:00:00Z"}, "end": {"dateTime": "2024-03-11T11:00:00Z"}},
+				{"id": "c", "iCalUID": "offsite@example.com", "summary": "Offsite", "start": {"date": "2024-03-11"}, "end": {"date": "2024-03-12"}}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	calendarService, err := calendar.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	events, err := fetchCalendars(context.Background(), calendarService, []string{"primary", "team@example.com"}, nil, date, date.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected the sync once and the offsite, got %d events", len(events))
+	}
+	if events[0].Summary != "Offsite" || events[1].ID != "a" || events[1].Calendar != "primary" {
+		t.Errorf("expected the offsite then the primary calendar's sync, got %+v and %+v", events[0], events[1])
+	}
+
+	if _, err := fetchCalendars(context.Background(), calendarService, []string{"primary", "missing"}, nil, date, date.Add(24*time.Hour)); exitCode(err) != exitAPI {
+		t.Errorf("expected an API error for a missing calendar, got %v", err)
+	}
+}
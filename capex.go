@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// runCapex implements the `capex` subcommand, reporting a fiscal quarter's
+// capitalizable hours per project and month for engineering capitalization.
+func runCapex(args []string) {
+	fs := flag.NewFlagSet("capex", flag.ExitOnError)
+	opts := &rollupOptions{}
+	opts.register(fs)
+	fs.Parse(args)
+
+	r, err := opts.rollUp()
+	if err != nil {
+		fatal(err)
+	}
+	rows := r.capexRows()
+	if err := opts.write(rows, capexCSV(r, rows)); err != nil {
+		fatal(err)
+	}
+}
+
+// classifyCapex marks the chunks matching a capitalizable rule as capex.
+func classifyCapex(chunks []*Chunk, rules []CapexRule) {
+	for _, chunk := range chunks {
+		for i, rule := range rules {
+			if !strings.Contains(strings.ToLower(chunk.notes), strings.ToLower(rule.Keyword)) {
+				continue
+			}
+			if rule.Project != "" && rule.Project != chunk.project {
+				continue
+			}
+			chunk.matched(fmt.Sprintf("capex #%d", i+1))
+			if rule.Capex {
+				chunk.capex = true
+				chunk.explain("capitalizable by capex rule %d", i+1)
+			}
+			break
+		}
+	}
+}
+
+// capitalized are the chunks marked capex.
+func capitalized(chunks []*Chunk) []*Chunk {
+	var capex []*Chunk
+	for _, chunk := range chunks {
+		if chunk.capex {
+			capex = append(capex, chunk)
+		}
+	}
+	return capex
+}
+
+// capexRow splits a project's hours in a period into capitalizable and
+// operating expenses.
+type capexRow struct {
+	Period  string  `json:"period"`
+	Project string  `json:"project"`
+	Capex   float64 `json:"capex"`
+	Opex    float64 `json:"opex"`
+}
+
+// capexRows are a row per month and project with capitalizable hours, then
+// the quarter's.
+func (r *rollup) capexRows() []capexRow {
+	rows := []capexRow{}
+	add := func(period string, projects, capex map[string]float64) {
+		for _, project := range sortedProjects(capex) {
+			rows = append(rows, capexRow{
+				Period:  period,
+				Project: project,
+				Capex:   capex[project],
+				Opex:    projects[project] - capex[project],
+			})
+		}
+	}
+	for _, month := range r.Months {
+		add(month.Period, month.Projects, month.Capex)
+	}
+	add(r.Quarter, r.Projects, r.Capex)
+	return rows
+}
+
+func capexCSV(r *rollup, rows []capexRow) string {
+	capex := 0.0
+	for _, hours := range r.Capex {
+		capex += hours
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString("period,project,capex,opex\n")
+	for _, row := range rows {
+		buf.WriteString(fmt.Sprintf("%s,%s,%.2f,%.2f\n", row.Period, row.Project, row.Capex, row.Opex))
+	}
+
+	return fmt.Sprintf(`
+Capitalizable hours of %s over %d recorded days: %.2f of %.2f hours.
+
+%s`,
+		r.Quarter,
+		r.Days,
+		capex,
+		r.Total,
+		buf.String(),
+	)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_classifyCapex(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	rules := []CapexRule{
+		{Keyword: "bugfix", Capex: false},
+		{Project: "Platform", Capex: true},
+	}
+	tests := []struct {
+		name    string
+		notes   string
+		project string
+		capex   bool
+	}{
+		{name: "project rule", notes: "Design review", project: "Platform", capex: true},
+		{name: "first rule wins", notes: "Bugfix triage", project: "Platform"},
+		{name: "no rule", notes: "Design review", project: "ACME"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk := &Chunk{start: date, end: date.Add(time.Hour), notes: tt.notes, project: tt.project}
+
+			classifyCapex([]*Chunk{chunk}, rules)
+
+			if chunk.capex != tt.capex {
+				t.Errorf("expected capex %t, got %t", tt.capex, chunk.capex)
+			}
+		})
+	}
+}
+
+func Test_rollup_capexRows(t *testing.T) {
+	store := &Store{Days: map[string]*DayRecord{
+		"2024-07-01": {Hours: 8, Projects: map[string]float64{"Platform": 6, "ACME": 2}, Capex: map[string]float64{"Platform": 5}},
+		"2024-08-01": {Hours: 8, Projects: map[string]float64{"Platform": 8}, Capex: map[string]float64{"Platform": 8}},
+	}}
+
+	rows := rollUp(store, FiscalCalendar{}, 2024, 3).capexRows()
+
+	expected := []capexRow{
+		{Period: "FY2024-P07", Project: "Platform", Capex: 5, Opex: 1},
+		{Period: "FY2024-P08", Project: "Platform", Capex: 8, Opex: 0},
+		{Period: "FY2024-Q3", Project: "Platform", Capex: 13, Opex: 1},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected %v, got %v", expected, rows)
+	}
+}
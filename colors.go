@@ -0,0 +1,49 @@
+package main
+
+import "log/slog"
+
+// colorKey is the key of the rule for an event's color, with events in the
+// calendar's own color keyed as "default".
+func colorKey(e *Event) string {
+	if e.ColorID == "" {
+		return "default"
+	}
+	return e.ColorID
+}
+
+// filterColors drops the timed events whose color is excluded, or, if any
+// color is marked include, whose color is not. All-day events are left to
+// their policies.
+func filterColors(items []*Event, rules map[string]ColorRule) []*Event {
+	includeOnly := false
+	for _, rule := range rules {
+		includeOnly = includeOnly || rule.Include
+	}
+
+	filtered := make([]*Event, 0, len(items))
+	for _, e := range items {
+		if !e.AllDay {
+			rule := rules[colorKey(e)]
+			if rule.Exclude || (includeOnly && !rule.Include) {
+				slog.Debug("filtered out event", "summary", e.Summary, "rule", "color", "color", colorKey(e))
+				continue
+			}
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+// assignColorProjects sets the project of event chunks from their color.
+func assignColorProjects(chunks []*Chunk, rules map[string]ColorRule) {
+	for _, chunk := range chunks {
+		if chunk.Event == nil {
+			continue
+		}
+		if rule, ok := rules[colorKey(chunk.Event)]; ok && rule.Project != "" {
+			chunk.project = rule.Project
+			chunk.explain("project %s from color %s", rule.Project, colorKey(chunk.Event))
+			chunk.matched("colors " + colorKey(chunk.Event))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_filterColors(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	billableEvent := newEvent(date.Add(9*time.Hour), date.Add(10*time.Hour), "ACME sync", "accepted", true)
+	billableEvent.ColorID = "5"
+	internalEvent := newEvent(date.Add(11*time.Hour), date.Add(12*time.Hour), "Guild", "accepted", true)
+	internalEvent.ColorID = "8"
+	personalEvent := newEvent(date.Add(12*time.Hour), date.Add(13*time.Hour), "Gym", "accepted", true)
+	personalEvent.ColorID = "11"
+	defaultEvent := newEvent(date.Add(14*time.Hour), date.Add(15*time.Hour), "1:1", "accepted", true)
+	items := []*Event{billableEvent, internalEvent, personalEvent, defaultEvent}
+
+	tests := []struct {
+		name     string
+		rules    map[string]ColorRule
+		expected []*Event
+	}{
+		{
+			name:     "excludes colors",
+			rules:    map[string]ColorRule{"11": {Exclude: true}},
+			expected: []*Event{billableEvent, internalEvent, defaultEvent},
+		},
+		{
+			name:     "includes only colors",
+			rules:    map[string]ColorRule{"5": {Include: true}, "default": {Include: true}, "8": {Project: "Internal"}},
+			expected: []*Event{billableEvent, defaultEvent},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filtered := filterColors(items, test.rules)
+
+			if len(filtered) != len(test.expected) {
+				t.Fatalf("expected %d events, got %d", len(test.expected), len(filtered))
+			}
+			for i, e := range filtered {
+				if e != test.expected[i] {
+					t.Errorf("expected event '%s', got '%s'", test.expected[i].Summary, e.Summary)
+				}
+			}
+		})
+	}
+}
+
+func Test_assignColorProjects(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	billableEvent := newEvent(date.Add(10*time.Hour), date.Add(11*time.Hour), "ACME sync", "accepted", true)
+	billableEvent.ColorID = "5"
+	defaultEvent := newEvent(date.Add(14*time.Hour), date.Add(15*time.Hour), "1:1", "accepted", true)
+
+	chunks := Chunkify(date, []*Event{billableEvent, defaultEvent}, Options{})
+	assignColorProjects(chunks, map[string]ColorRule{"5": {Project: "ACME"}})
+
+	expectedProjects := []string{"", "ACME", "", "", ""}
+	for i, chunk := range chunks {
+		if chunk.project != expectedProjects[i] {
+			t.Errorf("expected chunk %d project to be '%s', got '%s'", i, expectedProjects[i], chunk.project)
+		}
+	}
+}
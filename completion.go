@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// runCompletion implements the `completion` subcommand, printing a shell
+// completion script. Preset names are read from the config when the script is
+// generated, so regenerate it after adding presets.
+func runCompletion(args []string) {
+	fs := flag.NewFlagSet("completion", flag.ExitOnError)
+	configPath := fs.String("config", filepath.Join(configDir(), "config.json"), "Path to the optional JSON config file")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: chunkit completion [-config path] bash|zsh|fish")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	var presets []string
+	if config, err := loadConfig(*configPath); err == nil {
+		for name := range config.Presets {
+			presets = append(presets, name)
+		}
+		sort.Strings(presets)
+	}
+	spec := newCompletionSpec(presets)
+
+	switch fs.Arg(0) {
+	case "bash":
+		fmt.Print(spec.bash())
+	case "zsh":
+		fmt.Print("autoload -U +X bashcompinit && bashcompinit\n" + spec.bash())
+	case "fish":
+		fmt.Print(spec.fish())
+	default:
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+}
+
+// completionSpec is what the completion scripts offer.
+type completionSpec struct {
+	commands []string
+	targets  []string
+	flags    []string            // without the leading dash
+	values   map[string][]string // flag values worth suggesting
+}
+
+// newCompletionSpec gathers the subcommands, push targets and the flags of
+// the report, which most subcommands share.
+func newCompletionSpec(presets []string) *completionSpec {
+	spec := &completionSpec{values: map[string][]string{
+		"date":        {"today", "yesterday", "monday", "tuesday", "wednesday", "thursday", "friday", "this-week", "last-week"},
+		"format":      {formatCSV, formatTTY, formatHTML, formatSVG, formatPNG, formatTemplate, formatXLSX, formatICS},
+		"tentative":   {tentativeInclude, tentativeExclude, tentativeFlag},
+		"time-format": {timeDecimal, timeClock, timeISO, timeDuration},
+		"preset":      presets,
+	}}
+	for name := range commands {
+		spec.commands = append(spec.commands, name)
+	}
+	sort.Strings(spec.commands)
+	for name := range pushTargets {
+		spec.targets = append(spec.targets, name)
+	}
+	sort.Strings(spec.targets)
+
+	fs := flag.NewFlagSet("chunkit", flag.ContinueOnError)
+	(&reportOptions{}).register(fs)
+	(&outputOptions{}).register(fs)
+	fs.VisitAll(func(f *flag.Flag) { spec.flags = append(spec.flags, f.Name) })
+	return spec
+}
+
+func (s *completionSpec) bash() string {
+	dashed := make([]string, 0, len(s.flags))
+	for _, f := range s.flags {
+		dashed = append(dashed, "-"+f)
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString("_chunkit() {\n")
+	buf.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	buf.WriteString("    case \"$prev\" in\n")
+	for _, name := range sortedKeys(s.values) {
+		buf.WriteString(fmt.Sprintf("    -%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", name, strings.Join(s.values[name], " ")))
+	}
+	buf.WriteString("    -config|-credentials|-token|-history|-template|-o) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n")
+	buf.WriteString("    esac\n")
+	buf.WriteString("    if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	buf.WriteString(fmt.Sprintf("        COMPREPLY=($(compgen -W %q -- \"$cur\")); return\n", strings.Join(append(append([]string{}, s.commands...), dashed...), " ")))
+	buf.WriteString("    fi\n")
+	buf.WriteString("    if [[ $COMP_CWORD -eq 2 && ${COMP_WORDS[1]} == push ]]; then\n")
+	buf.WriteString(fmt.Sprintf("        COMPREPLY=($(compgen -W %q -- \"$cur\")); return\n", strings.Join(s.targets, " ")))
+	buf.WriteString("    fi\n")
+	buf.WriteString(fmt.Sprintf("    COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(dashed, " ")))
+	buf.WriteString("}\n")
+	buf.WriteString("complete -F _chunkit chunkit\n")
+	return buf.String()
+}
+
+func (s *completionSpec) fish() string {
+	buf := strings.Builder{}
+	buf.WriteString("complete -c chunkit -f\n")
+	buf.WriteString(fmt.Sprintf("complete -c chunkit -n __fish_use_subcommand -a '%s'\n", strings.Join(s.commands, " ")))
+	buf.WriteString(fmt.Sprintf("complete -c chunkit -n '__fish_seen_subcommand_from push; and not __fish_seen_subcommand_from %s' -a '%s'\n", strings.Join(s.targets, " "), strings.Join(s.targets, " ")))
+	for _, f := range s.flags {
+		switch values := s.values[f]; {
+		case len(values) > 0:
+			buf.WriteString(fmt.Sprintf("complete -c chunkit -o %s -x -a '%s'\n", f, strings.Join(values, " ")))
+		case f == "config" || f == "credentials" || f == "token" || f == "history" || f == "template" || f == "o":
+			buf.WriteString(fmt.Sprintf("complete -c chunkit -o %s -r -F\n", f))
+		default:
+			buf.WriteString(fmt.Sprintf("complete -c chunkit -o %s\n", f))
+		}
+	}
+	return buf.String()
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_completionSpec(t *testing.T) {
+	spec := newCompletionSpec([]string{"client-acme"})
+
+	bash := spec.bash()
+	for _, expected := range []string{"summary", "-time-format", "-preset) COMPREPLY=($(compgen -W \"client-acme\"", "openproject"} {
+		if !strings.Contains(bash, expected) {
+			t.Errorf("expected the bash completion to offer %s", expected)
+		}
+	}
+	if fish := spec.fish(); !strings.Contains(fish, "complete -c chunkit -o preset -x -a 'client-acme'") {
+		t.Errorf("expected the fish completion to offer the presets, got:\n%s", fish)
+	}
+}
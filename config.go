@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config is the optional JSON configuration file for rules that are too
+// rich for flags.
+type Config struct {
+	AllDay []AllDayPolicy `json:"allDay"`
+	Kimai  []KimaiRule    `json:"kimai"`
+	Odoo   []OdooRule     `json:"odoo"`
+	// Colors are keyed by Google Calendar colorId, or "default" for events
+	// in the calendar's own color.
+	Colors map[string]ColorRule `json:"colors"`
+	// Presets are named sets of flag values selected with -preset, e.g.
+	// {"client-acme": {"tentative": "exclude", "clamp-workday": true}}.
+	Presets map[string]map[string]any `json:"presets"`
+	// Redact lists the columns (notes, project, on_call, links, location, tags) to withhold
+	// from each output target: "csv" for the report or a push target's name.
+	Redact map[string][]string `json:"redact"`
+	// Priorities are what `plan` books focus time for, most important first.
+	Priorities []string `json:"priorities"`
+	Budgets    []Budget `json:"budgets"`
+	// Precedence ranks overlapping events to decide which one a chunk goes to.
+	Precedence []PrecedenceRule `json:"precedence"`
+	// Umbrella events name the gaps they span rather than leaving them
+	// unallocated.
+	Umbrella []UmbrellaRule `json:"umbrella"`
+	// CalendarOwners rank the calendars whose copy of an event on several
+	// of them owns its chunk, first first. Unlisted calendars rank after
+	// them in -calendar order.
+	CalendarOwners []string `json:"calendarOwners"`
+	// Fiscal is how stats group days into months and quarters.
+	Fiscal FiscalCalendar `json:"fiscal"`
+	// Workdays are the workday windows per weekday, 9 to 5 by default.
+	Workdays Schedule `json:"workdays"`
+	// Target is the hours to work, e.g. {"week": 20}; summaries and the
+	// history then track the over- and undertime.
+	Target Target `json:"target"`
+	// Absences mark days off, such as PTO or sick days, by their all-day
+	// events, before the allDay policies apply.
+	Absences []AbsenceRule `json:"absences"`
+	// Billable classifies chunks as billable or not; chunks no rule matches
+	// are billable.
+	Billable []BillableRule `json:"billable"`
+	// Capex marks the chunks whose hours are capitalizable; the rest are
+	// operating expenses.
+	Capex []CapexRule `json:"capex"`
+	// Rates are the hourly rates per project, "default" for the projects not
+	// listed. Summaries total what was earned when any are set.
+	Rates map[string]float64 `json:"rates"`
+	// Currency labels the earnings, e.g. "EUR".
+	Currency string `json:"currency"`
+	// Gap names and assigns the time between events that nothing else does,
+	// e.g. {"notes": "Development", "project": "ACME"}.
+	Gap GapDefault `json:"gap"`
+	// ProjectColors assign Google Calendar color names, e.g. "tomato", to
+	// projects in visual outputs; other projects get one picked from their name.
+	ProjectColors map[string]string `json:"projectColors"`
+	// MeetingCost is the average hourly cost of an attendee; when set,
+	// reports estimate what each meeting cost.
+	MeetingCost float64 `json:"meetingCost"`
+	// Clients are who `invoice -client` bills, keyed by name.
+	Clients map[string]Client `json:"clients"`
+	// Team are the people `team` reports on.
+	Team []TeamMember `json:"team"`
+	// AttendeeDomains set the project of meetings without one from the most
+	// common email domain of their attendees, e.g. {"acme.com": "ACME"}.
+	AttendeeDomains map[string]string `json:"attendeeDomains"`
+	// InternalDomains are your organization's email domains, which never
+	// count towards a meeting's domain.
+	InternalDomains []string `json:"internalDomains"`
+}
+
+// TeamMember is a person whose calendar, identified by Email, is shared with
+// you or readable through a service account.
+type TeamMember struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// name is what reports call the member: their Name, or Email without one.
+func (m TeamMember) name() string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return m.Email
+}
+
+// Client is billed for the time on its Projects at their rates, plus Tax
+// percent. Template is the path to an html/template for its invoices, which
+// are otherwise rendered with a plain built-in one.
+type Client struct {
+	Projects []string `json:"projects"`
+	Tax      float64  `json:"tax"`
+	Template string   `json:"template"`
+}
+
+// CapexRule marks chunks whose notes contain Keyword (case-insensitively)
+// and whose project is Project as capitalizable or not; either may be empty
+// to match any chunk. The first matching rule wins.
+type CapexRule struct {
+	Keyword string `json:"keyword"`
+	Project string `json:"project"`
+	Capex   bool   `json:"capex"`
+}
+
+// BillableRule marks chunks whose notes contain Keyword (case-insensitively)
+// and whose project is Project as billable or not; either may be empty to
+// match any chunk. The first matching rule wins.
+type BillableRule struct {
+	Keyword  string `json:"keyword"`
+	Project  string `json:"project"`
+	Billable bool   `json:"billable"`
+}
+
+// GapDefault is the notes and project of gap chunks without their own.
+type GapDefault struct {
+	Notes   string `json:"notes"`
+	Project string `json:"project"`
+}
+
+// PrecedenceRule ranks events whose summary contains Keyword
+// (case-insensitively) and where your Role is "organizer", "required" or
+// "optional"; either may be empty to match any event. An event's rank is the
+// sum of its matching rules' Rank, zero if none match. When two events
+// overlap, the one with the higher rank keeps the time, and the later one on a
+// tie.
+type PrecedenceRule struct {
+	Keyword string `json:"keyword"`
+	Role    string `json:"role"`
+	Rank    int    `json:"rank"`
+}
+
+// UmbrellaRule makes events whose summary contains Keyword
+// (case-insensitively) umbrellas: gaps they span are named after them, after
+// the innermost one when they nest. An empty Keyword matches the events that
+// contain another event.
+type UmbrellaRule struct {
+	Keyword string `json:"keyword"`
+}
+
+// Budget caps the weekly hours of meetings whose summary contains Keyword
+// (case-insensitively), only counting recurring ones if Recurring is set.
+// Message is a text/template for declines, given .Budget and .Event.
+type Budget struct {
+	Name      string  `json:"name"`
+	Keyword   string  `json:"keyword"`
+	Recurring bool    `json:"recurring"`
+	Hours     float64 `json:"hours"`
+	Message   string  `json:"message"`
+}
+
+// earned is what the time on project earns at its rate.
+func (c *Config) earned(project string, d time.Duration) float64 {
+	rate, ok := c.Rates[project]
+	if !ok {
+		rate = c.Rates["default"]
+	}
+	return rate * d.Hours()
+}
+
+// money formats an amount in the configured currency.
+func (c *Config) money(amount float64) string {
+	if c.Currency == "" {
+		return fmt.Sprintf("%.2f", amount)
+	}
+	return fmt.Sprintf("%s %.2f", c.Currency, amount)
+}
+
+// redacts reports whether column is withheld from target.
+func (c *Config) redacts(target, column string) bool {
+	for _, redacted := range c.Redact[target] {
+		if redacted == column {
+			return true
+		}
+	}
+	return false
+}
+
+// ColorRule decides what happens to timed events of a color: Exclude drops
+// them, and Project assigns their chunks to a project. If any color is marked
+// Include, events of every other color are dropped.
+type ColorRule struct {
+	Include bool   `json:"include"`
+	Exclude bool   `json:"exclude"`
+	Project string `json:"project"`
+}
+
+// AllDayPolicy decides what an all-day event whose summary contains Keyword
+// (case-insensitively) does to the day:
+//
+//	"zero"     the day has no chunks, e.g. PTO or a public holiday
+//	"fill"     the whole workday is one chunk named after the event, e.g. a conference
+//	"annotate" gap chunks are named after the event, e.g. a team offsite
+type AllDayPolicy struct {
+	Keyword string `json:"keyword"`
+	Policy  string `json:"policy"`
+}
+
+// KimaiRule books chunks whose notes contain Keyword (case-insensitively, an
+// empty keyword matching every chunk) to a Kimai customer, project and
+// activity, all given by name. The first matching rule wins.
+type KimaiRule struct {
+	Keyword  string `json:"keyword"`
+	Customer string `json:"customer"`
+	Project  string `json:"project"`
+	Activity string `json:"activity"`
+}
+
+// OdooRule books chunks whose notes contain Keyword (case-insensitively, an
+// empty keyword matching every chunk) to an Odoo analytic account and,
+// optionally, a project, both given by name. The first matching rule wins.
+type OdooRule struct {
+	Keyword string `json:"keyword"`
+	Account string `json:"account"`
+	Project string `json:"project"`
+}
+
+const (
+	roleOrganizer = "organizer"
+	roleRequired  = "required"
+	roleOptional  = "optional"
+)
+
+const (
+	allDayZero     = "zero"
+	allDayFill     = "fill"
+	allDayAnnotate = "annotate"
+)
+
+// loadConfig reads the config file at path, returning an empty config if the
+// file does not exist.
+func loadConfig(path string) (*Config, error) {
+	config := &Config{}
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the config file: %v", err)
+	}
+	if err := json.Unmarshal(bytes, config); err != nil {
+		return nil, fmt.Errorf("error decoding the config file %s: %v", path, err)
+	}
+
+	for color, rule := range config.Colors {
+		if rule.Include && rule.Exclude {
+			return nil, fmt.Errorf("invalid rule for color %q: cannot both include and exclude", color)
+		}
+	}
+
+	for target, columns := range config.Redact {
+		for _, column := range columns {
+			switch column {
+			case "notes", "project", "on_call", "links", "location", "tags":
+			default:
+				return nil, fmt.Errorf("invalid redaction %q for %q: must be notes, project, on_call, links, location or tags", column, target)
+			}
+		}
+	}
+
+	if err := config.Fiscal.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Workdays.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Target.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := validateProjectColors(config.ProjectColors); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range config.Precedence {
+		switch rule.Role {
+		case "", roleOrganizer, roleRequired, roleOptional:
+		default:
+			return nil, fmt.Errorf("invalid precedence role %q: must be organizer, required or optional", rule.Role)
+		}
+	}
+
+	for _, r := range config.Absences {
+		if r.Keyword == "" {
+			return nil, fmt.Errorf("invalid absence: keyword is required")
+		}
+	}
+
+	for domain := range config.AttendeeDomains {
+		if domain == "" || strings.Contains(domain, "@") {
+			return nil, fmt.Errorf("invalid attendee domain %q: must be a domain such as acme.com", domain)
+		}
+	}
+
+	for _, p := range config.AllDay {
+		switch p.Policy {
+		case allDayZero, allDayFill, allDayAnnotate:
+		default:
+			return nil, fmt.Errorf("invalid all-day policy %q for keyword %q: must be zero, fill or annotate", p.Policy, p.Keyword)
+		}
+	}
+	return config, nil
+}
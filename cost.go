@@ -0,0 +1,13 @@
+package main
+
+// attendeeCount is how many people attend the event, not counting those who
+// declined or meeting rooms, and at least you.
+func attendeeCount(e *Event) int {
+	n := 0
+	for _, attendee := range e.Attendees {
+		if !attendee.Resource && attendee.Response != "declined" {
+			n++
+		}
+	}
+	return max(n, 1)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_attendeeCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		attendees []*Attendee
+		expected  int
+	}{
+		{name: "no attendees", expected: 1},
+		{name: "declined and rooms", attendees: []*Attendee{
+			{Self: true, Response: "accepted"},
+			{Response: "needsAction"},
+			{Response: "declined"},
+			{Resource: true, Response: "accepted"},
+		}, expected: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n := attendeeCount(&Event{Attendees: tt.attendees}); n != tt.expected {
+				t.Errorf("expected %d attendees, got %d", tt.expected, n)
+			}
+		})
+	}
+}
+
+func Test_Report_csv_cost(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := testChunks(date)
+	chunks[0].Event = &Event{Attendees: []*Attendee{{Self: true}, {}, {}}}
+
+	output := newReport(date, chunks, reportColumns{meetingCost: 80}).csv(timeDecimal)
+
+	for _, line := range []string{
+		"meetings costing an estimated 240.00.",
+		"start,end,cost\n09.00,10.00,240.00\n10.00,11.50,0.00\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected the report to contain %q, got:\n%s", line, output)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DayLock records that a day was pushed to a timesheet, with a fingerprint
+// of its calendar then, so later runs notice when it has changed since.
+type DayLock struct {
+	At          time.Time `json:"at"`
+	Target      string    `json:"target"`
+	Fingerprint string    `json:"fingerprint"`
+}
+
+// fingerprint hashes the IDs, times and summaries of the events fetched for
+// a day, cancelled ones aside: what the calendar says, before chunking,
+// config and local notes make anything of it.
+func fingerprint(items []*Event) string {
+	h := sha256.New()
+	for _, e := range items {
+		if e.Status == "cancelled" {
+			continue
+		}
+		fmt.Fprintf(h, "%q %d %d %q\n", e.ID, e.Start.Unix(), e.End.Unix(), e.Summary)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fingerprints are the fingerprints of the days buildDay fetched, by date in
+// dateLayout.
+type fingerprints struct {
+	mu   sync.Mutex
+	days map[string]string
+}
+
+// fingerprints returns the options' day fingerprints, creating them on first
+// use. buildDays does so before its workers share them.
+func (o *reportOptions) fingerprints() *fingerprints {
+	if o.printed == nil {
+		o.printed = &fingerprints{days: map[string]string{}}
+	}
+	return o.printed
+}
+
+func (f *fingerprints) set(date time.Time, print string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.days[date.Format(dateLayout)] = print
+}
+
+// dayFingerprint returns the fingerprint of the events of a day built with
+// the options.
+func (o *reportOptions) dayFingerprint(date time.Time) string {
+	f := o.fingerprints()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.days[date.Format(dateLayout)]
+}
+
+// lockDrift describes how the locked day date's calendar has changed since
+// it was locked, given its fingerprint now, "" if it isn't locked or hasn't
+// changed.
+func (s *Store) lockDrift(date time.Time, print string) string {
+	lock, ok := s.Locks[date.Format(dateLayout)]
+	if !ok || lock.Fingerprint == print {
+		return ""
+	}
+	return fmt.Sprintf("%s was locked after pushing it to %s on %s, but its calendar has changed since", date.Format(dateLayout), lock.Target, lock.At.Format("2006-01-02 15:04"))
+}
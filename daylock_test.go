@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_fingerprint(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	standup := &Event{ID: "a", Summary: "Standup", Start: date.Add(9 * time.Hour), End: date.Add(10 * time.Hour)}
+	items := []*Event{standup}
+	print := fingerprint(items)
+
+	described := *standup
+	described.Description = "notes for the sprint review"
+	cancelled := &Event{ID: "b", Summary: "Retro", Status: "cancelled", Start: date.Add(14 * time.Hour), End: date.Add(15 * time.Hour)}
+	moved := *standup
+	moved.Start, moved.End = moved.Start.Add(30*time.Minute), moved.End.Add(30*time.Minute)
+	renamed := *standup
+	renamed.Summary = "Planning"
+
+	tests := []struct {
+		name    string
+		items   []*Event
+		changed bool
+	}{
+		{name: "unchanged", items: []*Event{standup}},
+		{name: "description", items: []*Event{&described}},
+		{name: "cancelled", items: []*Event{standup, cancelled}},
+		{name: "moved", items: []*Event{&moved}, changed: true},
+		{name: "renamed", items: []*Event{&renamed}, changed: true},
+		{name: "added", items: []*Event{standup, {ID: "c", Start: date.Add(11 * time.Hour), End: date.Add(12 * time.Hour)}}, changed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := fingerprint(tt.items) != print; changed != tt.changed {
+				t.Errorf("expected changed %v, got %v", tt.changed, changed)
+			}
+		})
+	}
+}
+
+func Test_Store_lockDrift(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	items := []*Event{{ID: "a", Summary: "Standup", Start: date.Add(9 * time.Hour), End: date.Add(10 * time.Hour)}}
+	store := &Store{Locks: map[string]DayLock{
+		"2024-05-01": {At: date.Add(18 * time.Hour), Target: "kimai", Fingerprint: fingerprint(items)},
+	}}
+	moved := []*Event{{ID: "a", Summary: "Standup", Start: date.Add(9*time.Hour + 30*time.Minute), End: date.Add(10*time.Hour + 30*time.Minute)}}
+
+	tests := []struct {
+		name    string
+		date    time.Time
+		print   string
+		drifted bool
+	}{
+		{name: "unchanged", date: date, print: fingerprint(items)},
+		{name: "moved", date: date, print: fingerprint(moved), drifted: true},
+		{name: "not locked", date: date.AddDate(0, 0, 1), print: fingerprint(moved)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drift := store.lockDrift(tt.date, tt.print)
+			if (drift != "") != tt.drifted {
+				t.Errorf("expected drift %v, got %q", tt.drifted, drift)
+			}
+			if tt.drifted && !strings.Contains(drift, "kimai") {
+				t.Errorf("expected the drift to name the target, got %q", drift)
+			}
+		})
+	}
+}
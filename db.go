@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// runDB implements the `db` subcommand, maintenance of the local history.
+func runDB(args []string) {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: chunkit db check [-history path]")
+		os.Exit(exitUsage)
+	}
+
+	fs := flag.NewFlagSet("db check", flag.ExitOnError)
+	historyPath := fs.String("history", filepath.Join(dataDir(), "history.json"), "Path to the local report history")
+	fs.Parse(args[1:])
+
+	if _, err := os.Stat(*historyPath); os.IsNotExist(err) {
+		fmt.Printf("%s does not exist yet.\n", *historyPath)
+		return
+	}
+
+	// hold the lock so we don't check a file mid-update
+	unlock, err := lockFile(*historyPath + ".lock")
+	if err != nil {
+		fatal(err)
+	}
+	store, err := openStore(*historyPath)
+	unlock()
+	if err != nil {
+		fatal(err)
+	}
+
+	problems := store.Check()
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		log.Fatalf("%s has %d problems", *historyPath, len(problems))
+	}
+	fmt.Printf("%s is ok with %d recorded days.\n", *historyPath, len(store.Days))
+}
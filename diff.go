@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The kinds of chunkDiff.
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffShifted = "shifted"
+	diffChanged = "changed"
+)
+
+// chunkDiff is how an event's chunk differs from the one saved: added,
+// removed, shifted to other times, or changed in notes or project.
+type chunkDiff struct {
+	kind string
+	// now and was are the chunk as rows of the -append CSV, nil if it isn't
+	// there now or wasn't then.
+	now, was []string
+}
+
+// runDiff implements the `diff` subcommand, comparing a day's chunks as they
+// are now with those saved to an -append dataset, e.g. after a meeting was
+// moved once the hours were submitted.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	against := fs.String("against", "", "The CSV dataset the day was saved to with -append")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+	if *against == "" {
+		fatal(withExitCode(exitUsage, fmt.Errorf("-against is required")))
+	}
+	if err := opts.validate(); err != nil {
+		fatal(err)
+	}
+	date, err := opts.reportDate()
+	if err != nil {
+		fatal(err)
+	}
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	saved, err := readDataset(*against)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	chunks, err := opts.buildDay(ctx, calendarService, date)
+	if err != nil {
+		fatal(err)
+	}
+	report := newReport(date, chunks, opts.columns(config))
+	fmt.Print(formatDiff(date, diffDay(date, saved, report, opts.timeFormat)))
+}
+
+// diffDay compares the events of the report with those of its date saved in
+// the dataset, matched by event ID and, for an event in several chunks, by
+// their order, in the order of their times. Gaps follow from the events, so
+// they are left out.
+func diffDay(date time.Time, saved [][]string, report *Report, timeFormat string) []chunkDiff {
+	was := map[string][]string{}
+	seen := map[string]int{}
+	for _, record := range saved {
+		if record[0] == date.Format(dateLayout) && record[6] != "" {
+			was[occurrenceKey(seen, record[6])] = record
+		}
+	}
+	diffs := []chunkDiff{}
+	seen = map[string]int{}
+	for _, row := range report.Rows {
+		if row.EventID == "" {
+			continue
+		}
+		key := occurrenceKey(seen, row.EventID)
+		now := datasetRecord(date, row, timeFormat)
+		before, ok := was[key]
+		delete(was, key)
+		switch {
+		case !ok:
+			diffs = append(diffs, chunkDiff{kind: diffAdded, now: now})
+		case !sameTime(date, row.Start, before[1]) || !sameTime(date, row.End, before[2]):
+			diffs = append(diffs, chunkDiff{kind: diffShifted, now: now, was: before})
+		case now[4] != before[4] || now[5] != before[5]:
+			diffs = append(diffs, chunkDiff{kind: diffChanged, now: now, was: before})
+		}
+	}
+	for _, before := range was {
+		diffs = append(diffs, chunkDiff{kind: diffRemoved, was: before})
+	}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].start() < diffs[j].start()
+	})
+	return diffs
+}
+
+// sameTime reports whether t is the time saved as value, whatever
+// -time-format it was saved in.
+func sameTime(date, t time.Time, value string) bool {
+	saved, err := parseDatasetTime(date, value)
+	return err == nil && saved.Equal(t.Truncate(time.Minute))
+}
+
+// start is when the chunk starts now, or started if it was removed.
+func (d chunkDiff) start() string {
+	if d.now != nil {
+		return d.now[1]
+	}
+	return d.was[1]
+}
+
+// formatDiff writes the changes to the day as CSV, with the saved times,
+// notes and project of those shifted, changed or removed.
+func formatDiff(date time.Time, diffs []chunkDiff) string {
+	if len(diffs) == 0 {
+		return fmt.Sprintf("\nNo changes to %s since it was saved.\n", date.Format(dateLayout))
+	}
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("\n%d changes to %s since it was saved.\n\n", len(diffs), date.Format(dateLayout)))
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"change", "start", "end", "notes", "project", "was_start", "was_end", "was_notes", "was_project"})
+	for _, d := range diffs {
+		now, was := d.now, d.was
+		if now == nil {
+			now = make([]string, len(appendHeader))
+		}
+		if was == nil {
+			was = make([]string, len(appendHeader))
+		}
+		w.Write([]string{d.kind, now[1], now[2], now[4], now[5], was[1], was[2], was[4], was[5]})
+	}
+	// writing to a strings.Builder doesn't fail
+	w.Flush()
+	return buf.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_diffDay(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	saved := [][]string{
+		{"2024-05-01", "09.00", "10.00", "1.00", "Standup", "ACME", "a"},
+		{"2024-05-01", "10.00", "11.00", "1.00", "Review", "ACME", "b"},
+		{"2024-05-01", "11.00", "12.00", "1.00", "Planning", "ACME", "c"},
+		{"2024-05-01", "12.00", "17.00", "5.00", "", "", ""},
+		{"2024-05-02", "09.00", "10.00", "1.00", "Standup", "ACME", "d"},
+	}
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Standup", project: "ACME", Event: &Event{ID: "a"}},
+		{start: date.Add(10*time.Hour + 30*time.Minute), end: date.Add(11*time.Hour + 30*time.Minute), notes: "Review", project: "ACME", Event: &Event{ID: "b"}},
+		{start: date.Add(13 * time.Hour), end: date.Add(14 * time.Hour), notes: "Demo", project: "Globex", Event: &Event{ID: "e"}},
+		{start: date.Add(14 * time.Hour), end: date.Add(17 * time.Hour)},
+	}
+	report := newReport(date, chunks, reportColumns{notes: true, project: true})
+
+	diffs := diffDay(date, saved, report, timeDecimal)
+	expected := []struct{ kind, start string }{
+		{diffShifted, "10.50"},
+		{diffRemoved, "11.00"},
+		{diffAdded, "13.00"},
+	}
+	if len(diffs) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %v", len(expected), len(diffs), diffs)
+	}
+	for i, e := range expected {
+		t.Run(e.kind, func(t *testing.T) {
+			if diffs[i].kind != e.kind || diffs[i].start() != e.start {
+				t.Errorf("expected %s at %s, got %s at %s", e.kind, e.start, diffs[i].kind, diffs[i].start())
+			}
+		})
+	}
+
+	// saved in another -time-format
+	unchanged := newReport(date, chunks[:1], reportColumns{notes: true, project: true})
+	clock := [][]string{{"2024-05-01", "09:00", "10:00", "1.00", "Standup", "ACME", "a"}}
+	if diffs := diffDay(date, clock, unchanged, timeDecimal); len(diffs) != 0 {
+		t.Errorf("expected no changes, got %v", diffs)
+	}
+
+	// an event split around another is matched chunk by chunk
+	split := [][]string{
+		{"2024-05-01", "09.00", "10.00", "1.00", "Workshop", "", "w"},
+		{"2024-05-01", "10.00", "11.00", "1.00", "Standup", "", "a"},
+		{"2024-05-01", "11.00", "12.00", "1.00", "Workshop", "", "w"},
+	}
+	workshop := &Event{ID: "w"}
+	report = newReport(date, []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Workshop", Event: workshop},
+		{start: date.Add(10 * time.Hour), end: date.Add(11 * time.Hour), notes: "Standup", Event: &Event{ID: "a"}},
+		{start: date.Add(11 * time.Hour), end: date.Add(12*time.Hour + 30*time.Minute), notes: "Workshop", Event: workshop},
+	}, reportColumns{notes: true})
+	diffs = diffDay(date, split, report, timeDecimal)
+	if len(diffs) != 1 || diffs[0].kind != diffShifted || diffs[0].was[1] != "11.00" {
+		t.Errorf("expected the workshop's second chunk shifted, got %v", diffs)
+	}
+}
+
+func Test_formatDiff(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	diffs := []chunkDiff{{
+		kind: diffChanged,
+		now:  []string{"2024-05-01", "09.00", "10.00", "1.00", `Standup, then "retro"`, "ACME", "a"},
+		was:  []string{"2024-05-01", "09.00", "10.00", "1.00", "Standup", "ACME", "a"},
+	}}
+
+	output := formatDiff(date, diffs)
+
+	line := `changed,09.00,10.00,"Standup, then ""retro""",ACME,09.00,10.00,Standup,ACME` + "\n"
+	if !strings.Contains(output, line) {
+		t.Errorf("expected the diff to contain %q, got:\n%s", line, output)
+	}
+}
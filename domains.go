@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// assignDomainProjects sets the project of event chunks without one from
+// the domains rules: the project of the most common email domain among the
+// attendees outside your organization, and yours. A tie sets none.
+func assignDomainProjects(chunks []*Chunk, domains map[string]string, internal []string) {
+	if len(domains) == 0 {
+		return
+	}
+	projects := map[string]string{}
+	for domain, project := range domains {
+		projects[strings.ToLower(domain)] = project
+	}
+	for _, chunk := range chunks {
+		if chunk.Event == nil || chunk.project != "" {
+			continue
+		}
+		domain := meetingDomain(chunk.Event, internal)
+		if project, ok := projects[domain]; ok && domain != "" {
+			chunk.project = project
+			chunk.explain("project %s from attendee domain %s", project, domain)
+			chunk.matched("attendeeDomains " + domain)
+		}
+	}
+}
+
+// meetingDomain returns the most common email domain of the event's guests,
+// leaving out rooms, you, your domain and the internal ones, "" if there's
+// none or a tie.
+func meetingDomain(e *Event, internal []string) string {
+	skip := map[string]bool{}
+	for _, domain := range internal {
+		skip[strings.ToLower(domain)] = true
+	}
+	for _, a := range e.Attendees {
+		if a.Self {
+			skip[emailDomain(a.Email)] = true
+		}
+	}
+	counts := map[string]int{}
+	for _, a := range e.Attendees {
+		if domain := emailDomain(a.Email); !a.Self && !a.Resource && domain != "" && !skip[domain] {
+			counts[domain]++
+		}
+	}
+	domains := make([]string, 0, len(counts))
+	for domain := range counts {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool { return counts[domains[i]] > counts[domains[j]] })
+	if len(domains) == 0 || (len(domains) > 1 && counts[domains[0]] == counts[domains[1]]) {
+		return ""
+	}
+	return domains[0]
+}
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func Test_assignDomainProjects(t *testing.T) {
+	attendees := func(emails ...string) []*Attendee {
+		list := []*Attendee{{Email: "me@mycorp.com", Self: true}}
+		for _, email := range emails {
+			list = append(list, &Attendee{Email: email})
+		}
+		return list
+	}
+	domains := map[string]string{"ACME.com": "ACME", "globex.com": "Globex"}
+	internal := []string{"mycorp.io"}
+
+	tests := []struct {
+		name     string
+		chunk    *Chunk
+		expected string
+	}{
+		{
+			name:     "majority domain",
+			chunk:    &Chunk{Event: &Event{Attendees: attendees("a@acme.com", "b@Acme.com", "c@globex.com")}},
+			expected: "ACME",
+		},
+		{
+			name:     "own and internal domains ignored",
+			chunk:    &Chunk{Event: &Event{Attendees: attendees("a@mycorp.com", "b@mycorp.com", "c@mycorp.io", "d@globex.com")}},
+			expected: "Globex",
+		},
+		{
+			name:     "rooms ignored",
+			chunk:    &Chunk{Event: &Event{Attendees: append(attendees("a@acme.com"), &Attendee{Email: "room1@globex.com", Resource: true}, &Attendee{Email: "room2@globex.com", Resource: true})}},
+			expected: "ACME",
+		},
+		{
+			name:  "tie",
+			chunk: &Chunk{Event: &Event{Attendees: attendees("a@acme.com", "c@globex.com")}},
+		},
+		{
+			name:  "unmapped majority",
+			chunk: &Chunk{Event: &Event{Attendees: attendees("a@initech.com", "b@initech.com", "c@acme.com")}},
+		},
+		{
+			name:     "project already set",
+			chunk:    &Chunk{project: "Internal", Event: &Event{Attendees: attendees("a@acme.com")}},
+			expected: "Internal",
+		},
+		{
+			name:  "gap",
+			chunk: &Chunk{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignDomainProjects([]*Chunk{tt.chunk}, domains, internal)
+			if tt.chunk.project != tt.expected {
+				t.Errorf("expected project %q, got %q", tt.expected, tt.chunk.project)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PushedEntry is a remote entry a push created for a chunk, so pushing the
+// day again updates or deletes it rather than creating another.
+type PushedEntry struct {
+	Chunk string `json:"chunk"` // the chunk's key, see chunkKeys
+	ID    string `json:"id"`    // the target's ID of the entry
+	Hash  string `json:"hash"`  // of what was sent, to leave unchanged chunks be
+	// Spent is the time logged for the chunk, by targets that log time on
+	// something rather than create entries; ID is then what it was logged on.
+	Spent time.Duration `json:"spent,omitempty"`
+}
+
+// entryTracker is implemented by targets that record the entries they push,
+// given those recorded by the day's last push beforehand.
+type entryTracker interface {
+	track(previous []PushedEntry)
+	tracked() []PushedEntry
+}
+
+// entryUndoer is implemented by trackers that can delete what they pushed.
+type entryUndoer interface {
+	entryTracker
+	// undo deletes the entries tracked, those of the day's last push.
+	undo(ctx context.Context, dryRun bool) error
+}
+
+// pushedKey keys the entries of a target's push of date in the history.
+func pushedKey(target string, date time.Time) string {
+	return target + " " + date.Format(dateLayout)
+}
+
+// chunkKeys identifies each chunk across pushes: by its event's ID, numbered
+// if the event is split into several chunks, or by its start for gaps.
+func chunkKeys(chunks []*Chunk) []string {
+	keys := make([]string, len(chunks))
+	seen := map[string]int{}
+	for i, chunk := range chunks {
+		key := "gap " + chunk.start.Format(time.RFC3339)
+		if chunk.Event != nil {
+			key = chunk.Event.ID
+		}
+		keys[i] = occurrenceKey(seen, key)
+	}
+	return keys
+}
+
+// occurrenceKey numbers the keys seen more than once, counting them in seen:
+// the key, then "key #2" and on.
+func occurrenceKey(seen map[string]int, key string) string {
+	if seen[key]++; seen[key] > 1 {
+		return fmt.Sprintf("%s #%d", key, seen[key])
+	}
+	return key
+}
+
+// entrySync tracks a push's entries against the last push's, for targets to
+// embed. Entries not yet kept or forgotten stay tracked, so a push failing
+// midway loses none.
+type entrySync struct {
+	previous map[string]PushedEntry
+	current  []PushedEntry
+}
+
+func (s *entrySync) track(previous []PushedEntry) {
+	s.previous = map[string]PushedEntry{}
+	for _, entry := range previous {
+		s.previous[entry.Chunk] = entry
+	}
+}
+
+func (s *entrySync) tracked() []PushedEntry {
+	entries := append([]PushedEntry{}, s.current...)
+	for _, entry := range s.previous {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Chunk < entries[j].Chunk })
+	return entries
+}
+
+// lookup returns the entry last pushed for the chunk key, if any, and
+// whether body differs from what was sent then.
+func (s *entrySync) lookup(key string, body []byte) (PushedEntry, bool, bool) {
+	entry, ok := s.previous[key]
+	return entry, entry.Hash != hashBody(body), ok
+}
+
+// keep records the entry id as pushed for the chunk key with body.
+func (s *entrySync) keep(key, id string, body []byte) {
+	delete(s.previous, key)
+	s.current = append(s.current, PushedEntry{Chunk: key, ID: id, Hash: hashBody(body)})
+}
+
+// keepSpent records d as the time logged on id for the chunk key.
+func (s *entrySync) keepSpent(key, id string, d time.Duration) {
+	delete(s.previous, key)
+	s.current = append(s.current, PushedEntry{Chunk: key, ID: id, Spent: d})
+}
+
+// forget stops tracking the entry last pushed for the chunk key, once what
+// it logged has been taken back.
+func (s *entrySync) forget(key string) {
+	delete(s.previous, key)
+}
+
+// stale returns the entries last pushed for chunks no longer there, once
+// the rest have been kept.
+func (s *entrySync) stale() []PushedEntry {
+	entries := make([]PushedEntry, 0, len(s.previous))
+	for _, entry := range s.previous {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Chunk < entries[j].Chunk })
+	return entries
+}
+
+// deleteStale deletes the stale entries with del, describing each as what,
+// e.g. "timesheet record", and counting them on from sent.
+func (s *entrySync) deleteStale(dryRun bool, sent int, what string, del func(id string) error) error {
+	for _, entry := range s.stale() {
+		if !dryRun {
+			if err := del(entry.ID); err != nil {
+				return partial(sent, err)
+			}
+			delete(s.previous, entry.Chunk)
+			sent++
+		}
+		fmt.Printf("%sDeleted %s %s\n", dryRunPrefix(dryRun), what, entry.ID)
+	}
+	return nil
+}
+
+func hashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_chunkKeys(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), Event: &Event{ID: "a"}},
+		{start: date.Add(10 * time.Hour)},
+		{start: date.Add(11 * time.Hour), Event: &Event{ID: "a"}},
+	}
+	expected := []string{"a", "gap 2024-03-15T10:00:00Z", "a #2"}
+	keys := chunkKeys(chunks)
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("expected key %q, got %q", expected[i], keys[i])
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Sources of events.
+const (
+	sourceGoogle   = "google"
+	sourceFreeBusy = "freebusy"
+	sourceInput    = "input"
+)
+
+// Google Calendar event types besides the default.
+const (
+	eventFocusTime       = "focusTime"
+	eventOutOfOffice     = "outOfOffice"
+	eventWorkingLocation = "workingLocation"
+)
+
+// Event is a calendar event as chunking sees it, whichever calendar it came
+// from. Providers convert their own events to it, so only they depend on
+// their SDKs.
+type Event struct {
+	ID string
+	// ICalUID is shared by the copies of an event on several calendars, and
+	// by the instances of a recurring one.
+	ICalUID     string
+	Summary     string // the event's title
+	Description string
+	Status      string // "cancelled" for cancelled instances
+	ColorID     string // Google Calendar's colorId, "" for the calendar's color
+	Start, End  time.Time
+	// AllDay events have dates rather than times: Start and End are the
+	// midnights starting their first day and ending their last.
+	AllDay bool
+	// Recurring is set for the instances of recurring events.
+	Recurring bool
+	Creator   Person
+	Organizer Person
+	Attendees []*Attendee
+	// Source is where the event came from, e.g. sourceGoogle, and Calendar
+	// the ID of its calendar there if it has several.
+	Source   string
+	Calendar string
+	// Mirrors are the other calendars with a copy of the event, which was
+	// counted once.
+	Mirrors []string
+	// Type is Google Calendar's eventType, e.g. eventFocusTime, "" or
+	// "default" for ordinary events.
+	Type string
+	// WorkingLocation is where a workingLocation event says you work:
+	// "home", or the office's or custom location's label.
+	WorkingLocation string
+}
+
+// Person is the creator or organizer of an event. Self is set when it is
+// you, or whoever's calendar is reported on.
+type Person struct {
+	Email string
+	Self  bool
+}
+
+// Attendee is a guest of an event and their Response: "accepted",
+// "tentative", "declined" or "needsAction". Resource attendees are rooms and
+// equipment.
+type Attendee struct {
+	Email    string
+	Self     bool
+	Optional bool
+	Resource bool
+	Response string
+}
+
+// fromGoogle converts a Calendar API event, reading all-day events' dates in
+// loc.
+func fromGoogle(e *calendar.Event, loc *time.Location) *Event {
+	event := &Event{
+		ID:          e.Id,
+		ICalUID:     e.ICalUID,
+		Summary:     e.Summary,
+		Description: e.Description,
+		Status:      e.Status,
+		ColorID:     e.ColorId,
+		Recurring:   e.RecurringEventId != "",
+		Type:        e.EventType,
+		Source:      sourceGoogle,
+	}
+	if e.Start != nil && e.End != nil {
+		event.AllDay = e.Start.DateTime == "" || e.End.DateTime == ""
+		event.Start, event.End = googleTime(e.Start, loc), googleTime(e.End, loc)
+	}
+	if e.Creator != nil {
+		event.Creator = Person{Email: e.Creator.Email, Self: e.Creator.Self}
+	}
+	if e.Organizer != nil {
+		event.Organizer = Person{Email: e.Organizer.Email, Self: e.Organizer.Self}
+	}
+	for _, a := range e.Attendees {
+		event.Attendees = append(event.Attendees, &Attendee{
+			Email:    a.Email,
+			Self:     a.Self,
+			Optional: a.Optional,
+			Resource: a.Resource,
+			Response: a.ResponseStatus,
+		})
+	}
+	if p := e.WorkingLocationProperties; p != nil {
+		event.WorkingLocation = workingLocation(p)
+	}
+	return event
+}
+
+func workingLocation(p *calendar.EventWorkingLocationProperties) string {
+	switch {
+	case p.OfficeLocation != nil && p.OfficeLocation.Label != "":
+		return p.OfficeLocation.Label
+	case p.CustomLocation != nil && p.CustomLocation.Label != "":
+		return p.CustomLocation.Label
+	case p.Type == "homeOffice" || p.HomeOffice != nil:
+		return "home"
+	case p.Type == "officeLocation":
+		return "office"
+	}
+	return "elsewhere"
+}
+
+// fromGoogleAll converts the Calendar API's events.
+func fromGoogleAll(items []*calendar.Event, loc *time.Location) []*Event {
+	events := make([]*Event, 0, len(items))
+	for _, e := range items {
+		events = append(events, fromGoogle(e, loc))
+	}
+	return events
+}
+
+func googleTime(dt *calendar.EventDateTime, loc *time.Location) time.Time {
+	if dt.DateTime == "" {
+		t, _ := time.ParseInLocation(dateLayout, dt.Date, loc)
+		return t
+	}
+	t, _ := time.Parse(time.RFC3339, dt.DateTime)
+	return t
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func Test_fromGoogle(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+
+	timed := fromGoogle(&calendar.Event{
+		Id:               "standup_20240311T143000Z",
+		Summary:          "Standup",
+		Start:            &calendar.EventDateTime{DateTime: "2024-03-11T09:30:00-05:00"},
+		End:              &calendar.EventDateTime{DateTime: "2024-03-11T09:45:00-05:00"},
+		RecurringEventId: "standup",
+		Organizer:        &calendar.EventOrganizer{Email: "lead@example.com"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "me@example.com", Self: true, ResponseStatus: "tentative"},
+			{Email: "room@example.com", Resource: true, ResponseStatus: "accepted"},
+		},
+	}, loc)
+	if timed.AllDay || !timed.Recurring || timed.Source != sourceGoogle {
+		t.Errorf("expected a recurring timed event from Google, got %+v", timed)
+	}
+	if !timed.Start.Equal(time.Date(2024, 3, 11, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected the start to be 14:30 UTC, got %s", timed.Start)
+	}
+	if timed.Creator.Self || timed.Organizer.Email != "lead@example.com" {
+		t.Errorf("expected the organizer without a creator, got %+v and %+v", timed.Creator, timed.Organizer)
+	}
+	if len(timed.Attendees) != 2 || timed.Attendees[0].Response != "tentative" || !timed.Attendees[1].Resource {
+		t.Errorf("expected both attendees with their responses, got %+v", timed.Attendees)
+	}
+
+	allDay := fromGoogle(&calendar.Event{
+		Summary: "Offsite",
+		Start:   &calendar.EventDateTime{Date: "2024-03-12"},
+		End:     &calendar.EventDateTime{Date: "2024-03-14"},
+	}, loc)
+	if !allDay.AllDay {
+		t.Errorf("expected an all-day event")
+	}
+	if !allDay.Start.Equal(time.Date(2024, 3, 12, 0, 0, 0, 0, loc)) || !allDay.End.Equal(time.Date(2024, 3, 14, 0, 0, 0, 0, loc)) {
+		t.Errorf("expected the dates' midnights in the location, got %s to %s", allDay.Start, allDay.End)
+	}
+}
+
+func Test_workingLocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties *calendar.EventWorkingLocationProperties
+		expected   string
+	}{
+		{name: "home", properties: &calendar.EventWorkingLocationProperties{Type: "homeOffice", HomeOffice: map[string]any{}}, expected: "home"},
+		{name: "office", properties: &calendar.EventWorkingLocationProperties{Type: "officeLocation", OfficeLocation: &calendar.EventWorkingLocationPropertiesOfficeLocation{Label: "Berlin HQ"}}, expected: "Berlin HQ"},
+		{name: "unlabeled office", properties: &calendar.EventWorkingLocationProperties{Type: "officeLocation"}, expected: "office"},
+		{name: "custom", properties: &calendar.EventWorkingLocationProperties{Type: "customLocation", CustomLocation: &calendar.EventWorkingLocationPropertiesCustomLocation{Label: "ACME site"}}, expected: "ACME site"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := fromGoogle(&calendar.Event{EventType: eventWorkingLocation, WorkingLocationProperties: test.properties}, time.UTC)
+			if e.Type != eventWorkingLocation || e.WorkingLocation != test.expected {
+				t.Errorf("expected a working location at '%s', got '%s' at '%s'", test.expected, e.Type, e.WorkingLocation)
+			}
+		})
+	}
+}
+
+func Test_Chunkify_eventTypes(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	focus := &Event{Summary: "Focus time", Type: eventFocusTime, Start: date.Add(9 * time.Hour), End: date.Add(11 * time.Hour)}
+	away := &Event{Summary: "Dentist", Type: eventOutOfOffice, Start: date.Add(13 * time.Hour), End: date.Add(15 * time.Hour)}
+	office := &Event{Summary: "Office", Type: eventWorkingLocation, WorkingLocation: "office", Start: date.Add(9 * time.Hour), End: date.Add(17 * time.Hour)}
+
+	chunks := Chunkify(date, []*Event{focus, office, away}, Options{})
+
+	expected := []string{"09:00-11:00 Focus time", "11:00-13:00 ", "15:00-17:00 "}
+	if len(chunks) != len(expected) {
+		t.Fatalf("expected %d chunks, got %d", len(expected), len(chunks))
+	}
+	for i, chunk := range chunks {
+		if got := chunk.start.Format("15:04") + "-" + chunk.end.Format("15:04") + " " + chunk.notes; got != expected[i] {
+			t.Errorf("expected chunk %d to be '%s', got '%s'", i, expected[i], got)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Exit codes, so scripts can tell failures apart without reading the logs:
+//
+//	0  success
+//	1  any other failure
+//	2  invalid flags or arguments
+//	3  invalid config file
+//	4  authentication failed; log in again with `chunkit auth`
+//	5  a request to Google Calendar or an export target failed
+//	6  no events in the reported days, with -fail-empty
+//	7  a push failed after sending some of the chunks
+const (
+	exitFailure       = 1
+	exitUsage         = 2
+	exitConfig        = 3
+	exitAuth          = 4
+	exitAPI           = 5
+	exitNoEvents      = 6
+	exitPartialExport = 7
+)
+
+// exitError is an error that exits chunkit with code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode attaches code to err, nil staying nil. Refused refresh tokens
+// are authentication failures whatever code is given.
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	if code == exitAPI && revoked(err) {
+		code = exitAuth
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode is the code err exits with, exitFailure unless one is attached.
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
+// partial marks an export error as partial once some chunks were sent.
+func partial(sent int, err error) error {
+	if sent == 0 {
+		return withExitCode(exitAPI, err)
+	}
+	return withExitCode(exitPartialExport, fmt.Errorf("%v (after sending %d)", err, sent))
+}
+
+// fatal logs err and exits with its code.
+func fatal(err error) {
+	log.Print(err)
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func Test_exitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "plain error", err: errors.New("boom"), expected: exitFailure},
+		{name: "config error", err: withExitCode(exitConfig, errors.New("bad json")), expected: exitConfig},
+		{name: "wrapped again", err: fmt.Errorf("error building the day: %w", withExitCode(exitAPI, errors.New("503"))), expected: exitAPI},
+		{name: "revoked token", err: withExitCode(exitAPI, fmt.Errorf("error fetching: %w", &oauth2.RetrieveError{ErrorCode: "invalid_grant"})), expected: exitAuth},
+		{name: "nothing sent", err: partial(0, errors.New("503")), expected: exitAPI},
+		{name: "some sent", err: partial(2, errors.New("503")), expected: exitPartialExport},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := exitCode(test.err); code != test.expected {
+				t.Errorf("expected exit code %d, got %d", test.expected, code)
+			}
+		})
+	}
+
+	if withExitCode(exitAPI, nil) != nil {
+		t.Errorf("expected no error to stay nil")
+	}
+}
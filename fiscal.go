@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// FiscalCalendar is how the company divides its year into months and
+// quarters. Fiscal years are named after the calendar year they end in, so
+// with StartMonth 4, FY2024 runs from April 2023 to March 2024.
+type FiscalCalendar struct {
+	// StartMonth is the month the fiscal year starts in, default 1 (January).
+	StartMonth int `json:"startMonth"`
+	// Pattern is "calendar" (the default) for calendar months, or "4-4-5",
+	// "4-5-4" or "5-4-4" for quarters of 13 weeks split into periods of that
+	// many weeks. Week-based years start on the Monday nearest the first of
+	// StartMonth, and the last period takes the extra week of 53-week years.
+	Pattern string `json:"pattern"`
+}
+
+const patternCalendar = "calendar"
+
+var fiscalPatterns = map[string][3]int{
+	"4-4-5": {4, 4, 5},
+	"4-5-4": {4, 5, 4},
+	"5-4-4": {5, 4, 4},
+}
+
+func (f FiscalCalendar) validate() error {
+	if f.StartMonth < 0 || f.StartMonth > 12 {
+		return fmt.Errorf("invalid fiscal startMonth %d: must be 1 to 12", f.StartMonth)
+	}
+	if _, ok := fiscalPatterns[f.Pattern]; !ok && f.Pattern != "" && f.Pattern != patternCalendar {
+		return fmt.Errorf("invalid fiscal pattern %q: must be calendar, 4-4-5, 4-5-4 or 5-4-4", f.Pattern)
+	}
+	return nil
+}
+
+func (f FiscalCalendar) startMonth() time.Month {
+	if f.StartMonth == 0 {
+		return time.January
+	}
+	return time.Month(f.StartMonth)
+}
+
+// yearStart returns the first day of fiscal year fy.
+func (f FiscalCalendar) yearStart(fy int) time.Time {
+	year := fy
+	if f.startMonth() > time.January {
+		year--
+	}
+	first := time.Date(year, f.startMonth(), 1, 0, 0, 0, 0, time.UTC)
+	if _, ok := fiscalPatterns[f.Pattern]; !ok {
+		return first
+	}
+
+	// the nearest Monday
+	sinceMonday := (int(first.Weekday()) + 6) % 7
+	if sinceMonday <= 3 {
+		return first.AddDate(0, 0, -sinceMonday)
+	}
+	return first.AddDate(0, 0, 7-sinceMonday)
+}
+
+// periodStart returns the first day of fiscal month period (1 to 12) of fy.
+func (f FiscalCalendar) periodStart(fy, period int) time.Time {
+	start := f.yearStart(fy)
+	weeks, ok := fiscalPatterns[f.Pattern]
+	if !ok {
+		return start.AddDate(0, period-1, 0)
+	}
+	days := 0
+	for p := 1; p < period; p++ {
+		days += weeks[(p-1)%3] * 7
+	}
+	return start.AddDate(0, 0, days)
+}
+
+// locate returns the fiscal year and month (1 to 12) that date falls in.
+func (f FiscalCalendar) locate(date time.Time) (fy, period int) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	fy = date.Year()
+	if f.startMonth() > time.January && date.Month() >= f.startMonth() {
+		fy++
+	}
+	// week-based years start up to three days either side of the month
+	if date.Before(f.yearStart(fy)) {
+		fy--
+	} else if !date.Before(f.yearStart(fy + 1)) {
+		fy++
+	}
+
+	period = 1
+	for period < 12 && !date.Before(f.periodStart(fy, period+1)) {
+		period++
+	}
+	return fy, period
+}
+
+// label names the fiscal month or, with quarters, the quarter date falls in,
+// e.g. FY2024-P07 or FY2024-Q3.
+func (f FiscalCalendar) label(date time.Time, quarters bool) string {
+	fy, period := f.locate(date)
+	if quarters {
+		return fmt.Sprintf("FY%d-Q%d", fy, (period+2)/3)
+	}
+	return fmt.Sprintf("FY%d-P%02d", fy, period)
+}
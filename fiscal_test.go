@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_FiscalCalendar_label(t *testing.T) {
+	tests := []struct {
+		name     string
+		fiscal   FiscalCalendar
+		date     string
+		quarters bool
+		expected string
+	}{
+		{name: "calendar months", fiscal: FiscalCalendar{}, date: "2024-03-15", expected: "FY2024-P03"},
+		{name: "april start", fiscal: FiscalCalendar{StartMonth: 4}, date: "2023-04-01", expected: "FY2024-P01"},
+		{name: "april start year end", fiscal: FiscalCalendar{StartMonth: 4}, date: "2024-03-31", expected: "FY2024-P12"},
+		{name: "april start quarter", fiscal: FiscalCalendar{StartMonth: 4}, date: "2023-10-01", quarters: true, expected: "FY2024-Q3"},
+		{name: "4-4-5 second period", fiscal: FiscalCalendar{Pattern: "4-4-5"}, date: "2024-01-29", expected: "FY2024-P02"},
+		{name: "4-4-5 long period", fiscal: FiscalCalendar{Pattern: "4-4-5"}, date: "2024-03-31", expected: "FY2024-P03"},
+		{name: "4-4-5 next quarter", fiscal: FiscalCalendar{Pattern: "4-4-5"}, date: "2024-04-01", quarters: true, expected: "FY2024-Q2"},
+		{name: "4-4-5 last day", fiscal: FiscalCalendar{Pattern: "4-4-5"}, date: "2024-12-29", expected: "FY2024-P12"},
+		{name: "4-4-5 next year starts early", fiscal: FiscalCalendar{Pattern: "4-4-5"}, date: "2024-12-30", expected: "FY2025-P01"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			date, _ := time.Parse(dateLayout, test.date)
+			if label := test.fiscal.label(date, test.quarters); label != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, label)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// freeBusyScope only grants the busy times of calendars, not their events.
+const freeBusyScope = "https://www.googleapis.com/auth/calendar.freebusy"
+
+// busySummary names the busy times, whose events are unknown.
+const busySummary = "Busy"
+
+// fetchBusy lists the calendar's busy times overlapping from-to as events
+// you attended, for reporting without access to the events themselves.
+func fetchBusy(ctx context.Context, calendarService *calendar.Service, calendarID string, from, to time.Time) ([]*Event, error) {
+	res, err := calendarService.Freebusy.Query(&calendar.FreeBusyRequest{
+		TimeMin: from.Format(time.RFC3339),
+		TimeMax: to.Format(time.RFC3339),
+		Items:   []*calendar.FreeBusyRequestItem{{Id: calendarID}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, withExitCode(exitAPI, fmt.Errorf("error fetching the busy times: %w", err))
+	}
+	busy, ok := res.Calendars[calendarID]
+	if !ok {
+		return nil, fmt.Errorf("error fetching the busy times: no calendar %q in the response", calendarID)
+	}
+	if len(busy.Errors) > 0 {
+		return nil, fmt.Errorf("error fetching the busy times of %s: %s", calendarID, busy.Errors[0].Reason)
+	}
+	return busyEvents(busy.Busy), nil
+}
+
+// busyEvents turns busy periods into events you accepted, named busySummary.
+func busyEvents(periods []*calendar.TimePeriod) []*Event {
+	items := make([]*Event, 0, len(periods))
+	for _, p := range periods {
+		start, _ := time.Parse(time.RFC3339, p.Start)
+		end, _ := time.Parse(time.RFC3339, p.End)
+		items = append(items, &Event{
+			Summary:   busySummary,
+			Start:     start,
+			End:       end,
+			Attendees: []*Attendee{{Self: true, Response: "accepted"}},
+			Source:    sourceFreeBusy,
+		})
+	}
+	return items
+}
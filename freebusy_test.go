@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func Test_busyEvents(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	periods := []*calendar.TimePeriod{
+		{Start: date.Add(10 * time.Hour).Format(time.RFC3339), End: date.Add(11 * time.Hour).Format(time.RFC3339)},
+		{Start: date.Add(14 * time.Hour).Format(time.RFC3339), End: date.Add(15*time.Hour + 30*time.Minute).Format(time.RFC3339)},
+	}
+
+	chunks := Chunkify(date, busyEvents(periods), Options{})
+
+	expectedNotes := []string{"", busySummary, "", busySummary, ""}
+	if len(chunks) != len(expectedNotes) {
+		t.Fatalf("expected %d chunks, got %d", len(expectedNotes), len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.notes != expectedNotes[i] {
+			t.Errorf("expected chunk notes to be '%s', got '%s'", expectedNotes[i], chunk.notes)
+		}
+	}
+	if end := chunks[3].end; !end.Equal(date.Add(15*time.Hour + 30*time.Minute)) {
+		t.Errorf("expected the busy time to end at 15:30, got %s", formatTime(end))
+	}
+}
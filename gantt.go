@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	formatSVG = "svg"
+	formatPNG = "png"
+)
+
+// The chart's layout in pixels.
+const (
+	ganttLabelWidth = 110 // the days' labels left of the lanes
+	ganttHourWidth  = 60
+	ganttLaneHeight = 24
+	ganttLaneGap    = 8
+	ganttTop        = 24 // the hours' labels above the lanes
+	ganttLegendRow  = 20
+)
+
+var (
+	ganttGapColor   = paletteColor{css: "#ddd", rgb: color.RGBA{221, 221, 221, 255}}
+	ganttEventColor = paletteColor{css: "#999", rgb: color.RGBA{153, 153, 153, 255}}
+	ganttGridColor  = color.RGBA{238, 238, 238, 255}
+)
+
+// gantt is the reports laid out as a Gantt chart: a lane per day, across the
+// hours of the day from the earliest chunk to the latest.
+type gantt struct {
+	from, to      int // the hours shown
+	width, height int
+	lanes         []ganttLane
+	legend        []siteProject
+}
+
+type ganttLane struct {
+	label string
+	y     int
+	bars  []ganttBar
+}
+
+// ganttBar is a row of a report placed in its lane.
+type ganttBar struct {
+	x, width int
+	color    paletteColor
+	row      Row
+}
+
+func newGantt(reports []*Report) *gantt {
+	g := &gantt{from: startOfDay, to: endOfDay}
+	first := true
+	for _, r := range reports {
+		for _, row := range r.Rows {
+			from := int(row.Start.Sub(r.Date).Hours())
+			to := int(row.End.Sub(r.Date).Hours() + 0.999)
+			if first || from < g.from {
+				g.from = from
+			}
+			if first || to > g.to {
+				g.to = to
+			}
+			first = false
+		}
+	}
+	g.from, g.to = max(g.from, 0), max(g.to, g.from+1)
+
+	for i, r := range reports {
+		lane := ganttLane{label: r.Date.Format("Mon Jan 2"), y: ganttTop + i*(ganttLaneHeight+ganttLaneGap)}
+		for _, row := range r.Rows {
+			x := g.x(row.Start.Sub(r.Date))
+			lane.bars = append(lane.bars, ganttBar{x: x, width: max(g.x(row.End.Sub(r.Date))-x, 1), color: rowColor(row), row: row})
+		}
+		g.lanes = append(g.lanes, lane)
+	}
+
+	for _, p := range projectTotals(reports) {
+		if p.Name != "" && p.Color != "" {
+			g.legend = append(g.legend, p)
+		}
+	}
+	g.width = ganttLabelWidth + (g.to-g.from)*ganttHourWidth + ganttLaneGap
+	g.height = ganttTop + len(reports)*(ganttLaneHeight+ganttLaneGap) + len(g.legend)*ganttLegendRow
+	return g
+}
+
+// rowColor is the color of the row's project, gray for gaps and a darker
+// gray for events without one.
+func rowColor(row Row) paletteColor {
+	if c, ok := findColor(row.Color); ok {
+		return c
+	}
+	if row.Gap {
+		return ganttGapColor
+	}
+	return ganttEventColor
+}
+
+// x is where a time of day is on the chart.
+func (g *gantt) x(d time.Duration) int {
+	return ganttLabelWidth + int((d.Hours()-float64(g.from))*ganttHourWidth)
+}
+
+// writeSVG draws the reports as a Gantt chart, each chunk titled with its
+// times and notes, and a legend of the projects' colors.
+func writeSVG(w io.Writer, reports []*Report, timeFormat string) error {
+	g := newGantt(reports)
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", g.width, g.height))
+	buf.WriteString(fmt.Sprintf(`<rect width="%d" height="%d" fill="#fff"/>`+"\n", g.width, g.height))
+	bottom := ganttTop + len(g.lanes)*(ganttLaneHeight+ganttLaneGap)
+	for hour := g.from; hour <= g.to; hour++ {
+		x := g.x(time.Duration(hour) * time.Hour)
+		buf.WriteString(fmt.Sprintf(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#eee"/>`+"\n", x, ganttTop-4, x, bottom))
+		buf.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="middle" fill="#666">%02d:00</text>`+"\n", x, ganttTop-8, hour))
+	}
+	for i, lane := range g.lanes {
+		r := reports[i]
+		buf.WriteString(fmt.Sprintf(`<text x="0" y="%d">%s</text>`+"\n", lane.y+ganttLaneHeight*2/3, lane.label))
+		for _, bar := range lane.bars {
+			title := fmt.Sprintf("%s-%s %s", formatTimeAs(timeFormat, r.Date, bar.row.Start), formatTimeAs(timeFormat, r.Date, bar.row.End), bar.row.title())
+			buf.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#fff"><title>%s</title></rect>`+"\n",
+				bar.x, lane.y, bar.width, ganttLaneHeight, bar.color.css, html.EscapeString(title)))
+		}
+	}
+	for i, p := range g.legend {
+		y := bottom + i*ganttLegendRow
+		buf.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="12" height="12" fill="%s"/>`+"\n", ganttLabelWidth, y, p.Color))
+		buf.WriteString(fmt.Sprintf(`<text x="%d" y="%d">%s</text>`+"\n", ganttLabelWidth+18, y+11, html.EscapeString(p.Name)))
+	}
+	buf.WriteString("</svg>\n")
+	_, err := fmt.Fprint(w, buf.String())
+	return err
+}
+
+// writePNG rasterizes the chart's lanes and hour lines. The standard library
+// has no fonts, so it leaves out the labels and legend the SVG has.
+func writePNG(w io.Writer, reports []*Report) error {
+	g := newGantt(reports)
+	img := image.NewRGBA(image.Rect(0, 0, g.width, g.height-len(g.legend)*ganttLegendRow))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for hour := g.from; hour <= g.to; hour++ {
+		x := g.x(time.Duration(hour) * time.Hour)
+		draw.Draw(img, image.Rect(x, ganttTop-4, x+1, img.Bounds().Max.Y), image.NewUniform(ganttGridColor), image.Point{}, draw.Src)
+	}
+	for _, lane := range g.lanes {
+		for _, bar := range lane.bars {
+			// a pixel's white border tells adjacent chunks apart
+			rect := image.Rect(bar.x, lane.y, bar.x+max(bar.width-1, 1), lane.y+ganttLaneHeight)
+			draw.Draw(img, rect, image.NewUniform(bar.color.rgb), image.Point{}, draw.Src)
+		}
+	}
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("error encoding the PNG: %v", err)
+	}
+	return nil
+}
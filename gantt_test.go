@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_newGantt(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	reports := []*Report{
+		newReport(date, testChunks(date), reportColumns{notes: true, project: true}),
+		newReport(date.AddDate(0, 0, 1), nil, reportColumns{notes: true}),
+	}
+
+	g := newGantt(reports)
+
+	if g.from != 9 || g.to != 12 {
+		t.Errorf("expected the chart to run from 9 to 12, got %d to %d", g.from, g.to)
+	}
+	if len(g.lanes) != 2 || len(g.lanes[0].bars) != 2 || len(g.lanes[1].bars) != 0 {
+		t.Fatalf("expected a lane per day with its chunks, got %+v", g.lanes)
+	}
+	standup, gap := g.lanes[0].bars[0], g.lanes[0].bars[1]
+	if standup.x != ganttLabelWidth || standup.width != ganttHourWidth || standup.color.css != projectColor("ACME", nil).css {
+		t.Errorf("expected the standup at the start in the ACME color, got %+v", standup)
+	}
+	if gap.x != ganttLabelWidth+ganttHourWidth || gap.width != ganttHourWidth*3/2 || gap.color != ganttGapColor {
+		t.Errorf("expected the gap after it in gray, got %+v", gap)
+	}
+	if len(g.legend) != 1 || g.legend[0].Name != "ACME" {
+		t.Errorf("expected ACME in the legend, got %+v", g.legend)
+	}
+}
+
+func Test_writeSVG(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	chunks := testChunks(date)
+	chunks[0].notes = "Standup & retro"
+	reports := []*Report{newReport(date, chunks, reportColumns{notes: true, project: true})}
+
+	buf := strings.Builder{}
+	if err := writeSVG(&buf, reports, timeClock); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, s := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"`,
+		">Wed May 1</text>",
+		">09:00</text>",
+		"<title>09:00-10:00 Standup &amp; retro</title>",
+		"<title>10:00-11:30 Unallocated</title>",
+		">ACME</text>",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected the chart to contain %q, got:\n%s", s, buf.String())
+		}
+	}
+}
+
+func Test_writePNG(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	reports := []*Report{newReport(date, testChunks(date), reportColumns{notes: true, project: true})}
+
+	buf := bytes.Buffer{}
+	if err := writePNG(&buf, reports); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("expected a PNG, got %v", err)
+	}
+
+	if got := img.At(ganttLabelWidth+10, ganttTop+10); got != projectColor("ACME", nil).rgb {
+		t.Errorf("expected the standup in the ACME color, got %v", got)
+	}
+	if got := img.At(ganttLabelWidth+ganttHourWidth+10, ganttTop+10); got != ganttGapColor.rgb {
+		t.Errorf("expected the gap in gray, got %v", got)
+	}
+}
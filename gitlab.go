@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// gitLabPusher logs time on the GitLab issues and merge requests referenced
+// in chunk notes by posting /spend quick actions, which unlike the
+// add_spent_time endpoint let us record the date the time was spent. Time
+// logged by an earlier push is tracked per chunk, so pushing again only
+// spends the difference.
+type gitLabPusher struct {
+	entrySync
+	baseURL *string
+	project *string
+}
+
+func newGitLabPusher(fs *flag.FlagSet) pusher {
+	return &gitLabPusher{
+		baseURL: fs.String("gitlab-url", "https://gitlab.com", "Base URL of the GitLab instance (token from $GITLAB_TOKEN)"),
+		project: fs.String("gitlab-project", "", "Project path used for bare #123 and !45 references"),
+	}
+}
+
+// gitLabRef is an issue (#) or merge request (!) reference.
+type gitLabRef struct {
+	project string
+	kind    string
+	iid     string
+}
+
+func (r gitLabRef) String() string {
+	return r.project + r.kind + r.iid
+}
+
+var gitLabRefPattern = regexp.MustCompile(`([\w.-]+(?:/[\w.-]+)+)?([#!])(\d+)\b`)
+
+// parseGitLabRefs finds the references in text, resolving bare ones against
+// defaultProject and dropping them if it is empty.
+func parseGitLabRefs(text, defaultProject string) []gitLabRef {
+	var refs []gitLabRef
+	for _, m := range gitLabRefPattern.FindAllStringSubmatch(text, -1) {
+		ref := gitLabRef{project: m[1], kind: m[2], iid: m[3]}
+		if ref.project == "" {
+			ref.project = defaultProject
+		}
+		if ref.project != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+func (p *gitLabPusher) push(ctx context.Context, config *Config, date time.Time, chunks []*Chunk, dryRun bool) error {
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" && !dryRun {
+		return errors.New("GITLAB_TOKEN is not set")
+	}
+
+	// total the time to spend per reference: what the chunks referencing it
+	// take now, less what was spent on them before, splitting chunks that
+	// reference several
+	keys := chunkKeys(chunks)
+	spent := map[gitLabRef]time.Duration{}
+	entries := map[gitLabRef][]PushedEntry{}
+	var order []gitLabRef
+	add := func(ref gitLabRef, entry PushedEntry, d time.Duration) {
+		if _, ok := entries[ref]; !ok {
+			order = append(order, ref)
+		}
+		spent[ref] += d
+		entries[ref] = append(entries[ref], entry)
+	}
+	current := map[string]bool{}
+	for i, chunk := range chunks {
+		refs := parseGitLabRefs(chunk.notes, *p.project)
+		for _, ref := range refs {
+			entry := PushedEntry{Chunk: keys[i] + " on " + ref.String(), ID: ref.String(), Spent: chunk.end.Sub(chunk.start) / time.Duration(len(refs))}
+			previous, _, _ := p.lookup(entry.Chunk, nil)
+			add(ref, entry, entry.Spent-previous.Spent)
+			current[entry.Chunk] = true
+		}
+	}
+	// the time spent on chunks since removed or no longer referencing it
+	for _, entry := range p.stale() {
+		refs := parseGitLabRefs(entry.ID, "")
+		if current[entry.Chunk] || len(refs) == 0 {
+			continue
+		}
+		add(refs[0], PushedEntry{Chunk: entry.Chunk}, -entry.Spent)
+	}
+
+	sent := 0
+	for _, ref := range order {
+		if spent[ref] != 0 {
+			if !dryRun {
+				if err := p.spend(ctx, token, ref, spent[ref], date); err != nil {
+					return partial(sent, err)
+				}
+				sent++
+			}
+			fmt.Printf("%sSpent %s on %s\n", dryRunPrefix(dryRun), formatSpentChange(spent[ref]), ref)
+		}
+		if dryRun {
+			continue
+		}
+		for _, entry := range entries[ref] {
+			if entry.Spent == 0 {
+				p.forget(entry.Chunk)
+				continue
+			}
+			p.keepSpent(entry.Chunk, entry.ID, entry.Spent)
+		}
+	}
+	return nil
+}
+
+// formatSpentChange formats a change in time spent for /spend, negative to
+// take time back, e.g. -30m.
+func formatSpentChange(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatSpent(-d)
+	}
+	return formatSpent(d)
+}
+
+func (p *gitLabPusher) spend(ctx context.Context, token string, ref gitLabRef, d time.Duration, date time.Time) error {
+	resource := "issues"
+	if ref.kind == "!" {
+		resource = "merge_requests"
+	}
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/%s/%s/notes",
+		strings.TrimRight(*p.baseURL, "/"), url.PathEscape(ref.project), resource, ref.iid)
+
+	form := url.Values{}
+	form.Set("body", fmt.Sprintf("/spend %s %s", formatSpentChange(d), date.Format(dateLayout)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling GitLab: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error spending time on %s: %s", ref, resp.Status)
+	}
+	return nil
+}
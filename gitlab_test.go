@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_gitLabPusher(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	var spent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		spent = append(spent, r.URL.EscapedPath()+" "+r.PostForm.Get("body"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	t.Setenv("GITLAB_TOKEN", "secret")
+
+	newPusher := func() *gitLabPusher {
+		fs := flag.NewFlagSet("push gitlab", flag.ContinueOnError)
+		p := newGitLabPusher(fs).(*gitLabPusher)
+		fs.Parse([]string{"-gitlab-url", server.URL, "-gitlab-project", "group/app"})
+		return p
+	}
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Fix #12 and !7", Event: &Event{ID: "fix"}},
+		{start: date.Add(10 * time.Hour), end: date.Add(11 * time.Hour), notes: "Review #12", Event: &Event{ID: "review"}},
+		{start: date.Add(13 * time.Hour), end: date.Add(14 * time.Hour), notes: "Pair on #13", Event: &Event{ID: "pair"}},
+	}
+
+	first := newPusher()
+	if err := first.push(context.Background(), &Config{}, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{
+		"/api/v4/projects/group%2Fapp/issues/12/notes /spend 1h30m 2024-03-15",
+		"/api/v4/projects/group%2Fapp/merge_requests/7/notes /spend 30m 2024-03-15",
+		"/api/v4/projects/group%2Fapp/issues/13/notes /spend 1h 2024-03-15",
+	}
+	if strings.Join(spent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %v, got %v", expected, spent)
+	}
+
+	// pushing again unchanged spends nothing
+	spent = nil
+	second := newPusher()
+	second.track(first.tracked())
+	if err := second.push(context.Background(), &Config{}, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(spent) != 0 {
+		t.Errorf("expected nothing spent again, got %v", spent)
+	}
+
+	// then only the differences: the review grew, the pairing went
+	spent = nil
+	chunks[1].end = date.Add(11*time.Hour + 30*time.Minute)
+	third := newPusher()
+	third.track(second.tracked())
+	if err := third.push(context.Background(), &Config{}, date, chunks[:2], false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected = []string{
+		"/api/v4/projects/group%2Fapp/issues/12/notes /spend 30m 2024-03-15",
+		"/api/v4/projects/group%2Fapp/issues/13/notes /spend -1h 2024-03-15",
+	}
+	if strings.Join(spent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %v, got %v", expected, spent)
+	}
+	if tracked := third.tracked(); len(tracked) != 3 {
+		t.Errorf("expected the 3 chunk references tracked, got %v", tracked)
+	}
+}
+
+func Test_parseGitLabRefs(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		defaultProject string
+		expected       []string
+	}{
+		{
+			name:     "full references",
+			text:     "Review group/api!45 and group/sub/web#12",
+			expected: []string{"group/api!45", "group/sub/web#12"},
+		},
+		{
+			name:           "bare references use the default project",
+			text:           "Pairing on #7",
+			defaultProject: "group/api",
+			expected:       []string{"group/api#7"},
+		},
+		{
+			name:     "bare references without a default project are dropped",
+			text:     "Pairing on #7",
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			refs := parseGitLabRefs(test.text, test.defaultProject)
+			if len(refs) != len(test.expected) {
+				t.Fatalf("expected %d references, got %d", len(test.expected), len(refs))
+			}
+			for i, ref := range refs {
+				if ref.String() != test.expected[i] {
+					t.Errorf("expected reference '%s', got '%s'", test.expected[i], ref)
+				}
+			}
+		})
+	}
+}
+
+func Test_formatSpent(t *testing.T) {
+	tests := map[time.Duration]string{
+		45 * time.Minute:             "45m",
+		2 * time.Hour:                "2h",
+		time.Hour + 30*time.Minute:   "1h30m",
+		time.Hour + 29*time.Second:   "1h",
+		7*time.Hour + 15*time.Minute: "7h15m",
+	}
+	for d, expected := range tests {
+		if spent := formatSpent(d); spent != expected {
+			t.Errorf("expected %s to format as '%s', got '%s'", d, expected, spent)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const formatHTML = "html"
+
+// htmlReport is what -format html is executed with: the days, and the time
+// per project across them.
+type htmlReport struct {
+	Title    string
+	Days     []*Report
+	Total    time.Duration
+	Projects []siteProject
+}
+
+// writeHTML renders the reports as a standalone page, each day a timeline of
+// its chunks, gaps in gray, above their table, with the totals.
+func writeHTML(w io.Writer, reports []*Report, timeFormat string) error {
+	tmpl, err := siteTemplate(timeFormat)
+	if err != nil {
+		return err
+	}
+	if tmpl, err = tmpl.Parse(htmlTemplate); err != nil {
+		return fmt.Errorf("error parsing the HTML template: %v", err)
+	}
+
+	page := htmlReport{Days: reports, Projects: projectTotals(reports)}
+	for _, r := range reports {
+		page.Total += r.Total
+	}
+	if len(reports) > 0 {
+		page.Title = "Chunks for " + reports[0].Date.Format(dateLayout)
+		if last := reports[len(reports)-1]; len(reports) > 1 {
+			page.Title += " to " + last.Date.Format(dateLayout)
+		}
+	}
+	if err := tmpl.ExecuteTemplate(w, "report", page); err != nil {
+		return fmt.Errorf("error executing the HTML template: %v", err)
+	}
+	return nil
+}
+
+const htmlTemplate = `
+{{define "report"}}{{template "head" .Title}}<h1>{{.Title}}</h1>
+<p>{{hours .Total}}{{if gt (len .Days) 1}} over {{len .Days}} days{{end}}.</p>
+{{if .Projects}}<table>
+<tr><th>Project</th><th>Time</th></tr>
+{{range .Projects}}<tr><td>{{if .Color}}<span class="swatch" style="background: {{.Color}}"></span> {{end}}{{or .Name "(none)"}}</td><td>{{hours .Total}}</td></tr>
+{{end}}</table>
+{{end}}{{range .Days}}<h2>{{.Date.Format "Monday, January 2 2006"}}</h2>
+<p>{{hours .Total}}{{if .Location}} at {{.Location}}{{end}}{{if .Absence}}, absent ({{.Absence}}){{end}}.</p>
+{{template "chunks" .}}{{end}}</body>
+</html>
+{{end}}
+`
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_writeHTML(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	reports := []*Report{
+		newReport(date, testChunks(date), reportColumns{notes: true, project: true}),
+		newReport(date.AddDate(0, 0, 1), nil, reportColumns{notes: true}),
+	}
+
+	buf := strings.Builder{}
+	if err := writeHTML(&buf, reports, timeClock); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, s := range []string{
+		"<title>Chunks for 2024-05-01 to 2024-05-02</title>",
+		"2:30 over 2 days.",
+		"Wednesday, May 1 2024",
+		"left: 0.00%; width: 40.00%; background: " + projectColor("ACME", nil).css,
+		"background: #ddd",
+		"<td>Standup</td>",
+		"Nothing recorded.",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected the page to contain %q, got:\n%s", s, buf.String())
+		}
+	}
+}
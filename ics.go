@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const formatICS = "ics"
+
+const icsTime = "20060102T150405Z"
+
+// writeICS renders the reports as an iCalendar file with an event per chunk,
+// gaps included, for importing into a calendar to check the days against.
+// now is the DTSTAMP.
+func writeICS(reports []*Report, now time.Time) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//chunkit//chunkit//EN",
+		"CALSCALE:GREGORIAN",
+	}
+	for _, r := range reports {
+		lines = append(lines, r.icsEvents(now)...)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	buf := strings.Builder{}
+	for _, line := range lines {
+		buf.WriteString(icsFold(line))
+	}
+	return buf.String()
+}
+
+// icsEvents are the unfolded VEVENT lines of the report's rows.
+func (r *Report) icsEvents(now time.Time) []string {
+	var lines []string
+	for i, row := range r.Rows {
+		var description []string
+		if row.Project != "" {
+			description = append(description, "Project: "+row.Project)
+		}
+		if row.OnCall {
+			description = append(description, "On call")
+		}
+		description = append(description, row.Links...)
+		description = append(description, row.Explain...)
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s-%d@chunkit", r.Date.Format(dateLayout), i),
+			"DTSTAMP:"+now.UTC().Format(icsTime),
+			"DTSTART:"+row.Start.UTC().Format(icsTime),
+			"DTEND:"+row.End.UTC().Format(icsTime),
+			"SUMMARY:"+icsEscape(row.title()),
+		)
+		if len(description) > 0 {
+			lines = append(lines, "DESCRIPTION:"+icsEscape(strings.Join(description, "\n")))
+		}
+		if c, ok := findColor(row.Color); ok {
+			lines = append(lines, "COLOR:"+c.css) // RFC 7986
+		}
+		lines = append(lines, "TRANSP:TRANSPARENT", "END:VEVENT")
+	}
+	return lines
+}
+
+// icsEscape escapes a TEXT value per RFC 5545.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsFold ends a content line with CRLF, folding it into lines of at most 75
+// octets without splitting UTF-8 characters.
+func icsFold(line string) string {
+	buf := strings.Builder{}
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // the leading space counts
+	}
+	buf.WriteString(line + "\r\n")
+	return buf.String()
+}
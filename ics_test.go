@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_writeICS(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour)},
+		{Event: newEvent(date.Add(10*time.Hour), date.Add(11*time.Hour), "", "accepted", true), start: date.Add(10 * time.Hour), end: date.Add(11 * time.Hour), notes: "Review; part 1, with Sam", project: "ACME"},
+	}
+
+	output := writeICS([]*Report{newReport(date, chunks, reportColumns{notes: true, project: true})}, date)
+
+	for _, line := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20240311T090000Z\r\nDTEND:20240311T100000Z\r\nSUMMARY:Unallocated\r\n",
+		"SUMMARY:Review\\; part 1\\, with Sam\r\nDESCRIPTION:Project: ACME\r\nCOLOR:" + projectColor("ACME", nil).css + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected the calendar to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func Test_icsFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 50)
+
+	folded := icsFold(line)
+
+	for _, l := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("expected lines of at most 75 octets, got %d", len(l))
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line+"\r\n" {
+		t.Errorf("expected folding to be reversible, got %q", unfolded)
+	}
+}
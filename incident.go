@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// incident is the time spent responding to a PagerDuty incident, from its
+// first acknowledgement until it was resolved.
+type incident struct {
+	window
+	id    string
+	title string
+}
+
+// fetchPagerDutyIncidents returns the incidents assigned to the PagerDuty user
+// between from and to, with response windows taken from their log entries.
+func fetchPagerDutyIncidents(ctx context.Context, token, userID string, from, to time.Time) ([]incident, error) {
+	query := url.Values{}
+	query.Set("user_ids[]", userID)
+	query.Set("since", from.Format(time.RFC3339))
+	query.Set("until", to.Format(time.RFC3339))
+	query.Add("statuses[]", "acknowledged")
+	query.Add("statuses[]", "resolved")
+
+	var result struct {
+		Incidents []struct {
+			ID        string    `json:"id"`
+			Title     string    `json:"title"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"incidents"`
+	}
+	if err := pagerDutyGet(ctx, token, "/incidents", query, &result); err != nil {
+		return nil, err
+	}
+
+	incidents := make([]incident, 0, len(result.Incidents))
+	for _, inc := range result.Incidents {
+		var entries struct {
+			LogEntries []struct {
+				Type      string    `json:"type"`
+				CreatedAt time.Time `json:"created_at"`
+			} `json:"log_entries"`
+		}
+		if err := pagerDutyGet(ctx, token, "/incidents/"+url.PathEscape(inc.ID)+"/log_entries", url.Values{}, &entries); err != nil {
+			return nil, err
+		}
+
+		// an incident still open is being worked on until the end of the report
+		w := window{start: inc.CreatedAt, end: to}
+		acknowledged := false
+		for _, entry := range entries.LogEntries {
+			switch entry.Type {
+			case "acknowledge_log_entry":
+				if !acknowledged || entry.CreatedAt.Before(w.start) {
+					w.start = entry.CreatedAt
+					acknowledged = true
+				}
+			case "resolve_log_entry":
+				w.end = entry.CreatedAt
+			}
+		}
+		if w.end.After(w.start) {
+			incidents = append(incidents, incident{window: w, id: inc.ID, title: inc.Title})
+		}
+	}
+	return incidents, nil
+}
+
+// overlayIncidents labels gap time spent responding to incidents with the
+// incident ID. Meetings keep precedence, so only gap chunks are relabeled,
+// split where an incident starts or ends inside them.
+func overlayIncidents(chunks []*Chunk, incidents []incident) []*Chunk {
+	boundaries := make([]time.Time, 0, len(incidents)*2)
+	for _, inc := range incidents {
+		boundaries = append(boundaries, inc.start, inc.end)
+	}
+
+	overlaid := splitAt(chunks, boundaries)
+	for _, chunk := range overlaid {
+		if chunk.Event != nil {
+			continue
+		}
+		for _, inc := range incidents {
+			if !chunk.start.Before(inc.start) && !chunk.end.After(inc.end) {
+				chunk.notes = fmt.Sprintf("PagerDuty incident %s: %s", inc.id, inc.title)
+				chunk.explain("during PagerDuty incident %s", inc.id)
+			}
+		}
+	}
+	return overlaid
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_overlayIncidents(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	meeting := newEvent(date.Add(10*time.Hour), date.Add(11*time.Hour), "standup", "accepted", true)
+	chunks := Chunkify(date, []*Event{meeting}, Options{})
+	incidents := []incident{
+		{window: window{start: date.Add(9*time.Hour + 30*time.Minute), end: date.Add(12 * time.Hour)}, id: "PT4KHLK", title: "API latency"},
+	}
+
+	overlaid := overlayIncidents(chunks, incidents)
+
+	expectedNotes := []string{"", "PagerDuty incident PT4KHLK: API latency", "standup", "PagerDuty incident PT4KHLK: API latency", ""}
+	if len(overlaid) != len(expectedNotes) {
+		t.Fatalf("expected %d chunks, got %d", len(expectedNotes), len(overlaid))
+	}
+	for i, chunk := range overlaid {
+		if chunk.notes != expectedNotes[i] {
+			t.Errorf("expected chunk notes to be '%s', got '%s'", expectedNotes[i], chunk.notes)
+		}
+		if i > 0 && chunk.start != overlaid[i-1].end {
+			t.Errorf("expected chunk %d to start at %s, got %s", i, overlaid[i-1].end, chunk.start)
+		}
+	}
+}
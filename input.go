@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// inputEvent is the simplified schema -input accepts besides the Calendar
+// API's events: Start and End are RFC 3339 times, or dates for all-day events,
+// and Response is your answer, "accepted" if empty.
+type inputEvent struct {
+	ID       string `json:"id"`
+	Summary  string `json:"summary"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Response string `json:"response"`
+	Status   string `json:"status"`
+	ColorID  string `json:"colorId"`
+}
+
+// readInput reads a JSON array of events from path, standard input for "-",
+// reading all-day events' dates in loc. Each element is either a Calendar API
+// event or an inputEvent.
+func readInput(path string, loc *time.Location) ([]*Event, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the -input events: %v", err)
+	}
+	items, err := decodeInput(data)
+	if err != nil {
+		return nil, err
+	}
+	events := fromGoogleAll(items, loc)
+	for _, e := range events {
+		e.Source = sourceInput
+	}
+	return events, nil
+}
+
+func decodeInput(data []byte) ([]*calendar.Event, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error decoding the -input events: must be a JSON array: %v", err)
+	}
+
+	items := make([]*calendar.Event, 0, len(raw))
+	for i, r := range raw {
+		var shape struct {
+			Start json.RawMessage `json:"start"`
+		}
+		if err := json.Unmarshal(r, &shape); err != nil {
+			return nil, fmt.Errorf("error decoding -input event %d: %v", i, err)
+		}
+
+		var e *calendar.Event
+		if bytes.HasPrefix(bytes.TrimSpace(shape.Start), []byte("{")) {
+			e = &calendar.Event{}
+			if err := json.Unmarshal(r, e); err != nil {
+				return nil, fmt.Errorf("error decoding -input event %d: %v", i, err)
+			}
+		} else {
+			var in inputEvent
+			if err := json.Unmarshal(r, &in); err != nil {
+				return nil, fmt.Errorf("error decoding -input event %d: %v", i, err)
+			}
+			e = in.event()
+		}
+		if e.Start == nil || e.End == nil {
+			return nil, fmt.Errorf("error decoding -input event %d: start and end are required", i)
+		}
+		if err := checkInputTime("start", e.Start); err != nil {
+			return nil, fmt.Errorf("error decoding -input event %d: %v", i, err)
+		}
+		if err := checkInputTime("end", e.End); err != nil {
+			return nil, fmt.Errorf("error decoding -input event %d: %v", i, err)
+		}
+		// Chunkify checks whether you created events without attendees
+		if e.Creator == nil {
+			e.Creator = &calendar.EventCreator{}
+		}
+		items = append(items, e)
+	}
+	return items, nil
+}
+
+// checkInputTime checks that an event's start or end, named field, is an
+// RFC 3339 time or a date, as googleTime can read.
+func checkInputTime(field string, dt *calendar.EventDateTime) error {
+	if dt.DateTime == "" {
+		if _, err := time.Parse(dateLayout, dt.Date); err != nil {
+			return fmt.Errorf("invalid %s date %q: must be YYYY-MM-DD", field, dt.Date)
+		}
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339, dt.DateTime); err != nil {
+		return fmt.Errorf("invalid %s %q: must be an RFC 3339 time, e.g. 2024-03-15T10:00:00Z", field, dt.DateTime)
+	}
+	return nil
+}
+
+// event converts the simplified event to the Calendar API's shape.
+func (in inputEvent) event() *calendar.Event {
+	response := in.Response
+	if response == "" {
+		response = "accepted"
+	}
+	return &calendar.Event{
+		Id:        in.ID,
+		Summary:   in.Summary,
+		Status:    in.Status,
+		ColorId:   in.ColorID,
+		Start:     inputTime(in.Start),
+		End:       inputTime(in.End),
+		Attendees: []*calendar.EventAttendee{{Self: true, ResponseStatus: response}},
+	}
+}
+
+func inputTime(value string) *calendar.EventDateTime {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.Parse(dateLayout, value); err == nil {
+		return &calendar.EventDateTime{Date: value}
+	}
+	return &calendar.EventDateTime{DateTime: value}
+}
+
+// fetchInput lists the -input events overlapping from-to in start time order,
+// like fetchEvents does for the calendar.
+func (o *reportOptions) fetchInput(from, to time.Time) ([]*Event, error) {
+	if o.inputItems == nil {
+		items, err := readInput(o.input, from.Location())
+		if err != nil {
+			return nil, err
+		}
+		o.inputItems = items
+	}
+
+	// copies, as buildDays chunks several days at once and Chunkify adds
+	// you to the attendees of the events you attend unlisted
+	var items []*Event
+	for _, e := range o.inputItems {
+		if e.Start.Before(to) && e.End.After(from) {
+			event := *e
+			event.Attendees = slices.Clone(e.Attendees)
+			items = append(items, &event)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Start.Before(items[j].Start) })
+	return items, nil
+}
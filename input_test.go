@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_decodeInput(t *testing.T) {
+	items, err := decodeInput([]byte(`[
+		{"summary": "Standup", "start": "2024-03-11T09:30:00Z", "end": "2024-03-11T09:45:00Z"},
+		{"summary": "Offsite", "start": "2024-03-12", "end": "2024-03-13"},
+		{"summary": "Review", "start": {"dateTime": "2024-03-11T14:00:00Z"}, "end": {"dateTime": "2024-03-11T15:00:00Z"},
+		 "attendees": [{"self": true, "responseStatus": "tentative"}]}
+	]`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(items))
+	}
+	if items[0].Attendees[0].ResponseStatus != "accepted" {
+		t.Errorf("expected simplified events to be accepted, got '%s'", items[0].Attendees[0].ResponseStatus)
+	}
+	if items[1].Start.Date != "2024-03-12" {
+		t.Errorf("expected a date to make an all-day event, got %+v", items[1].Start)
+	}
+	if items[2].Attendees[0].ResponseStatus != "tentative" {
+		t.Errorf("expected the Calendar API event's response, got '%s'", items[2].Attendees[0].ResponseStatus)
+	}
+
+	if _, err := decodeInput([]byte(`[{"summary": "No times"}]`)); err == nil {
+		t.Errorf("expected an error for an event without times")
+	}
+	for _, event := range []string{
+		`{"summary": "Standup", "start": "2024-03-15 10:00", "end": "2024-03-15T11:00:00Z"}`,
+		`{"summary": "Review", "start": {"dateTime": "2024-03-15T10:00:00Z"}, "end": {"date": "15/03/2024"}}`,
+	} {
+		_, err := decodeInput([]byte("[" + event + "]"))
+		if err == nil || !strings.Contains(err.Error(), "error decoding -input event 0") {
+			t.Errorf("expected an error decoding %s, got %v", event, err)
+		}
+	}
+}
+
+func Test_reportOptions_buildDay_input(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.json")
+	err := os.WriteFile(path, []byte(`[
+		{"summary": "Review", "start": "2024-03-11T14:00:00Z", "end": "2024-03-11T15:00:00Z"},
+		{"summary": "Standup", "start": "2024-03-11T09:30:00Z", "end": "2024-03-11T09:45:00Z"},
+		{"summary": "Tomorrow", "start": "2024-03-12T10:00:00Z", "end": "2024-03-12T11:00:00Z"}
+	]`), 0600)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	opts := &reportOptions{input: path, config: filepath.Join(dir, "config.json"), history: filepath.Join(dir, "history.json"), tentative: tentativeInclude}
+	// annotations are applied before the billable rules see the notes
+	err = updateStore(opts.history, func(s *Store) {
+		s.Annotations["2024-03-11"] = []TimedNote{{At: time.Date(2024, 3, 11, 10, 0, 0, 0, time.UTC), Text: "Admin [nb]"}}
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	calendarService, err := opts.service(ctx)
+	if err != nil || calendarService != nil {
+		t.Fatalf("expected no client and no error with -input, got %v", err)
+	}
+	chunks, err := opts.buildDay(ctx, calendarService, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedNotes := []string{"", "Standup", "Admin", "Review", ""}
+	if len(chunks) != len(expectedNotes) {
+		t.Fatalf("expected %d chunks, got %d", len(expectedNotes), len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.notes != expectedNotes[i] {
+			t.Errorf("expected chunk notes to be '%s', got '%s'", expectedNotes[i], chunk.notes)
+		}
+	}
+	if !chunks[2].nonBillable {
+		t.Errorf("expected the gap annotated [nb] to be non-billable")
+	}
+}
+
+func Test_reportOptions_fetchInput_copies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	err := os.WriteFile(path, []byte(`[
+		{"summary": "Offsite", "start": "2024-03-11T20:00:00Z", "end": "2024-03-12T04:00:00Z"}
+	]`), 0600)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	opts := &reportOptions{input: path}
+	from := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	// the days the event spans are chunked apart, each adding attendees
+	for i := range 2 {
+		day := from.AddDate(0, 0, i)
+		items, err := opts.fetchInput(day, day.Add(24*time.Hour))
+		if err != nil || len(items) != 1 {
+			t.Fatalf("expected the event and no error, got %v, %v", items, err)
+		}
+		if len(items[0].Attendees) != 1 {
+			t.Errorf("expected 1 attendee on day %d, got %d", i+1, len(items[0].Attendees))
+		}
+		items[0].Attendees = append(items[0].Attendees, &Attendee{Self: true})
+	}
+}
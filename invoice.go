@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// runInvoice implements the `invoice` subcommand, billing a client for a
+// month of time on its projects.
+func runInvoice(args []string) {
+	fs := flag.NewFlagSet("invoice", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	clientName := fs.String("client", "", "Client to invoice, from the config's clients")
+	month := fs.String("month", "", "Month to invoice as YYYY-MM (default last month)")
+	per := fs.String("per", "project", "Line items per project or per day")
+	path := fs.String("o", "", "Path to write the invoice to (default standard output)")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	client, ok := config.Clients[*clientName]
+	if !ok {
+		fatal(withExitCode(exitUsage, fmt.Errorf("unknown -client %q: add it to the clients in the config file", *clientName)))
+	}
+	if *per != "project" && *per != "day" {
+		fatal(withExitCode(exitUsage, fmt.Errorf("invalid -per %q: must be project or day", *per)))
+	}
+	if err := opts.validate(); err != nil {
+		fatal(err)
+	}
+	loc, err := opts.location()
+	if err != nil {
+		fatal(err)
+	}
+	from, err := parseMonth(*month, time.Now().In(loc))
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	// the day before the next month's first is the month's last
+	days := time.Date(from.Year(), from.Month()+1, 0, 0, 0, 0, 0, from.Location()).Day()
+	dayChunks, err := opts.buildDays(ctx, calendarService, from, days)
+	if err != nil {
+		fatal(err)
+	}
+	var chunks []*Chunk
+	for _, day := range dayChunks {
+		chunks = append(chunks, day...)
+	}
+
+	// the number is only issued once the invoice is written
+	invoice := newInvoice(config, *clientName, client, from, chunks, *per == "day")
+	store, err := openStore(opts.history)
+	if err != nil {
+		fatal(err)
+	}
+	invoice.Number = store.invoiceNumber(*clientName, from.Format("2006-01"))
+	render := func(w io.Writer) error {
+		return invoice.render(w, client.Template)
+	}
+	if *path != "" {
+		err = replaceFile(*path, "invoice", render)
+	} else {
+		buf := bytes.Buffer{}
+		if err = render(&buf); err == nil {
+			_, err = buf.WriteTo(os.Stdout)
+		}
+	}
+	if err != nil {
+		fatal(err)
+	}
+	err = updateStore(opts.history, func(s *Store) {
+		s.invoiceNumber(*clientName, from.Format("2006-01"))
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// parseMonth resolves a -month to midnight of its first day, last month if
+// value is empty.
+func parseMonth(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location()), nil
+	}
+	month, err := time.ParseInLocation("2006-01", value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -month %q: must be YYYY-MM", value)
+	}
+	return month, nil
+}
+
+// Invoice is what invoice templates are executed with.
+type Invoice struct {
+	Number   string
+	Client   string
+	Month    time.Time
+	Issued   time.Time
+	Currency string
+	Items    []InvoiceItem
+	Subtotal float64
+	TaxRate  float64 // percent
+	Tax      float64
+	Total    float64
+}
+
+// InvoiceItem is a line of an invoice: the time on a project, or on a project
+// on a single Date with -per day.
+type InvoiceItem struct {
+	Date    time.Time // zero per project
+	Project string
+	Hours   float64
+	Rate    float64
+	Amount  float64
+}
+
+// newInvoice bills the billable chunks on the client's projects at their
+// rates. The number is left to the caller.
+func newInvoice(config *Config, name string, client Client, month time.Time, chunks []*Chunk, perDay bool) *Invoice {
+	projects := map[string]bool{}
+	for _, project := range client.Projects {
+		projects[project] = true
+	}
+
+	invoice := &Invoice{Client: name, Month: month, Issued: time.Now(), Currency: config.Currency, TaxRate: client.Tax}
+	items := map[string]*InvoiceItem{}
+	for _, chunk := range chunks {
+		if !projects[chunk.project] || chunk.nonBillable {
+			continue
+		}
+		key, date := chunk.project, time.Time{}
+		if perDay {
+			date = time.Date(chunk.start.Year(), chunk.start.Month(), chunk.start.Day(), 0, 0, 0, 0, chunk.start.Location())
+			key = date.Format(dateLayout) + " " + key
+		}
+		item, ok := items[key]
+		if !ok {
+			item = &InvoiceItem{Date: date, Project: chunk.project, Rate: config.earned(chunk.project, time.Hour)}
+			items[key] = item
+		}
+		item.Hours += chunk.end.Sub(chunk.start).Hours()
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		item := items[key]
+		item.Amount = item.Hours * item.Rate
+		invoice.Subtotal += item.Amount
+		invoice.Items = append(invoice.Items, *item)
+	}
+	invoice.Tax = invoice.Subtotal * invoice.TaxRate / 100
+	invoice.Total = invoice.Subtotal + invoice.Tax
+	return invoice
+}
+
+// render executes the html/template at path with the invoice, or the
+// built-in one if path is empty. Templates can use "money" to format amounts
+// in the invoice's currency.
+func (inv *Invoice) render(w io.Writer, path string) error {
+	text := defaultInvoiceTemplate
+	if path != "" {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading the invoice template: %v", err)
+		}
+		text = string(bytes)
+	}
+	money := (&Config{Currency: inv.Currency}).money
+	tmpl, err := template.New("invoice").Funcs(template.FuncMap{"money": money}).Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing the invoice template: %v", err)
+	}
+	if err := tmpl.Execute(w, inv); err != nil {
+		return fmt.Errorf("error executing the invoice template: %v", err)
+	}
+	return nil
+}
+
+const defaultInvoiceTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 0.3em 0.6em; border-bottom: 1px solid #ccc; }
+td.number, th.number { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>To: {{.Client}}<br>
+Issued: {{.Issued.Format "2006-01-02"}}<br>
+For: {{.Month.Format "January 2006"}}</p>
+<table>
+<tr><th>Item</th><th class="number">Hours</th><th class="number">Rate</th><th class="number">Amount</th></tr>
+{{range .Items}}<tr><td>{{if not .Date.IsZero}}{{.Date.Format "2006-01-02"}} {{end}}{{.Project}}</td><td class="number">{{printf "%.2f" .Hours}}</td><td class="number">{{money .Rate}}</td><td class="number">{{money .Amount}}</td></tr>
+{{end}}<tr><td colspan="3">Subtotal</td><td class="number">{{money .Subtotal}}</td></tr>
+{{if .TaxRate}}<tr><td colspan="3">Tax ({{.TaxRate}}%)</td><td class="number">{{money .Tax}}</td></tr>
+{{end}}<tr><th colspan="3">Total</th><th class="number">{{money .Total}}</th></tr>
+</table>
+</body>
+</html>
+`
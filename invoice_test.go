@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_newInvoice(t *testing.T) {
+	month := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	day := month.AddDate(0, 0, 1)
+	chunks := []*Chunk{
+		{start: month.Add(9 * time.Hour), end: month.Add(11 * time.Hour), project: "ACME"},
+		{start: month.Add(11 * time.Hour), end: month.Add(12 * time.Hour), project: "ACME", nonBillable: true},
+		{start: month.Add(13 * time.Hour), end: month.Add(14 * time.Hour), project: "Internal"},
+		{start: day.Add(9 * time.Hour), end: day.Add(10 * time.Hour), project: "ACME Support"},
+	}
+	config := &Config{Rates: map[string]float64{"ACME": 100, "default": 50}, Currency: "EUR"}
+	client := Client{Projects: []string{"ACME", "ACME Support"}, Tax: 20}
+
+	invoice := newInvoice(config, "ACME Corp", client, month, chunks, false)
+
+	if len(invoice.Items) != 2 || invoice.Items[0].Project != "ACME" || invoice.Items[0].Hours != 2 || invoice.Items[1].Amount != 50 {
+		t.Errorf("expected 2h of ACME and 1h of ACME Support, got %+v", invoice.Items)
+	}
+	if invoice.Subtotal != 250 || invoice.Tax != 50 || invoice.Total != 300 {
+		t.Errorf("expected 250 + 50 tax = 300, got %.2f + %.2f = %.2f", invoice.Subtotal, invoice.Tax, invoice.Total)
+	}
+
+	perDay := newInvoice(config, "ACME Corp", client, month, chunks, true)
+	if len(perDay.Items) != 2 || !perDay.Items[1].Date.Equal(day) {
+		t.Errorf("expected an item per day, got %+v", perDay.Items)
+	}
+
+	invoice.Number = "2024-001"
+	buf := strings.Builder{}
+	if err := invoice.render(&buf, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, s := range []string{"Invoice 2024-001", "May 2024", "EUR 200.00", "Tax (20%)", "EUR 300.00"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected the invoice to contain %q, got:\n%s", s, buf.String())
+		}
+	}
+}
+
+func Test_Store_invoiceNumber(t *testing.T) {
+	s := &Store{Invoices: []InvoiceRecord{{Number: "2023-004", Client: "ACME Corp", Month: "2023-12"}}}
+
+	first := s.invoiceNumber("ACME Corp", "2024-04")
+	second := s.invoiceNumber("Globex", "2024-04")
+	again := s.invoiceNumber("ACME Corp", "2024-04")
+
+	if first != "2024-001" || second != "2024-002" || again != first {
+		t.Errorf("expected 2024-001, 2024-002 and 2024-001 again, got %s, %s and %s", first, second, again)
+	}
+}
+
+func Test_parseMonth(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{value: "", expected: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2024-05", expected: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "May 2024", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			month, err := parseMonth(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !month.Equal(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, month)
+			}
+		})
+	}
+}
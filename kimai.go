@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// kimaiPusher creates Kimai 2 timesheet records for the chunks matching the
+// "kimai" rules in the config, updating or deleting those of an earlier push.
+type kimaiPusher struct {
+	entrySync
+	baseURL *string
+	token   string
+	ids     map[string]int // resolved names, keyed by kind and path
+}
+
+func newKimaiPusher(fs *flag.FlagSet) pusher {
+	return &kimaiPusher{
+		baseURL: fs.String("kimai-url", "", "Base URL of the Kimai instance (API token from $KIMAI_TOKEN)"),
+		ids:     map[string]int{},
+	}
+}
+
+// matchKimaiRule returns the first rule matching the notes, or nil.
+func matchKimaiRule(rules []KimaiRule, notes string) *KimaiRule {
+	for i, rule := range rules {
+		if strings.Contains(strings.ToLower(notes), strings.ToLower(rule.Keyword)) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func (p *kimaiPusher) push(ctx context.Context, config *Config, date time.Time, chunks []*Chunk, dryRun bool) error {
+	if err := p.connect(dryRun); err != nil {
+		return err
+	}
+	if len(config.Kimai) == 0 {
+		return errors.New("no kimai rules in the config file")
+	}
+
+	sent := 0
+	keys := chunkKeys(chunks)
+	for i, chunk := range chunks {
+		rule := matchKimaiRule(config.Kimai, chunk.notes)
+		if rule == nil {
+			fmt.Printf("Skipped %s-%s %q: no matching kimai rule\n", formatTime(chunk.start), formatTime(chunk.end), chunk.notes)
+			continue
+		}
+
+		description := chunk.notes
+		if config.redacts("kimai", "notes") {
+			description = ""
+		}
+		record := map[string]any{
+			"begin":       chunk.start.Format("2006-01-02T15:04:05"),
+			"end":         chunk.end.Format("2006-01-02T15:04:05"),
+			"description": description,
+			"billable":    !chunk.nonBillable,
+		}
+		// changes are told by the rule's names rather than the IDs they
+		// resolve to, so dry runs needn't look them up
+		named, err := json.Marshal(map[string]any{"record": record, "customer": rule.Customer, "project": rule.Project, "activity": rule.Activity})
+		if err != nil {
+			return partial(sent, err)
+		}
+		entry, changed, pushed := p.lookup(keys[i], named)
+		verb := "Booked"
+		if pushed {
+			verb = "Updated"
+		}
+		switch {
+		case pushed && !changed:
+			p.keep(keys[i], entry.ID, named)
+			continue
+		case !dryRun:
+			if record["project"], record["activity"], err = p.resolveRule(ctx, rule); err != nil {
+				return partial(sent, err)
+			}
+			body, err := json.Marshal(record)
+			if err != nil {
+				return partial(sent, err)
+			}
+			id := entry.ID
+			if pushed {
+				err = p.do(ctx, http.MethodPatch, "/api/timesheets/"+id, nil, body, nil)
+			} else {
+				var created struct {
+					ID int `json:"id"`
+				}
+				err = p.do(ctx, http.MethodPost, "/api/timesheets", nil, body, &created)
+				id = strconv.Itoa(created.ID)
+			}
+			if err != nil {
+				return partial(sent, err)
+			}
+			p.keep(keys[i], id, named)
+			sent++
+		}
+		fmt.Printf("%s%s %s-%s to %s / %s / %s\n", dryRunPrefix(dryRun), verb, formatTime(chunk.start), formatTime(chunk.end), rule.Customer, rule.Project, rule.Activity)
+	}
+
+	// the records of chunks since removed or no longer matching a rule
+	return p.deleteStale(dryRun, sent, "timesheet record", func(id string) error {
+		return p.deleteEntry(ctx, id)
+	})
+}
+
+// connect checks the URL and, unless dryRun, the token the API calls use.
+func (p *kimaiPusher) connect(dryRun bool) error {
+	p.token = os.Getenv("KIMAI_TOKEN")
+	if p.token == "" && !dryRun {
+		return errors.New("KIMAI_TOKEN is not set")
+	}
+	if *p.baseURL == "" {
+		return errors.New("-kimai-url is required")
+	}
+	return nil
+}
+
+func (p *kimaiPusher) undo(ctx context.Context, dryRun bool) error {
+	if err := p.connect(dryRun); err != nil {
+		return err
+	}
+	return p.deleteStale(dryRun, 0, "timesheet record", func(id string) error {
+		return p.deleteEntry(ctx, id)
+	})
+}
+
+func (p *kimaiPusher) deleteEntry(ctx context.Context, id string) error {
+	return p.do(ctx, http.MethodDelete, "/api/timesheets/"+id, nil, nil, nil)
+}
+
+// resolveRule looks up the IDs of the rule's project and activity.
+func (p *kimaiPusher) resolveRule(ctx context.Context, rule *KimaiRule) (project, activity int, err error) {
+	customer, err := p.resolve(ctx, "customers", rule.Customer, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	project, err = p.resolve(ctx, "projects", rule.Project, url.Values{"customer": {strconv.Itoa(customer)}})
+	if err != nil {
+		return 0, 0, err
+	}
+	activity, err = p.resolve(ctx, "activities", rule.Activity, url.Values{"project": {strconv.Itoa(project)}})
+	return project, activity, err
+}
+
+// resolve looks up the ID of the customer, project or activity with the
+// given name, caching the result for the rest of the push.
+func (p *kimaiPusher) resolve(ctx context.Context, kind, name string, query url.Values) (int, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	key := kind + "/" + query.Encode() + "/" + name
+	if id, ok := p.ids[key]; ok {
+		return id, nil
+	}
+
+	query.Set("term", name)
+	var results []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/api/"+kind, query, nil, &results); err != nil {
+		return 0, err
+	}
+	for _, r := range results {
+		if strings.EqualFold(r.Name, name) {
+			p.ids[key] = r.ID
+			return r.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("error resolving %q: no matching Kimai %s", name, kind)
+}
+
+func (p *kimaiPusher) do(ctx context.Context, method, path string, query url.Values, body []byte, v any) error {
+	endpoint := strings.TrimRight(*p.baseURL, "/") + path
+	if query != nil {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling Kimai: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error calling Kimai: %s %s %s", method, path, resp.Status)
+	}
+	if v != nil {
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+	return nil
+}
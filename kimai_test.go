@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_kimaiPusher(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	var booked []map[string]any
+	var changed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/customers":
+			w.Write([]byte(`[{"id": 1, "name": "ACME Corp"}, {"id": 2, "name": "ACME Corp Holdings"}]`))
+		case "/api/projects":
+			w.Write([]byte(`[{"id": 10, "name": "Website"}]`))
+		case "/api/activities":
+			w.Write([]byte(`[{"id": 100, "name": "Meeting"}]`))
+		case "/api/timesheets":
+			record := map[string]any{}
+			json.NewDecoder(r.Body).Decode(&record)
+			booked = append(booked, record)
+			w.Write([]byte(`{"id": 1000}`))
+		case "/api/timesheets/5", "/api/timesheets/6":
+			changed = append(changed, r.Method+" "+r.URL.Path)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("KIMAI_TOKEN", "secret")
+
+	fs := flag.NewFlagSet("push kimai", flag.ContinueOnError)
+	p := newKimaiPusher(fs)
+	fs.Parse([]string{"-kimai-url", server.URL})
+
+	config := &Config{Kimai: []KimaiRule{
+		{Keyword: "acme", Customer: "ACME Corp", Project: "Website", Activity: "Meeting"},
+	}}
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "ACME sync"},
+		{start: date.Add(10 * time.Hour), end: date.Add(11 * time.Hour), notes: "Lunch"},
+	}
+
+	if err := p.push(context.Background(), config, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(booked) != 1 {
+		t.Fatalf("expected 1 timesheet record, got %d", len(booked))
+	}
+	if booked[0]["project"] != 10.0 || booked[0]["activity"] != 100.0 || booked[0]["begin"] != "2024-03-15T09:00:00" {
+		t.Errorf("expected ACME sync booked to project 10 activity 100 at 09:00, got %v", booked[0])
+	}
+	if booked[0]["description"] != "ACME sync" {
+		t.Errorf("expected description 'ACME sync', got %v", booked[0]["description"])
+	}
+
+	// rules still match on the notes withheld from kimai
+	booked = nil
+	config.Redact = map[string][]string{"kimai": {"notes"}}
+	if err := p.push(context.Background(), config, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(booked) != 1 || booked[0]["description"] != "" {
+		t.Errorf("expected 1 timesheet record without a description, got %v", booked)
+	}
+
+	// a dry run works offline, without a token or looking the IDs up
+	booked = nil
+	t.Setenv("KIMAI_TOKEN", "")
+	offline := newKimaiPusher(flag.NewFlagSet("push kimai", flag.ContinueOnError)).(*kimaiPusher)
+	unreachable := "http://127.0.0.1:0"
+	offline.baseURL = &unreachable
+	if err := offline.push(context.Background(), config, date, chunks, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(booked) != 0 {
+		t.Errorf("expected no timesheet records in a dry run, got %d", len(booked))
+	}
+	t.Setenv("KIMAI_TOKEN", "secret")
+
+	// pushing again updates the changed chunk's record and deletes the
+	// removed one's, leaving the unchanged one be
+	booked = nil
+	config.Redact = nil
+	chunks = []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "ACME sync", Event: &Event{ID: "sync"}},
+		{start: date.Add(13 * time.Hour), end: date.Add(14 * time.Hour), notes: "ACME review", Event: &Event{ID: "review"}},
+	}
+	first := newKimaiPusher(flag.NewFlagSet("push kimai", flag.ContinueOnError)).(*kimaiPusher)
+	first.baseURL = &server.URL
+	if err := first.push(context.Background(), config, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	previous := first.tracked() // review, then sync
+	previous[0].ID, previous[1].ID = "5", "7"
+	previous = append(previous, PushedEntry{Chunk: "gone", ID: "6"})
+
+	booked, changed = nil, nil
+	chunks[1].end = date.Add(14*time.Hour + 30*time.Minute)
+	second := newKimaiPusher(flag.NewFlagSet("push kimai", flag.ContinueOnError)).(*kimaiPusher)
+	second.baseURL = &server.URL
+	second.track(previous)
+	if err := second.push(context.Background(), config, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(booked) != 0 {
+		t.Errorf("expected no new timesheet records, got %v", booked)
+	}
+	expected := []string{"PATCH /api/timesheets/5", "DELETE /api/timesheets/6"}
+	if len(changed) != len(expected) || changed[0] != expected[0] || changed[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, changed)
+	}
+	if tracked := second.tracked(); len(tracked) != 2 || tracked[0].ID != "5" || tracked[1].ID != "7" {
+		t.Errorf("expected the records 5 and 7 tracked, got %v", tracked)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// runLink implements the `link` subcommand, which records links such as PR
+// URLs in the local history for the day's report to attach to the chunk
+// covering the time they were added.
+func runLink(args []string) {
+	fs := flag.NewFlagSet("link", flag.ExitOnError)
+	historyPath := fs.String("history", filepath.Join(dataDir(), "history.json"), "Path to the local report history")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: chunkit link [-history path] url...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	for _, link := range fs.Args() {
+		if !linkPattern.MatchString(link) {
+			fatal(withExitCode(exitUsage, fmt.Errorf("invalid link %q: must be an http or https URL", link)))
+		}
+	}
+
+	now := time.Now()
+	err := updateStore(*historyPath, func(s *Store) {
+		date := now.Format(dateLayout)
+		for _, link := range fs.Args() {
+			s.Links[date] = append(s.Links[date], TimedNote{At: now, Text: link})
+		}
+	})
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("Linked at %s.\n", now.Format("15:04"))
+}
+
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// extractLinks returns the distinct URLs in text, which may be HTML as event
+// descriptions often are.
+func extractLinks(text string) []string {
+	var links []string
+	seen := map[string]bool{}
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		link = strings.TrimRight(link, ".,;:!?)")
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// attachEventLinks adds the links in the events' descriptions to their
+// chunks.
+func attachEventLinks(chunks []*Chunk) {
+	for _, chunk := range chunks {
+		if chunk.Event != nil {
+			chunk.links = appendLinks(chunk.links, extractLinks(chunk.Event.Description)...)
+		}
+	}
+}
+
+// attachLinks adds each link to the chunk covering the time it was added.
+func attachLinks(chunks []*Chunk, links []TimedNote) {
+	for _, link := range links {
+		for _, chunk := range chunks {
+			if link.At.Before(chunk.start) || !link.At.Before(chunk.end) {
+				continue
+			}
+			chunk.links = appendLinks(chunk.links, link.Text)
+			break
+		}
+	}
+}
+
+// appendLinks appends the links not already in links. Like why, links may be
+// shared by copies of a chunk, so it never appends in place.
+func appendLinks(links []string, more ...string) []string {
+	links = links[:len(links):len(links)]
+	for _, link := range more {
+		found := false
+		for _, l := range links {
+			found = found || l == link
+		}
+		if !found {
+			links = append(links, link)
+		}
+	}
+	return links
+}
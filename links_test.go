@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_extractLinks(t *testing.T) {
+	description := `Review <a href="https://github.com/acme/api/pull/12">the PR</a>, notes in https://docs.example.com/d/abc. Again: https://github.com/acme/api/pull/12`
+
+	links := extractLinks(description)
+
+	expected := []string{"https://github.com/acme/api/pull/12", "https://docs.example.com/d/abc"}
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %v, got %v", expected, links)
+	}
+}
+
+func Test_attachLinks(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := testChunks(date)
+	chunks[0].links = []string{"https://docs.example.com/d/abc"}
+	copied := *chunks[0]
+
+	attachLinks(chunks, []TimedNote{
+		{At: date.Add(9*time.Hour + 30*time.Minute), Text: "https://github.com/acme/api/pull/12"},
+		{At: date.Add(9*time.Hour + 45*time.Minute), Text: "https://docs.example.com/d/abc"},
+		{At: date.Add(18 * time.Hour), Text: "https://example.com/late"},
+	})
+
+	expected := []string{"https://docs.example.com/d/abc", "https://github.com/acme/api/pull/12"}
+	if !reflect.DeepEqual(chunks[0].links, expected) {
+		t.Errorf("expected %v, got %v", expected, chunks[0].links)
+	}
+	if len(copied.links) != 1 {
+		t.Errorf("expected copies of the chunk to keep their links, got %v", copied.links)
+	}
+	if len(chunks[1].links) != 0 {
+		t.Errorf("expected no links outside the chunks, got %v", chunks[1].links)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// tagLocations sets each chunk's location to the working location you were
+// at when it started, a timed workingLocation event taking precedence over
+// an all-day one.
+func tagLocations(chunks []*Chunk, items []*Event) {
+	for _, chunk := range chunks {
+		chunk.location = ""
+		timed := false
+		for _, e := range items {
+			if e.Type != eventWorkingLocation || e.Status == "cancelled" || chunk.start.Before(e.Start) || !chunk.start.Before(e.End) {
+				continue
+			}
+			if timed && e.AllDay {
+				continue
+			}
+			chunk.location, timed = e.WorkingLocation, !e.AllDay
+		}
+	}
+}
+
+// dayLocation is where most of the day's chunks were worked, "" if the
+// calendar doesn't say.
+func dayLocation(chunks []*Chunk) string {
+	spent := map[string]time.Duration{}
+	location := ""
+	for _, chunk := range chunks {
+		if chunk.location == "" {
+			continue
+		}
+		spent[chunk.location] += chunk.end.Sub(chunk.start)
+		if location == "" || spent[chunk.location] > spent[location] {
+			location = chunk.location
+		}
+	}
+	return location
+}
+
+// locationRow is the days, and their hours, worked at a location, in a
+// period for yearLocations.
+type locationRow struct {
+	period   string
+	location string
+	days     int
+	hours    float64
+}
+
+// countLocations totals the days worked at each location, the most days
+// first, "unknown" for those the calendar doesn't say. Days without hours,
+// such as absences, don't count.
+func countLocations(records []*DayRecord) []locationRow {
+	var rows []locationRow
+	index := map[string]int{}
+	for _, record := range records {
+		if record == nil || record.Hours == 0 {
+			continue
+		}
+		location := record.Location
+		if location == "" {
+			location = "unknown"
+		}
+		i, ok := index[location]
+		if !ok {
+			i = len(rows)
+			index[location] = i
+			rows = append(rows, locationRow{location: location})
+		}
+		rows[i].days++
+		rows[i].hours += record.Hours
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].days > rows[j].days })
+	return rows
+}
+
+// yearLocations counts the recorded days worked at each location per
+// calendar year, e.g. for a tax return's home office days.
+func yearLocations(store *Store) []locationRow {
+	var rows []locationRow
+	var year string
+	var records []*DayRecord
+	flush := func() {
+		for _, row := range countLocations(records) {
+			row.period = year
+			rows = append(rows, row)
+		}
+	}
+	for _, date := range store.Dates() {
+		if len(date) < 4 {
+			continue
+		}
+		if date[:4] != year {
+			flush()
+			year, records = date[:4], nil
+		}
+		records = append(records, store.Days[date])
+	}
+	flush()
+	return rows
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_tagLocations(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	items := []*Event{
+		{Type: eventWorkingLocation, WorkingLocation: "home", AllDay: true, Start: date, End: date.AddDate(0, 0, 1)},
+		{Type: eventWorkingLocation, WorkingLocation: "ACME site", Start: date.Add(13 * time.Hour), End: date.Add(17 * time.Hour)},
+	}
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(13 * time.Hour)},
+		{start: date.Add(13 * time.Hour), end: date.Add(17 * time.Hour)},
+	}
+
+	tagLocations(chunks, items)
+
+	if chunks[0].location != "home" || chunks[1].location != "ACME site" {
+		t.Errorf("expected home then the ACME site, got '%s' then '%s'", chunks[0].location, chunks[1].location)
+	}
+}
+
+func Test_dayLocation(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(8 * time.Hour), end: date.Add(9 * time.Hour)},
+		{start: date.Add(9 * time.Hour), end: date.Add(11 * time.Hour), location: "home"},
+		{start: date.Add(11 * time.Hour), end: date.Add(17 * time.Hour), location: "ACME site"},
+	}
+
+	if location := dayLocation(chunks); location != "ACME site" {
+		t.Errorf("expected the ACME site, got '%s'", location)
+	}
+	if location := dayLocation(chunks[:1]); location != "" {
+		t.Errorf("expected no location, got '%s'", location)
+	}
+	if report := newReport(date, chunks, reportColumns{dayLocation: true}); !strings.Contains(report.csv(""), ", at ACME site.") {
+		t.Errorf("expected the report to be at the ACME site, got %q", report.csv(""))
+	}
+}
+
+func Test_yearLocations(t *testing.T) {
+	store := &Store{Days: map[string]*DayRecord{
+		"2023-12-29": {Hours: 8, Location: "home"},
+		"2024-01-02": {Hours: 8, Location: "Berlin HQ"},
+		"2024-01-03": {Hours: 6, Location: "home"},
+		"2024-01-04": {Hours: 7, Location: "home"},
+		"2024-01-05": {Hours: 8},
+		"2024-01-08": {Absence: "sick"},
+	}}
+
+	rows := yearLocations(store)
+
+	expected := []locationRow{
+		{period: "2023", location: "home", days: 1, hours: 8},
+		{period: "2024", location: "home", days: 2, hours: 13},
+		{period: "2024", location: "Berlin HQ", days: 1, hours: 8},
+		{period: "2024", location: "unknown", days: 1, hours: 8},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i, row := range rows {
+		if row != expected[i] {
+			t.Errorf("expected row %d to be %+v, got %+v", i, expected[i], row)
+		}
+	}
+}
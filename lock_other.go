@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+// lockFile is a no-op where flock is unavailable; Save replacing the history
+// file atomically still keeps it from being torn, though concurrent updates
+// may be lost.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
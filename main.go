@@ -2,20 +2,17 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"math"
-	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
 	"strings"
+	"syscall"
 	"time"
-
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"google.golang.org/api/calendar/v3"
-	"google.golang.org/api/option"
 )
 
 const (
@@ -24,189 +21,570 @@ const (
 	dateLayout = "2006-01-02" // YYYY-MM-DD
 )
 
+// commands maps the subcommands to their implementations, which get the
+// arguments after the subcommand's name. Without one, chunkit reports on the
+// -date.
+var commands map[string]func(args []string)
+
+func init() {
+	commands = map[string]func(args []string){
+		"annotate":   runAnnotate,
+		"auth":       runAuth,
+		"budget":     runBudget,
+		"capex":      runCapex,
+		"completion": runCompletion,
+		"db":         runDB,
+		"diff":       runDiff,
+		"invoice":    runInvoice,
+		"link":       runLink,
+		"mcp":        runMCP,
+		"note":       runNote,
+		"override":   runOverride,
+		"plan":       runPlan,
+		"push":       runPush,
+		"query":      runQuery,
+		"rollup":     runRollup,
+		"site":       runSite,
+		"stats":      runStats,
+		"summary":    runSummary,
+		"team":       runTeam,
+		"watch":      runWatch,
+	}
+}
+
 func main() {
-	dateStr := flag.String("date", time.Now().Format(dateLayout), "The date in the format 'YYYY-MM-DD'")
-	flag.Parse()
-	date, err := time.ParseInLocation(dateLayout, *dateStr, time.Now().Location())
-	if err != nil {
-		log.Fatal(err.Error())
+	if len(os.Args) > 1 && commands[os.Args[1]] != nil {
+		commands[os.Args[1]](os.Args[2:])
+		return
 	}
 
-	ctx := context.Background()
-	oauth2Client, err := authenticateClient(ctx)
+	opts := &reportOptions{}
+	opts.register(flag.CommandLine)
+	output := &outputOptions{}
+	output.register(flag.CommandLine)
+	failEmpty := flag.Bool("fail-empty", false, "Exit with code 6 after writing the report if the days have no events")
+	appendTo := flag.String("append", "", "Also append the days' chunks to this CSV, skipping those already in it, to build up a dataset")
+	if err := opts.parse(flag.CommandLine, os.Args[1:]); err != nil {
+		fatal(err)
+	}
+	if err := output.validate(); err != nil {
+		fatal(err)
+	}
+
+	from, days, err := opts.reportRange()
+	if err != nil {
+		fatal(err)
+	}
+	if err := opts.validate(); err != nil {
+		fatal(err)
+	}
+	config, err := opts.loadConfig()
 	if err != nil {
-		log.Fatalf(err.Error())
+		fatal(err)
 	}
-	calendarService, err := calendar.NewService(ctx, option.WithHTTPClient(oauth2Client))
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
 	if err != nil {
-		log.Fatalf(err.Error())
+		fatal(err)
+	}
+	store, err := openStore(opts.history)
+	if err != nil {
+		fatal(err)
 	}
 
-	result, _ := calendarService.Events.List("primary").
-		ShowDeleted(false).
-		SingleEvents(true).
-		TimeMin(date.Format(time.RFC3339)).
-		TimeMax(date.Add(24 * time.Hour).Format(time.RFC3339)).
-		OrderBy("startTime").
-		Do()
-
-	chunks := Chunkify(date, result.Items)
+	reports := make([]*Report, 0, days)
+	records := map[string]*DayRecord{}
+	events := 0
+	dayChunks, err := opts.buildDays(ctx, calendarService, from, days)
+	if err != nil {
+		fatal(err)
+	}
+	for i, chunks := range dayChunks {
+		date := from.AddDate(0, 0, i)
+		for _, chunk := range chunks {
+			if chunk.Event != nil {
+				events++
+			}
+		}
+		if drift := store.lockDrift(date, opts.dayFingerprint(date)); drift != "" {
+			log.Printf("warning: %s", drift)
+		}
+		report := newReport(date, chunks, opts.columns(config))
+		report.Absence = opts.absence(date)
+		reports = append(reports, report)
+		records[date.Format(dateLayout)] = newDayRecord(date, chunks, config, report.Absence)
+	}
 
-	totalHours := 0.0
-	buf := strings.Builder{}
+	// catch days whose calendar looks incomplete before they are billed
+	if days > 1 {
+		skip := map[string]bool{}
+		for date := range records {
+			skip[date] = true
+		}
+		if usual, ok := usualHours(store, skip, config.Workdays); ok {
+			for _, anomaly := range anomalies(records, usual, config.Workdays) {
+				log.Printf("warning: %s", anomaly)
+			}
+		}
+	}
 
-	buf.WriteString("start,end,notes\n")
-	for _, chunk := range chunks {
-		totalHours += chunk.end.Sub(chunk.start).Hours()
-		line := fmt.Sprintf("%s,%s,%s\n",
-			formatTime(chunk.start),
-			formatTime(chunk.end),
-			chunk.notes,
-		)
-		buf.WriteString(line)
+	// remember the days' totals for flex-time tracking and rollups
+	err = updateStore(opts.history, func(s *Store) {
+		for date, record := range records {
+			s.Days[date] = record
+		}
+		s.rebalance()
+	})
+	if err != nil {
+		fatal(err)
 	}
 
-	output := fmt.Sprintf(`
-CSV report for the date: %s with a total of %.2f hours.
+	if err := output.write(reports, opts.timeFormat); err != nil {
+		fatal(err)
+	}
+	if *appendTo != "" {
+		added, err := appendCSV(*appendTo, reports, opts.timeFormat)
+		if err != nil {
+			fatal(err)
+		}
+		log.Printf("appended %d chunks to %s", added, *appendTo)
+	}
+	if *failEmpty && events == 0 {
+		os.Exit(exitNoEvents)
+	}
+}
 
-%s`,
-		date.Format(dateLayout),
-		totalHours,
-		buf.String(),
-	)
-	fmt.Print(output)
+// signalContext is cancelled by Ctrl-C or SIGTERM, aborting the requests in
+// flight and the login's redirect server. A second Ctrl-C kills the process
+// as usual, e.g. while waiting at a prompt.
+func signalContext() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx
 }
 
 type Chunk struct {
-	*calendar.Event
-	start time.Time
-	end   time.Time
-	notes string
+	*Event
+	start   time.Time
+	end     time.Time
+	notes   string
+	project string
+	onCall  bool
+	// nonBillable is set by the billable rules or a [nb] tag in the notes
+	nonBillable bool
+	capex       bool     // capitalizable, by the capex rules
+	why         []string // how the chunk was derived, for -explain
+	rules       []string // the config rules that classified it, e.g. "capex #2"
+	links       []string // from the event's description or `chunkit link`
+	location    string   // the working location, e.g. "home"
+	tags        []string // tokens from the event's description, e.g. "JIRA-123"
+}
+
+// explain records a step in how the chunk was derived.
+func (c *Chunk) explain(format string, args ...any) {
+	// copies of a chunk share why, so never append in place
+	c.why = append(c.why[:len(c.why):len(c.why)], fmt.Sprintf(format, args...))
 }
 
-func Chunkify(date time.Time, items []*calendar.Event) []*Chunk {
+// matched records a config rule that classified the chunk.
+func (c *Chunk) matched(rule string) {
+	c.rules = append(c.rules[:len(c.rules):len(c.rules)], rule)
+}
+
+// Options tweak how Chunkify turns events into chunks.
+type Options struct {
+	// ClampWorkday cuts events down to the workday window rather than to the
+	// whole report day.
+	ClampWorkday bool
+	// KeepOvernight leaves events running past midnight whole, for when they
+	// are attributed to the day they started.
+	KeepOvernight bool
+	// Tentative is what to do with events answered "tentative" or not
+	// answered at all: tentativeInclude (the default), tentativeExclude or
+	// tentativeFlag, which prefixes their notes.
+	Tentative string
+	// AllDay are the policies for all-day events, which are skipped unless
+	// one matches.
+	AllDay []AllDayPolicy
+	// Precedence decides which of two overlapping events keeps the time,
+	// rather than the later one.
+	Precedence []PrecedenceRule
+	// Umbrella rules pick the events whose name fills the gaps they span,
+	// such as a workshop resuming between its breakout sessions.
+	Umbrella []UmbrellaRule
+	// AttendUnlisted counts the events on the calendar that don't list you
+	// as an attendee, such as organizer-only invites with a room.
+	AttendUnlisted bool
+	// Schedule is the workday window per weekday, outside which no
+	// unallocated time is reported.
+	Schedule Schedule
+}
+
+const (
+	tentativeInclude = "include"
+	tentativeExclude = "exclude"
+	tentativeFlag    = "flag"
+)
+
+func Chunkify(date time.Time, items []*Event, opts Options) []*Chunk {
 	var (
-		lo        time.Time = date.Add(startOfDay * time.Hour)
-		hi        time.Time = date.Add(endOfDay * time.Hour)
-		i         int       = 0
-		chunks    []*Chunk  = make([]*Chunk, 0, len(items)*2)
-		intersect *Chunk
+		i      int      = 0
+		chunks []*Chunk = make([]*Chunk, 0, len(items)*2)
+		spans  []span
 	)
+	lo, hi := opts.Schedule.window(date)
+
+	// events are clamped to this window so ones spanning midnight don't
+	// spill into the neighbouring days
+	clampLo, clampHi := date, date.AddDate(0, 0, 1)
+	if opts.ClampWorkday {
+		clampLo, clampHi = lo, hi
+	}
+
+	var policy, rule string
+	match, allDay := matchAllDay(items, opts.AllDay)
+	if allDay != nil {
+		policy, rule = opts.AllDay[match].Policy, fmt.Sprintf("allDay #%d", match+1)
+	}
+	switch policy {
+	case allDayZero:
+		return chunks
+	case allDayFill:
+		if !lo.Before(hi) {
+			return chunks
+		}
+		chunks = append(chunks, &Chunk{Event: allDay, start: lo, end: hi, notes: allDay.Summary})
+		chunks[0].explain("all-day event %q fills the workday", allDay.Summary)
+		chunks[0].matched(rule)
+		return chunks
+	}
 
 	if len(items) == 0 {
-		chunks = append(chunks, &Chunk{start: lo, end: hi, notes: ""})
+		if lo.Before(hi) {
+			chunks = append(chunks, &Chunk{start: lo, end: hi, notes: ""})
+			chunks[0].explain("no events")
+		}
 		return chunks
 	}
 
 	for _, e := range items {
 		// exclude all-day events
-		if e.Start.DateTime == "" || e.End.DateTime == "" {
+		if e.AllDay {
+			continue
+		}
+
+		// exclude cancelled instances of recurring events, which ShowDeleted
+		// does not hide
+		if e.Status == "cancelled" {
+			slog.Debug("skipped cancelled event", "summary", e.Summary)
+			continue
+		}
+
+		// working locations say where you were, not what you did
+		if e.Type == eventWorkingLocation {
+			continue
+		}
+
+		// time out of office is neither worked nor unallocated
+		if e.Type == eventOutOfOffice {
+			slog.Debug("skipped out of office time", "summary", e.Summary)
+			start := roundToNearest15(e.Start).In(date.Location())
+			end := roundToNearest15(e.End).In(date.Location())
+			if start.After(lo) && lo.Before(hi) {
+				gapEnd := start
+				if gapEnd.After(hi) {
+					gapEnd = hi
+				}
+				chunks = append(chunks, gapChunk(lo, gapEnd))
+			}
+			if end.After(lo) {
+				lo = end
+			}
 			continue
 		}
 
 		// include event if you created it and are not an attendee
-		if len(e.Attendees) == 0 && e.Creator.Self {
-			e.Attendees = append(e.Attendees, &calendar.EventAttendee{
+		createdAlone := len(e.Attendees) == 0 && e.Creator.Self
+		if createdAlone {
+			e.Attendees = append(e.Attendees, &Attendee{
 				Self: true,
 			})
 		}
 
+		// or, with AttendUnlisted, if it is on the calendar at all; focus
+		// time always is work
+		unlisted := !createdAlone && (opts.AttendUnlisted || e.Type == eventFocusTime) && selfAttendee(e) == nil
+		if unlisted {
+			e.Attendees = append(e.Attendees, &Attendee{
+				Self:     true,
+				Response: "accepted",
+			})
+		}
+
 		for _, attendee := range e.Attendees {
 			// exclude events you are not an attendee or declined
-			if !attendee.Self || attendee.ResponseStatus == "declined" {
+			if !attendee.Self {
+				continue
+			}
+			if attendee.Response == "declined" {
+				slog.Debug("skipped declined event", "summary", e.Summary)
+				continue
+			}
+
+			// meetings never accepted may not have been attended
+			unconfirmed := attendee.Response == "tentative" || attendee.Response == "needsAction"
+			if unconfirmed && opts.Tentative == tentativeExclude {
+				slog.Debug("skipped unconfirmed event", "summary", e.Summary, "response", attendee.Response)
 				continue
 			}
+			notes := e.Summary
+			if unconfirmed && opts.Tentative == tentativeFlag {
+				notes = "(tentative) " + notes
+			}
+			chunk := &Chunk{Event: e, notes: notes}
+			switch {
+			case createdAlone:
+				chunk.explain("created by you without attendees")
+			case unlisted:
+				chunk.explain("on the calendar without you as an attendee")
+			default:
+				chunk.explain("you answered %s", attendee.Response)
+			}
+			if e.Type == eventFocusTime {
+				chunk.explain("focus time")
+			}
+			if len(e.Mirrors) > 0 {
+				chunk.explain("from calendar %s, also on %s", e.Calendar, strings.Join(e.Mirrors, ", "))
+			}
 
-			start := roundToNearest15(e.Start)
-			end := roundToNearest15(e.End)
+			// report in the date's timezone rather than the event's
+			start := roundToNearest15(e.Start).In(date.Location())
+			end := roundToNearest15(e.End).In(date.Location())
+			rawStart, rawEnd := e.Start.In(date.Location()), e.End.In(date.Location())
+			chunk.explain("event %s-%s", rawStart.Format("15:04"), rawEnd.Format("15:04"))
+			if !rawStart.Equal(start) || !rawEnd.Equal(end) {
+				slog.Debug("rounded event", "summary", e.Summary, "start", rawStart, "end", rawEnd, "roundedStart", start, "roundedEnd", end)
+				chunk.explain("rounded to %s-%s", start.Format("15:04"), end.Format("15:04"))
+			}
 
-			// include gap chunk if event starts after start of day
-			if start.After(lo) {
-				chunks = append(chunks, &Chunk{start: lo, end: start, notes: ""})
-				if intersect != nil {
-					chunks[len(chunks)-1].notes = intersect.notes
+			if start.Before(clampLo) {
+				start = clampLo
+				chunk.explain("clamped to start at %s", start.Format("15:04"))
+			}
+			if end.After(clampHi) && (opts.ClampWorkday || !opts.KeepOvernight) {
+				end = clampHi
+				chunk.explain("clamped to end at %s", end.Format("15:04"))
+			}
+			if !end.After(start) {
+				continue
+			}
+			spans = append(spans, span{event: e, notes: notes, start: start, end: end, rule: -1})
+
+			// an earlier event that outranks this one keeps the overlap
+			if n := len(chunks); n > 0 && chunks[n-1].Event != nil && start.Before(chunks[n-1].end) &&
+				rank(chunks[n-1].Event, opts.Precedence) > rank(e, opts.Precedence) {
+				slog.Debug("overlap kept by higher ranked event", "summary", e.Summary, "over", chunks[n-1].notes)
+				start = chunks[n-1].end
+				if !end.After(start) {
+					continue
 				}
+				chunk.explain("starts at %s after %q which outranks it", start.Format("15:04"), chunks[n-1].notes)
+			}
+			chunk.start, chunk.end = start, end
+
+			// include gap chunk if event starts after start of day, but
+			// don't invent work past the end of it
+			if start.After(lo) && lo.Before(hi) {
+				gapEnd := start
+				if gapEnd.After(hi) {
+					gapEnd = hi
+				}
+				chunks = append(chunks, gapChunk(lo, gapEnd))
 			}
 
 			// include current event chunk and keep track of index
-			chunks = append(chunks, &Chunk{Event: e, start: start, end: end, notes: e.Summary})
+			chunks = append(chunks, chunk)
 			i = len(chunks) - 1
 
 			// modify previous chunk if current event intersects
-			if i > 0 && start.Before(chunks[i-1].end) {
-				intersect = chunks[i-1]
+			truncated := i > 0 && start.Before(chunks[i-1].end)
+			if truncated {
+				slog.Debug("overlap truncated earlier chunk", "summary", e.Summary, "truncated", chunks[i-1].notes, "at", start)
 				chunks[i-1].end = start
+				chunks[i-1].explain("cut short at %s by %q", start.Format("15:04"), notes)
 			}
 
-			lo = chunks[i].end
+			// an event ending before the workday starts leaves it untouched
+			if truncated || end.After(lo) {
+				lo = end
+			}
 		}
 	}
 
 	// if last event ends before end of day, add a gap chunk
 	if lo.Before(hi) {
-		chunks = append(chunks, &Chunk{start: lo, end: hi, notes: ""})
-		if intersect != nil {
-			chunks[len(chunks)-1].notes = intersect.notes
+		chunks = append(chunks, gapChunk(lo, hi))
+	}
+	chunks = fillUmbrellas(chunks, umbrellas(spans, opts.Umbrella))
+
+	// name the unallocated time after the all-day event
+	if policy == allDayAnnotate {
+		for _, chunk := range chunks {
+			if chunk.Event == nil && chunk.notes == "" {
+				chunk.notes = allDay.Summary
+				chunk.explain("named after all-day event %q", allDay.Summary)
+				chunk.matched(rule)
+			}
 		}
 	}
 
 	return chunks
 }
 
-func roundToNearest15(dt *calendar.EventDateTime) time.Time {
-	t, _ := time.Parse(time.RFC3339, dt.DateTime)
-	// 7.5 minutes rounds up to 15 minutes, 7.49 minutes rounds down to 0 minutes
-	return t.Round(15 * time.Minute)
+// gapChunk is the time between events.
+func gapChunk(start, end time.Time) *Chunk {
+	chunk := &Chunk{start: start, end: end}
+	chunk.explain("gap between events")
+	return chunk
 }
 
-func formatTime(t time.Time) string {
-	// valid hours 00-23
-	// valid minutes 00, 25, 50, 75
-	// valid time 00:00, 00:15, 00:30, 00:45, 01:00, 01:15, ..., 23:45
-	return fmt.Sprintf("%s.%02d", t.Format("15"), int(math.Round(float64(t.Minute())/60*100)))
+// matchAllDay returns the first all-day event matching a policy, and the
+// index of the policy. Policies are tried in order, so put "zero" ones first.
+func matchAllDay(items []*Event, policies []AllDayPolicy) (int, *Event) {
+	for i, p := range policies {
+		for _, e := range items {
+			if !e.AllDay {
+				continue
+			}
+			if strings.Contains(strings.ToLower(e.Summary), strings.ToLower(p.Keyword)) {
+				return i, e
+			}
+		}
+	}
+	return -1, nil
 }
 
-func authenticateClient(ctx context.Context) (*http.Client, error) {
-	bytes, err := os.ReadFile("credentials.json")
-	if err != nil {
-		return nil, fmt.Errorf("error reading the credentials file: %v", err)
+// selfAttendee is your entry in the event's attendees, nil if you aren't
+// listed.
+func selfAttendee(e *Event) *Attendee {
+	for _, attendee := range e.Attendees {
+		if attendee.Self {
+			return attendee
+		}
 	}
+	return nil
+}
 
-	config, err := google.ConfigFromJSON(bytes, "https://www.googleapis.com/auth/calendar.events.readonly")
-	if err != nil {
-		return nil, fmt.Errorf("error creating the OAuth2 config: %v", err)
+// rank sums the Rank of the precedence rules matching the event.
+func rank(e *Event, rules []PrecedenceRule) int {
+	role := ""
+	for _, attendee := range e.Attendees {
+		if attendee.Self {
+			role = roleRequired
+			if attendee.Optional {
+				role = roleOptional
+			}
+		}
 	}
-
-	tokFile, err := os.OpenFile("token.json", os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("error opening the token file: %v", err)
+	if e.Organizer.Self {
+		role = roleOrganizer
 	}
-	defer tokFile.Close()
 
-	tok := &oauth2.Token{}
-	json.NewDecoder(tokFile).Decode(tok)
+	total := 0
+	for _, rule := range rules {
+		if rule.Role != "" && rule.Role != role {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(e.Summary), strings.ToLower(rule.Keyword)) {
+			continue
+		}
+		total += rule.Rank
+	}
+	return total
+}
 
-	if tok.Valid() {
-		return config.Client(ctx, tok), nil
+// filterSummaries drops the timed events whose summary matches exclude, or
+// does not match includeOnly; either may be nil. All-day events are left to
+// their policies.
+func filterSummaries(items []*Event, exclude, includeOnly *regexp.Regexp) []*Event {
+	filtered := make([]*Event, 0, len(items))
+	for _, e := range items {
+		if !e.AllDay {
+			if exclude != nil && exclude.MatchString(e.Summary) {
+				slog.Debug("filtered out event", "summary", e.Summary, "rule", "-exclude")
+				continue
+			}
+			if includeOnly != nil && !includeOnly.MatchString(e.Summary) {
+				slog.Debug("filtered out event", "summary", e.Summary, "rule", "-include-only")
+				continue
+			}
+		}
+		filtered = append(filtered, e)
 	}
+	return filtered
+}
 
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Authenticate at this URL:\n\n%s\n", authURL)
+// startedOn drops timed events that started before date, so an event crossing
+// midnight is counted in full on the day it started and not again on the next.
+func startedOn(date time.Time, items []*Event) []*Event {
+	filtered := make([]*Event, 0, len(items))
+	for _, e := range items {
+		if !e.AllDay && e.Start.Before(date) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
 
-	ch := make(chan string, 1)
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		ch <- r.URL.Query().Get("code")
-		w.Write([]byte("You can now close this window."))
-	})
+func roundToNearest15(t time.Time) time.Time {
+	// 7.5 minutes rounds up to 15 minutes, 7.49 minutes rounds down to 0 minutes
+	return t.Round(15 * time.Minute)
+}
 
-	go http.ListenAndServe(":"+strings.Split(config.RedirectURL, ":")[2], nil)
+const (
+	timeDecimal  = "decimal"
+	timeClock    = "hh:mm"
+	timeISO      = "iso8601"
+	timeDuration = "duration"
+)
 
-	tok, _ = config.Exchange(ctx, <-ch)
+// formatTimeAs formats t in a -time-format, counting durations from midnight
+// of date.
+func formatTimeAs(layout string, date, t time.Time) string {
+	switch layout {
+	case timeClock:
+		return t.Format("15:04")
+	case timeISO:
+		return t.Format(time.RFC3339)
+	case timeDuration:
+		return formatSpent(t.Sub(date))
+	default:
+		return formatTime(t)
+	}
+}
 
-	// save the token for future use
-	tokFile.Seek(0, 0)
-	tokFile.Truncate(0)
-	json.NewEncoder(tokFile).Encode(tok)
+// formatHours formats a total in a -time-format.
+func formatHours(layout string, d time.Duration) string {
+	switch layout {
+	case timeClock:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	case timeISO:
+		return formatISODuration(d)
+	case timeDuration:
+		return formatSpent(d)
+	default:
+		return fmt.Sprintf("%.2f hours", d.Hours())
+	}
+}
 
-	return config.Client(ctx, tok), nil
+func formatTime(t time.Time) string {
+	// valid hours 00-23
+	// valid minutes 00, 25, 50, 75
+	// valid time 00:00, 00:15, 00:30, 00:45, 01:00, 01:15, ..., 23:45
+	return fmt.Sprintf("%s.%02d", t.Format("15"), int(math.Round(float64(t.Minute())/60*100)))
 }
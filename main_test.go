@@ -1,10 +1,13 @@
 package main
 
 import (
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
-
-	"google.golang.org/api/calendar/v3"
 )
 
 func Test_Chunkify(t *testing.T) {
@@ -18,39 +21,39 @@ func Test_Chunkify(t *testing.T) {
 
 	tests := []struct {
 		name          string
-		items         []*calendar.Event
+		items         []*Event
 		expectedNotes []string
 	}{
 		{
 			name:          "with no calendar events",
-			items:         []*calendar.Event{},
+			items:         []*Event{},
 			expectedNotes: []string{""},
 		},
 		{
 			name:          "skips declined events",
-			items:         []*calendar.Event{declinedEvent},
+			items:         []*Event{declinedEvent},
 			expectedNotes: []string{""},
 		},
 		{
 			name:          "includes accepted events",
-			items:         []*calendar.Event{acceptedEvent},
+			items:         []*Event{acceptedEvent},
 			expectedNotes: []string{"", "accepted event", ""},
 		},
 		{
 			name:          "creates gap between events",
-			items:         []*calendar.Event{acceptedEvent, gapEvent},
+			items:         []*Event{acceptedEvent, gapEvent},
 			expectedNotes: []string{"", "accepted event", "", "gap event", ""},
 		},
 		{
 			name:          "handles overlapping event",
-			items:         []*calendar.Event{overlapEvent, acceptedEvent, gapEvent},
-			expectedNotes: []string{"overlapping event", "accepted event", "overlapping event", "gap event", "overlapping event"},
+			items:         []*Event{overlapEvent, acceptedEvent, gapEvent},
+			expectedNotes: []string{"overlapping event", "accepted event", "", "gap event", ""},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			chunks := Chunkify(date, test.items)
+			chunks := Chunkify(date, test.items, Options{})
 
 			// check that number of chunks are as expected
 			if len(chunks) != len(test.expectedNotes) {
@@ -72,6 +75,422 @@ func Test_Chunkify(t *testing.T) {
 	}
 }
 
+func Test_Chunkify_tentative(t *testing.T) {
+	date := time.Now()
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	acceptedEvent := newEvent(date.Add(10*time.Hour), date.Add(11*time.Hour), "accepted event", "accepted", true)
+	tentativeEvent := newEvent(date.Add(12*time.Hour), date.Add(13*time.Hour), "tentative event", "tentative", true)
+	unansweredEvent := newEvent(date.Add(14*time.Hour), date.Add(15*time.Hour), "unanswered event", "needsAction", true)
+	items := []*Event{acceptedEvent, tentativeEvent, unansweredEvent}
+
+	tests := []struct {
+		tentative     string
+		expectedNotes []string
+	}{
+		{
+			tentative:     tentativeInclude,
+			expectedNotes: []string{"", "accepted event", "", "tentative event", "", "unanswered event", ""},
+		},
+		{
+			tentative:     tentativeExclude,
+			expectedNotes: []string{"", "accepted event", ""},
+		},
+		{
+			tentative:     tentativeFlag,
+			expectedNotes: []string{"", "accepted event", "", "(tentative) tentative event", "", "(tentative) unanswered event", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tentative, func(t *testing.T) {
+			chunks := Chunkify(date, items, Options{Tentative: test.tentative})
+
+			if len(chunks) != len(test.expectedNotes) {
+				t.Fatalf("expected %d chunks, got %d", len(test.expectedNotes), len(chunks))
+			}
+			for i, chunk := range chunks {
+				if chunk.notes != test.expectedNotes[i] {
+					t.Errorf("expected chunk notes to be '%s', got '%s'", test.expectedNotes[i], chunk.notes)
+				}
+			}
+		})
+	}
+}
+
+func Test_Chunkify_precedence(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	oneOnOne := newEvent(date.Add(10*time.Hour), date.Add(11*time.Hour+30*time.Minute), "1:1 with Sam", "accepted", true)
+	allHands := newEvent(date.Add(11*time.Hour), date.Add(12*time.Hour), "All hands", "accepted", true)
+	organized := newEvent(date.Add(14*time.Hour), date.Add(16*time.Hour), "Planning", "accepted", true)
+	organized.Organizer = Person{Self: true}
+	optional := newEvent(date.Add(15*time.Hour), date.Add(15*time.Hour+30*time.Minute), "Demo", "accepted", true)
+	optional.Attendees[0].Optional = true
+	items := []*Event{oneOnOne, allHands, organized, optional}
+
+	tests := []struct {
+		name          string
+		precedence    []PrecedenceRule
+		expectedNotes []string
+	}{
+		{
+			name:          "later event wins without rules",
+			expectedNotes: []string{"", "1:1 with Sam", "All hands", "", "Planning", "Demo", ""},
+		},
+		{
+			name: "higher rank wins",
+			precedence: []PrecedenceRule{
+				{Keyword: "1:1", Rank: 1},
+				{Keyword: "all hands", Rank: -1},
+				{Role: roleOrganizer, Rank: 1},
+				{Role: roleOptional, Rank: -1},
+			},
+			expectedNotes: []string{"", "1:1 with Sam", "All hands", "", "Planning", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := Chunkify(date, items, Options{Precedence: test.precedence})
+
+			if len(chunks) != len(test.expectedNotes) {
+				t.Fatalf("expected %d chunks, got %d", len(test.expectedNotes), len(chunks))
+			}
+			for i, chunk := range chunks {
+				if chunk.notes != test.expectedNotes[i] {
+					t.Errorf("expected chunk notes to be '%s', got '%s'", test.expectedNotes[i], chunk.notes)
+				}
+			}
+		})
+	}
+}
+
+func Test_Chunkify_recurring(t *testing.T) {
+	date := time.Now()
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	// instances of a daily series as returned with SingleEvents(true)
+	instance := func(start, end time.Duration, summary, status string) *Event {
+		e := newEvent(date.Add(start), date.Add(end), summary, "accepted", true)
+		e.Recurring = true
+		e.Status = status
+		return e
+	}
+
+	tests := []struct {
+		name          string
+		items         []*Event
+		expectedNotes []string
+	}{
+		{
+			name:          "includes a confirmed instance",
+			items:         []*Event{instance(10*time.Hour, 11*time.Hour, "standup", "confirmed")},
+			expectedNotes: []string{"", "standup", ""},
+		},
+		{
+			name:          "skips a cancelled instance",
+			items:         []*Event{instance(10*time.Hour, 11*time.Hour, "standup", "cancelled")},
+			expectedNotes: []string{""},
+		},
+		{
+			name: "uses the times of a modified instance",
+			items: []*Event{
+				instance(14*time.Hour, 15*time.Hour, "standup (moved)", "confirmed"),
+				instance(10*time.Hour, 11*time.Hour, "standup", "cancelled"),
+			},
+			expectedNotes: []string{"", "standup (moved)", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := Chunkify(date, test.items, Options{})
+
+			if len(chunks) != len(test.expectedNotes) {
+				t.Fatalf("expected %d chunks, got %d", len(test.expectedNotes), len(chunks))
+			}
+			for i, chunk := range chunks {
+				if chunk.notes != test.expectedNotes[i] {
+					t.Errorf("expected chunk notes to be '%s', got '%s'", test.expectedNotes[i], chunk.notes)
+				}
+				if i > 0 && chunk.start != chunks[i-1].end {
+					t.Errorf("expected chunk %d to start at %s, got %s", i, chunks[i-1].end, chunk.start)
+				}
+			}
+		})
+	}
+}
+
+func Test_Chunkify_attendUnlisted(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	withRoom := newEvent(date.Add(10*time.Hour), date.Add(11*time.Hour), "Planning", "accepted", false)
+	withRoom.Attendees[0].Resource = true
+	withRoom.Creator = Person{}
+	shared := newEvent(date.Add(15*time.Hour), date.Add(16*time.Hour), "Board prep", "", false)
+	shared.Attendees = nil
+	shared.Creator = Person{}
+	declined := newEvent(date.Add(13*time.Hour), date.Add(14*time.Hour), "Offsite", "declined", true)
+
+	tests := []struct {
+		name           string
+		attendUnlisted bool
+		expectedNotes  []string
+	}{
+		{
+			name:          "skips events not listing you",
+			expectedNotes: []string{""},
+		},
+		{
+			name:           "counts events not listing you unless declined",
+			attendUnlisted: true,
+			expectedNotes:  []string{"", "Planning", "", "Board prep", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := Chunkify(date, []*Event{withRoom, declined, shared}, Options{AttendUnlisted: test.attendUnlisted})
+
+			if len(chunks) != len(test.expectedNotes) {
+				t.Fatalf("expected %d chunks, got %d", len(test.expectedNotes), len(chunks))
+			}
+			for i, chunk := range chunks {
+				if chunk.notes != test.expectedNotes[i] {
+					t.Errorf("expected chunk notes to be '%s', got '%s'", test.expectedNotes[i], chunk.notes)
+				}
+			}
+		})
+	}
+}
+
+func Test_filterSummaries(t *testing.T) {
+	date := time.Now()
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	focusEvent := newEvent(date.Add(9*time.Hour), date.Add(11*time.Hour), "Focus time", "accepted", true)
+	lunchEvent := newEvent(date.Add(12*time.Hour), date.Add(13*time.Hour), "Lunch", "accepted", true)
+	billableEvent := newEvent(date.Add(14*time.Hour), date.Add(15*time.Hour), "ACME sync [billable]", "accepted", true)
+	offsiteEvent := newAllDayEvent(date, "Offsite")
+	items := []*Event{focusEvent, lunchEvent, billableEvent, offsiteEvent}
+
+	tests := []struct {
+		name        string
+		exclude     *regexp.Regexp
+		includeOnly *regexp.Regexp
+		expected    []*Event
+	}{
+		{
+			name:     "without filters",
+			expected: items,
+		},
+		{
+			name:     "excludes matching summaries",
+			exclude:  regexp.MustCompile("Focus time|Lunch"),
+			expected: []*Event{billableEvent, offsiteEvent},
+		},
+		{
+			name:        "includes only matching summaries",
+			includeOnly: regexp.MustCompile(`\[billable\]`),
+			expected:    []*Event{billableEvent, offsiteEvent},
+		},
+		{
+			name:        "applies both filters",
+			exclude:     regexp.MustCompile("ACME"),
+			includeOnly: regexp.MustCompile(`\[billable\]`),
+			expected:    []*Event{offsiteEvent},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filtered := filterSummaries(items, test.exclude, test.includeOnly)
+
+			if len(filtered) != len(test.expected) {
+				t.Fatalf("expected %d events, got %d", len(test.expected), len(filtered))
+			}
+			for i, e := range filtered {
+				if e != test.expected[i] {
+					t.Errorf("expected event '%s', got '%s'", test.expected[i].Summary, e.Summary)
+				}
+			}
+		})
+	}
+}
+
+func Test_startedOn(t *testing.T) {
+	date := time.Now()
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	lateEvent := newEvent(date.Add(22*time.Hour), date.Add(26*time.Hour), "late event", "accepted", true)
+	carriedEvent := newEvent(date.Add(-2*time.Hour), date.Add(2*time.Hour), "carried event", "accepted", true)
+
+	items := startedOn(date, []*Event{carriedEvent, lateEvent})
+	if len(items) != 1 || items[0] != lateEvent {
+		t.Fatalf("expected only the event starting on the date, got %d events", len(items))
+	}
+
+	chunks := Chunkify(date, items, Options{KeepOvernight: true})
+	last := chunks[len(chunks)-1]
+	if !last.end.Equal(date.Add(26 * time.Hour)) {
+		t.Errorf("expected late event to end at %s, got %s", date.Add(26*time.Hour), last.end)
+	}
+}
+
+func Test_Chunkify_timezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, berlin)
+
+	// 10:00-11:00 in Berlin, as returned by a calendar in New York
+	newYork := time.FixedZone("EDT", -4*60*60)
+	event := newEvent(date.Add(10*time.Hour).In(newYork), date.Add(11*time.Hour).In(newYork), "client call", "accepted", true)
+
+	chunks := Chunkify(date, []*Event{event}, Options{})
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	if formatTime(chunks[1].start) != "10.00" || formatTime(chunks[1].end) != "11.00" {
+		t.Errorf("expected event chunk to be 10.00-11.00, got %s-%s", formatTime(chunks[1].start), formatTime(chunks[1].end))
+	}
+}
+
+func Test_Chunkify_clamp(t *testing.T) {
+	date := time.Now()
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	carriedEvent := newEvent(date.Add(-2*time.Hour), date.Add(1*time.Hour), "carried event", "accepted", true)
+	lateEvent := newEvent(date.Add(16*time.Hour), date.Add(26*time.Hour), "late event", "accepted", true)
+	eveningEvent := newEvent(date.Add(20*time.Hour), date.Add(21*time.Hour), "evening event", "accepted", true)
+
+	type span struct {
+		start time.Duration
+		end   time.Duration
+		notes string
+	}
+
+	tests := []struct {
+		name     string
+		items    []*Event
+		opts     Options
+		expected []span
+	}{
+		{
+			name:  "clamps events to the day",
+			items: []*Event{carriedEvent, lateEvent},
+			expected: []span{
+				{0, 1 * time.Hour, "carried event"},
+				{9 * time.Hour, 16 * time.Hour, ""},
+				{16 * time.Hour, 24 * time.Hour, "late event"},
+			},
+		},
+		{
+			name:  "keeps overnight events whole",
+			items: []*Event{lateEvent},
+			opts:  Options{KeepOvernight: true},
+			expected: []span{
+				{9 * time.Hour, 16 * time.Hour, ""},
+				{16 * time.Hour, 26 * time.Hour, "late event"},
+			},
+		},
+		{
+			name:  "clamps events to the workday",
+			items: []*Event{carriedEvent, lateEvent, eveningEvent},
+			opts:  Options{ClampWorkday: true, KeepOvernight: true},
+			expected: []span{
+				{9 * time.Hour, 16 * time.Hour, ""},
+				{16 * time.Hour, 17 * time.Hour, "late event"},
+			},
+		},
+		{
+			name:  "does not fill gaps past the workday",
+			items: []*Event{eveningEvent},
+			expected: []span{
+				{9 * time.Hour, 17 * time.Hour, ""},
+				{20 * time.Hour, 21 * time.Hour, "evening event"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := Chunkify(date, test.items, test.opts)
+
+			if len(chunks) != len(test.expected) {
+				t.Fatalf("expected %d chunks, got %d", len(test.expected), len(chunks))
+			}
+			for i, chunk := range chunks {
+				expected := test.expected[i]
+				if !chunk.start.Equal(date.Add(expected.start)) || !chunk.end.Equal(date.Add(expected.end)) || chunk.notes != expected.notes {
+					t.Errorf("expected chunk %d to be %s-%s '%s', got %s-%s '%s'", i,
+						date.Add(expected.start), date.Add(expected.end), expected.notes, chunk.start, chunk.end, chunk.notes)
+				}
+			}
+		})
+	}
+}
+
+func Test_Chunkify_allDay(t *testing.T) {
+	date := time.Now()
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	acceptedEvent := newEvent(date.Add(10*time.Hour), date.Add(12*time.Hour), "accepted event", "accepted", true)
+	policies := []AllDayPolicy{
+		{Keyword: "pto", Policy: allDayZero},
+		{Keyword: "conference", Policy: allDayFill},
+		{Keyword: "offsite", Policy: allDayAnnotate},
+	}
+
+	tests := []struct {
+		name          string
+		items         []*Event
+		expectedNotes []string
+	}{
+		{
+			name:          "skips all-day events without a policy",
+			items:         []*Event{newAllDayEvent(date, "Birthday"), acceptedEvent},
+			expectedNotes: []string{"", "accepted event", ""},
+		},
+		{
+			name:          "zeroes the day",
+			items:         []*Event{newAllDayEvent(date, "PTO"), acceptedEvent},
+			expectedNotes: []string{},
+		},
+		{
+			name:          "fills the workday",
+			items:         []*Event{newAllDayEvent(date, "GopherCon conference"), acceptedEvent},
+			expectedNotes: []string{"GopherCon conference"},
+		},
+		{
+			name:          "annotates gaps",
+			items:         []*Event{newAllDayEvent(date, "Team offsite"), acceptedEvent},
+			expectedNotes: []string{"Team offsite", "accepted event", "Team offsite"},
+		},
+		{
+			name:          "applies the first matching policy",
+			items:         []*Event{newAllDayEvent(date, "Team offsite"), newAllDayEvent(date, "PTO")},
+			expectedNotes: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := Chunkify(date, test.items, Options{AllDay: policies})
+
+			if len(chunks) != len(test.expectedNotes) {
+				t.Fatalf("expected %d chunks, got %d", len(test.expectedNotes), len(chunks))
+			}
+			for i, chunk := range chunks {
+				if chunk.notes != test.expectedNotes[i] {
+					t.Errorf("expected chunk notes to be '%s', got '%s'", test.expectedNotes[i], chunk.notes)
+				}
+			}
+		})
+	}
+}
+
 func Benchmark_Chunkify(b *testing.B) {
 	date := time.Now()
 	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
@@ -80,21 +499,119 @@ func Benchmark_Chunkify(b *testing.B) {
 	acceptedEvent := newEvent(date.Add(10*time.Hour), date.Add(12*time.Hour), "accepted event", "accepted", true)
 	gapEvent := newEvent(date.Add(13*time.Hour), date.Add(14*time.Hour), "gap event", "accepted", true)
 	overlapEvent := newEvent(date.Add(8*time.Hour), date.Add(17*time.Hour), "overlapping event", "accepted", true)
-	items := []*calendar.Event{overlapEvent, acceptedEvent, gapEvent, declinedEvent}
+	items := []*Event{overlapEvent, acceptedEvent, gapEvent, declinedEvent}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		Chunkify(date, items)
+		Chunkify(date, items, Options{})
 	}
 }
 
-func newEvent(start time.Time, end time.Time, summary string, responseStatus string, self bool) *calendar.Event {
-	return &calendar.Event{
+func newEvent(start time.Time, end time.Time, summary string, responseStatus string, self bool) *Event {
+	return &Event{
 		Summary: summary,
-		Start:   &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
-		End:     &calendar.EventDateTime{DateTime: end.Format(time.RFC3339)},
-		Attendees: []*calendar.EventAttendee{
-			{Self: self, ResponseStatus: responseStatus},
+		Start:   start,
+		End:     end,
+		Attendees: []*Attendee{
+			{Self: self, Response: responseStatus},
 		},
 	}
 }
+
+func newAllDayEvent(date time.Time, summary string) *Event {
+	return &Event{
+		Summary: summary,
+		Start:   date,
+		End:     date.AddDate(0, 0, 1),
+		AllDay:  true,
+	}
+}
+
+func Test_formatTimeAs(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	at := date.Add(15*time.Hour + 30*time.Minute)
+
+	tests := []struct {
+		layout        string
+		expectedTime  string
+		expectedHours string
+	}{
+		{layout: timeDecimal, expectedTime: "15.50", expectedHours: "7.50 hours"},
+		{layout: timeClock, expectedTime: "15:30", expectedHours: "7:30"},
+		{layout: timeISO, expectedTime: "2024-03-11T15:30:00Z", expectedHours: "PT7H30M"},
+		{layout: timeDuration, expectedTime: "15h30m", expectedHours: "7h30m"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.layout, func(t *testing.T) {
+			if got := formatTimeAs(test.layout, date, at); got != test.expectedTime {
+				t.Errorf("expected time '%s', got '%s'", test.expectedTime, got)
+			}
+			if got := formatHours(test.layout, 7*time.Hour+30*time.Minute); got != test.expectedHours {
+				t.Errorf("expected hours '%s', got '%s'", test.expectedHours, got)
+			}
+		})
+	}
+}
+
+func Test_Chunkify_debugLog(t *testing.T) {
+	buf := strings.Builder{}
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	items := []*Event{
+		newEvent(date.Add(10*time.Hour+5*time.Minute), date.Add(11*time.Hour), "Design review", "accepted", true),
+		newEvent(date.Add(10*time.Hour+30*time.Minute), date.Add(12*time.Hour), "Incident call", "accepted", true),
+		newEvent(date.Add(13*time.Hour), date.Add(14*time.Hour), "Offsite", "declined", true),
+	}
+
+	Chunkify(date, items, Options{})
+
+	for _, expected := range []string{
+		`msg="rounded event" summary="Design review"`,
+		`msg="overlap truncated earlier chunk" summary="Incident call" truncated="Design review"`,
+		`msg="skipped declined event" summary=Offsite`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected the log to contain %s, got:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func Test_Chunkify_explain(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	items := []*Event{
+		newEvent(date.Add(10*time.Hour+5*time.Minute), date.Add(11*time.Hour), "Design review", "accepted", true),
+		newEvent(date.Add(10*time.Hour+30*time.Minute), date.Add(12*time.Hour), "Incident call", "tentative", true),
+	}
+
+	chunks := Chunkify(date, items, Options{})
+
+	expected := [][]string{
+		{"gap between events"},
+		{"you answered accepted", "event 10:05-11:00", "rounded to 10:00-11:00", `cut short at 10:30 by "Incident call"`},
+		{"you answered tentative", "event 10:30-12:00"},
+		{"gap between events"},
+	}
+	if len(chunks) != len(expected) {
+		t.Fatalf("expected %d chunks, got %d", len(expected), len(chunks))
+	}
+	for i, chunk := range chunks {
+		if strings.Join(chunk.why, "|") != strings.Join(expected[i], "|") {
+			t.Errorf("expected chunk %d to be explained as %q, got %q", i, expected[i], chunk.why)
+		}
+	}
+}
+
+func Test_signalContext(t *testing.T) {
+	ctx := signalContext()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Errorf("expected SIGTERM to cancel the context")
+	}
+}
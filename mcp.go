@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// mcpProtocolVersion is the Model Context Protocol revision spoken by `mcp`.
+const mcpProtocolVersion = "2024-11-05"
+
+// runMCP implements the `mcp` subcommand, a Model Context Protocol server on
+// standard input and output that lets LLM assistants read the chunks and
+// annotate gaps. The flags apply to every tool call.
+func runMCP(args []string) {
+	fs := flag.NewFlagSet("mcp", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+	if err := opts.validate(); err != nil {
+		fatal(err)
+	}
+	if _, err := opts.loadConfig(); err != nil {
+		fatal(err)
+	}
+
+	// standard output carries the protocol, so whatever else is printed,
+	// such as the login's prompts, goes to standard error
+	out := os.Stdout
+	os.Stdout = os.Stderr
+
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	server := &mcpServer{opts: opts, calendarService: calendarService}
+	if err := server.serve(ctx, os.Stdin, out); err != nil {
+		fatal(err)
+	}
+}
+
+// mcpServer answers JSON-RPC requests, one per line.
+type mcpServer struct {
+	opts            *reportOptions
+	calendarService *calendar.Service
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"` // absent for notifications
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC error codes.
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// serve answers the requests read from r on w until r ends or ctx is done.
+func (s *mcpServer) serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(w)
+	for scanner.Scan() && ctx.Err() == nil {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var req rpcRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			if err := encoder.Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		result, rpcErr := s.handle(ctx, req)
+		if req.ID == nil {
+			continue
+		}
+		if rpcErr == nil && result == nil {
+			result = struct{}{}
+		}
+		if err := encoder.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading the MCP requests: %v", err)
+	}
+	return nil
+}
+
+func (s *mcpServer) handle(ctx context.Context, req rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": "chunkit", "version": "1"},
+		}, nil
+	case "ping", "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "tools/list":
+		return map[string]any{"tools": mcpTools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		text, err := s.call(ctx, params.Name, params.Arguments)
+		if err != nil {
+			// tool failures are results, so the assistant sees them
+			return toolResult(err.Error(), true), nil
+		}
+		return toolResult(text, false), nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + req.Method}
+}
+
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+// mcpTool describes a tool to the assistant, its arguments as a JSON schema.
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+var dateArgument = map[string]any{
+	"type":        "string",
+	"description": "YYYY-MM-DD, or relative: today, yesterday, monday, -3d, this-week, last-week (default today)",
+}
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "get_day_report",
+		Description: "The chunks of a day, or of each day of a range, as CSV: start, end, notes and project of the meetings and the gaps between them.",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"date": dateArgument},
+		},
+	},
+	{
+		Name:        "get_week_summary",
+		Description: "Hours per project, meeting organizer or attendee email domain (to find the time with a client) in the Monday to Sunday week of a date, as CSV.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"date": dateArgument,
+				"by":   map[string]any{"type": "string", "enum": []string{"project", "organizer", "domain"}, "description": "What to total by (default project)"},
+			},
+		},
+	},
+	{
+		Name:        "annotate_gap",
+		Description: "Name the gap between meetings at a time, e.g. what was worked on then. The name is kept for later reports.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"date": dateArgument,
+				"time": map[string]any{"type": "string", "description": "A time within the gap as HH:MM"},
+				"text": map[string]any{"type": "string", "description": "What the gap was"},
+			},
+			"required": []string{"time", "text"},
+		},
+	},
+}
+
+// call runs the named tool, returning its text.
+func (s *mcpServer) call(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	var args struct {
+		Date string `json:"date"`
+		By   string `json:"by"`
+		Time string `json:"time"`
+		Text string `json:"text"`
+	}
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %v", err)
+		}
+	}
+
+	switch name {
+	case "get_day_report":
+		return s.dayReport(ctx, args.Date)
+	case "get_week_summary":
+		return s.weekSummary(ctx, args.Date, args.By)
+	case "annotate_gap":
+		return s.annotateGap(ctx, args.Date, args.Time, args.Text)
+	}
+	return "", fmt.Errorf("unknown tool %q", name)
+}
+
+// days builds the chunks of each day of the date, or of its week, with the
+// history's notes and annotations.
+func (s *mcpServer) days(ctx context.Context, date string, week bool) ([][]*Chunk, time.Time, error) {
+	opts := *s.opts
+	opts.date = date
+	from, days, err := opts.reportRange()
+	if err != nil {
+		return nil, from, err
+	}
+	if week {
+		from, days = startOfWeek(from), 7
+	}
+	chunks, err := opts.buildDays(ctx, s.calendarService, from, days)
+	if err != nil {
+		return nil, from, err
+	}
+	return chunks, from, nil
+}
+
+func (s *mcpServer) dayReport(ctx context.Context, date string) (string, error) {
+	days, from, err := s.days(ctx, date, false)
+	if err != nil {
+		return "", err
+	}
+	config, err := s.opts.loadConfig()
+	if err != nil {
+		return "", err
+	}
+	buf := strings.Builder{}
+	for i, chunks := range days {
+		buf.WriteString(newReport(from.AddDate(0, 0, i), chunks, s.opts.columns(config)).csv(s.opts.timeFormat))
+	}
+	return buf.String(), nil
+}
+
+func (s *mcpServer) weekSummary(ctx context.Context, date, by string) (string, error) {
+	keys := func(chunk *Chunk) []string { return []string{chunk.project} }
+	switch by {
+	case "", "project":
+		by = "project"
+	case "organizer":
+		keys = organizerOf
+	case "domain":
+		keys = domainsOf
+	default:
+		return "", fmt.Errorf("invalid by %q: must be project, organizer or domain", by)
+	}
+
+	days, from, err := s.days(ctx, date, true)
+	if err != nil {
+		return "", err
+	}
+	var chunks []*Chunk
+	for _, day := range days {
+		chunks = append(chunks, day...)
+	}
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("Week of %s.\n\n%s,hours\n", from.Format(dateLayout), by))
+	for _, group := range totalBy(chunks, keys) {
+		buf.WriteString(fmt.Sprintf("%s,%s\n", group.name, formatHours(s.opts.timeFormat, group.total)))
+	}
+	return buf.String(), nil
+}
+
+func (s *mcpServer) annotateGap(ctx context.Context, date, at, text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("text is required")
+	}
+	days, from, err := s.days(ctx, date, false)
+	if err != nil {
+		return "", err
+	}
+	if len(days) > 1 {
+		return "", fmt.Errorf("date %s is a range, annotate_gap needs a single day", date)
+	}
+	day := from.Format(dateLayout)
+	t, err := time.ParseInLocation("2006-01-02 15:04", day+" "+at, from.Location())
+	if err != nil {
+		return "", fmt.Errorf("invalid time %q: must be HH:MM", at)
+	}
+
+	var gap *Chunk
+	for _, chunk := range days[0] {
+		if chunk.Event == nil && !t.Before(chunk.start) && t.Before(chunk.end) {
+			gap = chunk
+		}
+	}
+	if gap == nil {
+		return "", fmt.Errorf("no gap at %s on %s", at, day)
+	}
+
+	err = updateStore(s.opts.history, func(s *Store) {
+		s.Annotations[day] = append(s.Annotations[day], TimedNote{At: t, Text: text})
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Annotated the gap %s-%s of %s as %q.", formatTime(gap.start), formatTime(gap.end), day, text), nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_mcpServer(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.json")
+	err := os.WriteFile(path, []byte(`[
+		{"summary": "ACME sync", "start": "2024-03-11T09:00:00Z", "end": "2024-03-11T10:00:00Z"},
+		{"summary": "Standup", "start": "2024-03-12T09:30:00Z", "end": "2024-03-12T09:45:00Z"}
+	]`), 0600)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	opts := &reportOptions{input: path, tz: "UTC", config: filepath.Join(dir, "config.json"), history: filepath.Join(dir, "history.json"), tentative: tentativeInclude, timeFormat: timeDecimal}
+	server := &mcpServer{opts: opts}
+
+	requests := []string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`,
+		`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "annotate_gap", "arguments": {"date": "2024-03-11", "time": "14:00", "text": "Code review"}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "get_day_report", "arguments": {"date": "2024-03-11"}}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "tools/call", "params": {"name": "get_week_summary", "arguments": {"date": "2024-03-13", "by": "project"}}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "tools/call", "params": {"name": "annotate_gap", "arguments": {"date": "2024-03-11", "time": "09:30", "text": "Sync"}}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "resources/list"}`,
+	}
+	out := strings.Builder{}
+	if err := server.serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	type response struct {
+		ID     int `json:"id"`
+		Result struct {
+			ProtocolVersion string    `json:"protocolVersion"`
+			Tools           []mcpTool `json:"tools"`
+			Content         []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error *rpcError `json:"error"`
+	}
+	var responses []response
+	decoder := json.NewDecoder(strings.NewReader(out.String()))
+	for decoder.More() {
+		var r response
+		if err := decoder.Decode(&r); err != nil {
+			t.Fatalf("expected JSON responses, got %v", err)
+		}
+		responses = append(responses, r)
+	}
+
+	// the notification gets no response
+	if len(responses) != 7 {
+		t.Fatalf("expected 7 responses, got %d: %s", len(responses), out.String())
+	}
+	if responses[0].Result.ProtocolVersion != mcpProtocolVersion {
+		t.Errorf("expected protocol version %s, got '%s'", mcpProtocolVersion, responses[0].Result.ProtocolVersion)
+	}
+	if len(responses[1].Result.Tools) != len(mcpTools) {
+		t.Errorf("expected %d tools, got %d", len(mcpTools), len(responses[1].Result.Tools))
+	}
+	if annotated := responses[2].Result; annotated.IsError || !strings.Contains(annotated.Content[0].Text, "10.00-") {
+		t.Errorf("expected the gap after the sync annotated, got %+v", annotated)
+	}
+	if report := responses[3].Result.Content[0].Text; !strings.Contains(report, "ACME sync") || !strings.Contains(report, "Code review") {
+		t.Errorf("expected the report with the sync and the annotated gap, got %s", report)
+	}
+	if summary := responses[4].Result.Content[0].Text; !strings.Contains(summary, "Week of 2024-03-11") || !strings.Contains(summary, "project,hours\n,40.00 hours") {
+		t.Errorf("expected the 5 workdays without a project, got %s", summary)
+	}
+	if !responses[5].Result.IsError {
+		t.Errorf("expected an error annotating a meeting")
+	}
+	if responses[6].Error == nil || responses[6].Error.Code != rpcMethodNotFound {
+		t.Errorf("expected an unknown method error, got %+v", responses[6].Error)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// mergeAdjacent joins consecutive chunks that belong together: gaps with the
+// same notes, pieces of the same event, and chunks of the same project, whose
+// distinct notes are joined with "; ".
+func mergeAdjacent(chunks []*Chunk) []*Chunk {
+	merged := make([]*Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if len(merged) > 0 {
+			prev := merged[len(merged)-1]
+			if prev.end.Equal(chunk.start) && prev.onCall == chunk.onCall && prev.location == chunk.location && belongTogether(prev, chunk) {
+				joined := *prev
+				joined.end = chunk.end
+				joined.explain("merged with the adjacent %s-%s", formatTime(chunk.start), formatTime(chunk.end))
+				if !containsNote(prev.notes, chunk.notes) {
+					joined.notes = prev.notes + "; " + chunk.notes
+				}
+				joined.links = appendLinks(prev.links, chunk.links...)
+				merged[len(merged)-1] = &joined
+				continue
+			}
+		}
+		merged = append(merged, chunk)
+	}
+	return merged
+}
+
+func belongTogether(a, b *Chunk) bool {
+	switch {
+	case a.project != "" || b.project != "":
+		return a.project == b.project
+	case a.Event == nil && b.Event == nil:
+		return a.notes == b.notes
+	case a.Event != nil && b.Event != nil:
+		return a.Event.ID != "" && a.Event.ID == b.Event.ID
+	}
+	return false
+}
+
+// containsNote reports whether note is empty or already among the "; "
+// separated notes.
+func containsNote(notes, note string) bool {
+	if note == "" {
+		return true
+	}
+	for _, n := range strings.Split(notes, "; ") {
+		if n == note {
+			return true
+		}
+	}
+	return false
+}
+
+// dropShort removes the chunks shorter than min.
+func dropShort(chunks []*Chunk, min time.Duration) []*Chunk {
+	kept := make([]*Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.end.Sub(chunk.start) >= min {
+			kept = append(kept, chunk)
+		} else {
+			slog.Debug("dropped short chunk", "notes", chunk.notes, "start", chunk.start, "end", chunk.end)
+		}
+	}
+	return kept
+}
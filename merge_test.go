@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_mergeAdjacent(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return date.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	workshop := &Event{ID: "workshop"}
+	review := &Event{ID: "review"}
+
+	tests := []struct {
+		name          string
+		chunks        []*Chunk
+		expectedNotes []string
+	}{
+		{
+			name: "merges gaps with the same notes",
+			chunks: []*Chunk{
+				{start: at(9, 0), end: at(9, 15), notes: ""},
+				{start: at(9, 15), end: at(10, 0), notes: ""},
+				{start: at(10, 0), end: at(11, 0), notes: "offsite"},
+			},
+			expectedNotes: []string{"", "offsite"},
+		},
+		{
+			name: "merges pieces of the same event",
+			chunks: []*Chunk{
+				{Event: workshop, start: at(9, 0), end: at(10, 0), notes: "workshop"},
+				{Event: workshop, start: at(10, 0), end: at(11, 0), notes: "workshop"},
+				{Event: review, start: at(11, 0), end: at(12, 0), notes: "review"},
+			},
+			expectedNotes: []string{"workshop", "review"},
+		},
+		{
+			name: "merges chunks of the same project",
+			chunks: []*Chunk{
+				{Event: workshop, start: at(9, 0), end: at(10, 0), notes: "workshop", project: "ACME"},
+				{Event: review, start: at(10, 0), end: at(11, 0), notes: "review", project: "ACME"},
+				{start: at(11, 0), end: at(12, 0), notes: ""},
+			},
+			expectedNotes: []string{"workshop; review", ""},
+		},
+		{
+			name: "keeps chunks that are not adjacent",
+			chunks: []*Chunk{
+				{start: at(9, 0), end: at(10, 0), notes: ""},
+				{start: at(11, 0), end: at(12, 0), notes: ""},
+			},
+			expectedNotes: []string{"", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged := mergeAdjacent(test.chunks)
+
+			if len(merged) != len(test.expectedNotes) {
+				t.Fatalf("expected %d chunks, got %d", len(test.expectedNotes), len(merged))
+			}
+			for i, chunk := range merged {
+				if chunk.notes != test.expectedNotes[i] {
+					t.Errorf("expected chunk notes to be '%s', got '%s'", test.expectedNotes[i], chunk.notes)
+				}
+			}
+			if !merged[0].start.Equal(test.chunks[0].start) || !merged[len(merged)-1].end.Equal(test.chunks[len(test.chunks)-1].end) {
+				t.Errorf("expected merged chunks to span %s-%s, got %s-%s",
+					test.chunks[0].start, test.chunks[len(test.chunks)-1].end, merged[0].start, merged[len(merged)-1].end)
+			}
+		})
+	}
+}
+
+func Test_dropShort(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(9 * time.Hour)},
+		{start: date.Add(9 * time.Hour), end: date.Add(9*time.Hour + 15*time.Minute)},
+		{start: date.Add(9*time.Hour + 15*time.Minute), end: date.Add(10 * time.Hour)},
+	}
+
+	if kept := dropShort(chunks, 0); len(kept) != 3 {
+		t.Errorf("expected 3 chunks without a minimum, got %d", len(kept))
+	}
+	if kept := dropShort(chunks, time.Minute); len(kept) != 2 {
+		t.Errorf("expected 2 chunks of at least a minute, got %d", len(kept))
+	}
+	if kept := dropShort(chunks, 30*time.Minute); len(kept) != 1 {
+		t.Errorf("expected 1 chunk of at least 30 minutes, got %d", len(kept))
+	}
+}
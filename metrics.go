@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// watchMetrics are what `watch -metrics-addr` exports for Prometheus.
+type watchMetrics struct {
+	mu           sync.Mutex
+	meetingHours float64 // today's, scheduled
+	freeHours    float64 // today's gaps
+	chunks       int     // generated by every refresh
+	refreshes    int
+	errors       int
+	apiErrors    int // of errors, those of Google Calendar or authentication
+	syncDuration time.Duration
+	lastSync     time.Time // of the last successful refresh
+}
+
+// observe records a refresh of the day's chunks that took took.
+func (m *watchMetrics) observe(chunks []*Chunk, took time.Duration, err error, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshes++
+	m.syncDuration = took
+	if err != nil {
+		m.errors++
+		if code := exitCode(err); code == exitAPI || code == exitAuth {
+			m.apiErrors++
+		}
+		return
+	}
+
+	m.meetingHours, m.freeHours = 0, 0
+	for _, chunk := range chunks {
+		if chunk.Event == nil {
+			m.freeHours += chunk.end.Sub(chunk.start).Hours()
+		} else {
+			m.meetingHours += chunk.end.Sub(chunk.start).Hours()
+		}
+	}
+	m.chunks += len(chunks)
+	m.lastSync = now
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *watchMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf := strings.Builder{}
+	metric := func(name, kind, help string, value float64) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	metric("chunkit_meeting_hours", "gauge", "Hours of meetings today.", m.meetingHours)
+	metric("chunkit_free_hours", "gauge", "Hours between meetings today.", m.freeHours)
+	metric("chunkit_chunks_generated_total", "counter", "Chunks generated by the refreshes.", float64(m.chunks))
+	metric("chunkit_refreshes_total", "counter", "Refreshes of the report.", float64(m.refreshes))
+	metric("chunkit_refresh_errors_total", "counter", "Refreshes that failed.", float64(m.errors))
+	metric("chunkit_api_errors_total", "counter", "Refreshes that failed calling Google Calendar or authenticating.", float64(m.apiErrors))
+	metric("chunkit_sync_duration_seconds", "gauge", "How long the last refresh took.", m.syncDuration.Seconds())
+	lastSync := 0.0
+	if !m.lastSync.IsZero() {
+		lastSync = float64(m.lastSync.Unix())
+	}
+	metric("chunkit_last_sync_timestamp_seconds", "gauge", "When the last successful refresh finished.", lastSync)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprint(w, buf.String())
+}
+
+// serveMetrics serves the metrics on addr's /metrics until ctx is done.
+func serveMetrics(ctx context.Context, addr string, m *watchMetrics) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening on -metrics-addr: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("error serving the metrics: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	log.Printf("serving metrics on http://%s/metrics", listener.Addr())
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_watchMetrics(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10*time.Hour + 30*time.Minute), Event: &Event{}},
+		{start: date.Add(10*time.Hour + 30*time.Minute), end: date.Add(17 * time.Hour)},
+	}
+
+	metrics := &watchMetrics{}
+	metrics.observe(chunks, 250*time.Millisecond, nil, date.Add(12*time.Hour))
+	metrics.observe(nil, time.Second, withExitCode(exitAPI, errors.New("error fetching the calendar events")), date.Add(13*time.Hour))
+	metrics.observe(nil, time.Second, errors.New("error writing the report"), date.Add(14*time.Hour))
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	expected := []string{
+		"# TYPE chunkit_meeting_hours gauge\nchunkit_meeting_hours 1.5\n",
+		"chunkit_free_hours 6.5\n",
+		"chunkit_chunks_generated_total 2\n",
+		"chunkit_refreshes_total 3\n",
+		"chunkit_refresh_errors_total 2\n",
+		"chunkit_api_errors_total 1\n",
+		"chunkit_sync_duration_seconds 1\n",
+		"chunkit_last_sync_timestamp_seconds 1710158400\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected the metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
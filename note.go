@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runNote implements the `note` subcommand, which records a timestamped note
+// in the local history for the day's report to attach to the chunk it falls in.
+func runNote(args []string) {
+	fs := flag.NewFlagSet("note", flag.ExitOnError)
+	historyPath := fs.String("history", filepath.Join(dataDir(), "history.json"), "Path to the local report history")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `usage: chunkit note [-history path] "what you are doing"`)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	text := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if text == "" {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	now := time.Now()
+	err := updateStore(*historyPath, func(s *Store) {
+		date := now.Format(dateLayout)
+		s.Notes[date] = append(s.Notes[date], TimedNote{At: now, Text: text})
+	})
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("Noted at %s.\n", now.Format("15:04"))
+}
+
+// attachNotes appends each note to the notes of the chunk covering its time.
+func attachNotes(chunks []*Chunk, notes []TimedNote) {
+	for _, note := range notes {
+		for _, chunk := range chunks {
+			if note.At.Before(chunk.start) || !note.At.Before(chunk.end) {
+				continue
+			}
+			if chunk.notes == "" {
+				chunk.notes = note.Text
+			} else {
+				chunk.notes += "; " + note.Text
+			}
+			chunk.explain("note taken at %s", note.At.Format("15:04"))
+			break
+		}
+	}
+}
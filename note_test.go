@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_attachNotes(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour)},
+		{start: date.Add(10 * time.Hour), end: date.Add(11 * time.Hour), notes: "Migration sync"},
+	}
+	notes := []TimedNote{
+		{At: date.Add(9*time.Hour + 20*time.Minute), Text: "wrapped up the migration script"},
+		{At: date.Add(10 * time.Hour), Text: "agreed on the cutover date"},
+		{At: date.Add(18 * time.Hour), Text: "after hours"},
+	}
+
+	attachNotes(chunks, notes)
+
+	expected := []string{"wrapped up the migration script", "Migration sync; agreed on the cutover date"}
+	for i, chunk := range chunks {
+		if chunk.notes != expected[i] {
+			t.Errorf("expected chunk notes to be '%s', got '%s'", expected[i], chunk.notes)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// odooPusher creates hr_timesheet lines (account.analytic.line records)
+// through Odoo's JSON-RPC API for the chunks matching the "odoo" rules in the
+// config, updating or deleting those of an earlier push.
+type odooPusher struct {
+	entrySync
+	baseURL  *string
+	database *string
+	user     *string
+	apiKey   string
+	uid      int
+	ids      map[string]int // resolved names, keyed by model and name
+}
+
+func newOdooPusher(fs *flag.FlagSet) pusher {
+	return &odooPusher{
+		baseURL:  fs.String("odoo-url", "", "Base URL of the Odoo instance (API key from $ODOO_API_KEY)"),
+		database: fs.String("odoo-db", "", "Odoo database name"),
+		user:     fs.String("odoo-user", "", "Odoo login"),
+		ids:      map[string]int{},
+	}
+}
+
+// matchOdooRule returns the first rule matching the notes, or nil.
+func matchOdooRule(rules []OdooRule, notes string) *OdooRule {
+	for i, rule := range rules {
+		if strings.Contains(strings.ToLower(notes), strings.ToLower(rule.Keyword)) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func (p *odooPusher) push(ctx context.Context, config *Config, date time.Time, chunks []*Chunk, dryRun bool) error {
+	if len(config.Odoo) == 0 {
+		return errors.New("no odoo rules in the config file")
+	}
+	if err := p.connect(ctx); err != nil {
+		return err
+	}
+
+	sent := 0
+	keys := chunkKeys(chunks)
+	for i, chunk := range chunks {
+		rule := matchOdooRule(config.Odoo, chunk.notes)
+		if rule == nil {
+			fmt.Printf("Skipped %s-%s %q: no matching odoo rule\n", formatTime(chunk.start), formatTime(chunk.end), chunk.notes)
+			continue
+		}
+
+		// Odoo requires a description, and uses "/" for none
+		name := chunk.notes
+		if name == "" || config.redacts("odoo", "notes") {
+			name = "/"
+		}
+		line := map[string]any{
+			"name":        name,
+			"date":        date.Format(dateLayout),
+			"unit_amount": chunk.end.Sub(chunk.start).Hours(),
+		}
+		account, err := p.resolve(ctx, "account.analytic.account", rule.Account)
+		if err != nil {
+			return partial(sent, err)
+		}
+		line["account_id"] = account
+		if rule.Project != "" {
+			project, err := p.resolve(ctx, "project.project", rule.Project)
+			if err != nil {
+				return partial(sent, err)
+			}
+			line["project_id"] = project
+		}
+
+		body, err := json.Marshal(line)
+		if err != nil {
+			return partial(sent, err)
+		}
+		entry, changed, pushed := p.lookup(keys[i], body)
+		verb := "Logged"
+		switch {
+		case pushed && !changed:
+			p.keep(keys[i], entry.ID, body)
+			continue
+		case pushed:
+			verb = "Updated"
+			if !dryRun {
+				id, err := strconv.Atoi(entry.ID)
+				if err != nil {
+					return partial(sent, fmt.Errorf("error updating timesheet line %s: %v", entry.ID, err))
+				}
+				var ok bool
+				if err := p.execute(ctx, "account.analytic.line", "write", []any{[]int{id}, line}, &ok); err != nil {
+					return partial(sent, err)
+				}
+				p.keep(keys[i], entry.ID, body)
+				sent++
+			}
+		case !dryRun:
+			var id int
+			if err := p.execute(ctx, "account.analytic.line", "create", []any{line}, &id); err != nil {
+				return partial(sent, err)
+			}
+			p.keep(keys[i], strconv.Itoa(id), body)
+			sent++
+		}
+		fmt.Printf("%s%s %s-%s to %s\n", dryRunPrefix(dryRun), verb, formatTime(chunk.start), formatTime(chunk.end), rule.Account)
+	}
+
+	// the lines of chunks since removed or no longer matching a rule
+	return p.deleteStale(dryRun, sent, "timesheet line", func(id string) error {
+		return p.deleteEntry(ctx, id)
+	})
+}
+
+// connect logs in with the API key.
+func (p *odooPusher) connect(ctx context.Context) error {
+	p.apiKey = os.Getenv("ODOO_API_KEY")
+	if p.apiKey == "" {
+		return errors.New("ODOO_API_KEY is not set")
+	}
+	if *p.baseURL == "" || *p.database == "" || *p.user == "" {
+		return errors.New("-odoo-url, -odoo-db and -odoo-user are required")
+	}
+	if err := p.call(ctx, "common", "login", []any{*p.database, *p.user, p.apiKey}, &p.uid); err != nil {
+		return err
+	}
+	if p.uid == 0 {
+		return errors.New("error logging in to Odoo: invalid login or API key")
+	}
+	return nil
+}
+
+func (p *odooPusher) undo(ctx context.Context, dryRun bool) error {
+	if err := p.connect(ctx); err != nil {
+		return err
+	}
+	return p.deleteStale(dryRun, 0, "timesheet line", func(id string) error {
+		return p.deleteEntry(ctx, id)
+	})
+}
+
+func (p *odooPusher) deleteEntry(ctx context.Context, id string) error {
+	lineID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("error deleting timesheet line %s: %v", id, err)
+	}
+	var ok bool
+	return p.execute(ctx, "account.analytic.line", "unlink", []any{[]int{lineID}}, &ok)
+}
+
+// resolve looks up the ID of the model record with the given name, caching
+// the result for the rest of the push.
+func (p *odooPusher) resolve(ctx context.Context, model, name string) (int, error) {
+	key := model + "/" + name
+	if id, ok := p.ids[key]; ok {
+		return id, nil
+	}
+
+	var ids []int
+	domain := []any{[]any{"name", "=", name}}
+	if err := p.execute(ctx, model, "search", []any{domain}, &ids); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("error resolving %q: no matching Odoo %s", name, model)
+	}
+	p.ids[key] = ids[0]
+	return ids[0], nil
+}
+
+func (p *odooPusher) execute(ctx context.Context, model, method string, args []any, v any) error {
+	return p.call(ctx, "object", "execute_kw", []any{*p.database, p.uid, p.apiKey, model, method, args}, v)
+}
+
+// call makes a JSON-RPC call to one of Odoo's external API services.
+func (p *odooPusher) call(ctx context.Context, service, method string, args []any, v any) error {
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "call",
+		"params":  map[string]any{"service": service, "method": method, "args": args},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(*p.baseURL, "/")+"/jsonrpc", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling Odoo: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error calling Odoo: %s.%s %s", service, method, resp.Status)
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+			Data    struct {
+				Message string `json:"message"`
+			} `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding the Odoo response: %v", err)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("error calling Odoo: %s: %s", result.Error.Message, result.Error.Data.Message)
+	}
+	// a failed login returns false rather than a uid
+	if string(result.Result) == "false" {
+		return nil
+	}
+	return json.Unmarshal(result.Result, v)
+}
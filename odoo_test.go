@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_odooPusher(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	var created []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params struct {
+				Service string `json:"service"`
+				Method  string `json:"method"`
+				Args    []any  `json:"args"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		switch {
+		case req.Params.Method == "login":
+			w.Write([]byte(`{"jsonrpc": "2.0", "result": 7}`))
+		case req.Params.Args[4] == "search":
+			w.Write([]byte(`{"jsonrpc": "2.0", "result": [42]}`))
+		case req.Params.Args[4] == "create":
+			line := req.Params.Args[5].([]any)[0].(map[string]any)
+			created = append(created, line)
+			w.Write([]byte(`{"jsonrpc": "2.0", "result": 1}`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("ODOO_API_KEY", "secret")
+
+	fs := flag.NewFlagSet("push odoo", flag.ContinueOnError)
+	p := newOdooPusher(fs)
+	fs.Parse([]string{"-odoo-url", server.URL, "-odoo-db", "prod", "-odoo-user", "me@example.com"})
+
+	config := &Config{Odoo: []OdooRule{{Keyword: "acme", Account: "ACME Corp"}}}
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10*time.Hour + 30*time.Minute), notes: "ACME sync"},
+		{start: date.Add(11 * time.Hour), end: date.Add(12 * time.Hour), notes: "Lunch"},
+	}
+
+	if err := p.push(context.Background(), config, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(created) != 1 {
+		t.Fatalf("expected 1 timesheet line, got %d", len(created))
+	}
+	if created[0]["account_id"] != 42.0 || created[0]["unit_amount"] != 1.5 || created[0]["date"] != "2024-03-15" {
+		t.Errorf("expected 1.5 hours on account 42 on 2024-03-15, got %v", created[0])
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// window is a span of time, such as an on-call shift.
+type window struct {
+	start time.Time
+	end   time.Time
+}
+
+const (
+	pagerDutyAPI = "https://api.pagerduty.com"
+	opsgenieAPI  = "https://api.opsgenie.com"
+)
+
+// pagerDutyGet calls the PagerDuty REST API and decodes the JSON response
+// into v.
+func pagerDutyGet(ctx context.Context, token, path string, query url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pagerDutyAPI+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token token="+token)
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling PagerDuty: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error calling PagerDuty: %s %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// fetchPagerDutyOnCall returns the windows the PagerDuty user is on call
+// between from and to.
+func fetchPagerDutyOnCall(ctx context.Context, token, userID string, from, to time.Time) ([]window, error) {
+	query := url.Values{}
+	query.Set("user_ids[]", userID)
+	query.Set("since", from.Format(time.RFC3339))
+	query.Set("until", to.Format(time.RFC3339))
+
+	var result struct {
+		OnCalls []struct {
+			Start *time.Time `json:"start"`
+			End   *time.Time `json:"end"`
+		} `json:"oncalls"`
+	}
+	if err := pagerDutyGet(ctx, token, "/oncalls", query, &result); err != nil {
+		return nil, err
+	}
+
+	windows := make([]window, 0, len(result.OnCalls))
+	for _, oc := range result.OnCalls {
+		// permanent on-call entries have no start or end
+		w := window{start: from, end: to}
+		if oc.Start != nil {
+			w.start = *oc.Start
+		}
+		if oc.End != nil {
+			w.end = *oc.End
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// fetchOpsgenieOnCall returns the windows the Opsgenie user (by email) is on
+// call in the named schedule between from and to.
+func fetchOpsgenieOnCall(ctx context.Context, apiKey, schedule, user string, from, to time.Time) ([]window, error) {
+	query := url.Values{}
+	query.Set("identifierType", "name")
+	query.Set("date", from.Format(time.RFC3339))
+	query.Set("interval", fmt.Sprint(int(to.Sub(from).Hours())))
+	query.Set("intervalUnit", "hours")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opsgenieAPI+"/v2/schedules/"+url.PathEscape(schedule)+"/timeline?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "GenieKey "+apiKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling Opsgenie: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error calling Opsgenie: schedule timeline %s", resp.Status)
+	}
+
+	var result struct {
+		Data struct {
+			FinalTimeline struct {
+				Rotations []struct {
+					Periods []struct {
+						StartDate time.Time `json:"startDate"`
+						EndDate   time.Time `json:"endDate"`
+						Recipient struct {
+							Name string `json:"name"`
+						} `json:"recipient"`
+					} `json:"periods"`
+				} `json:"rotations"`
+			} `json:"finalTimeline"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding the Opsgenie timeline: %v", err)
+	}
+
+	var windows []window
+	for _, rotation := range result.Data.FinalTimeline.Rotations {
+		for _, period := range rotation.Periods {
+			if period.Recipient.Name == user {
+				windows = append(windows, window{start: period.StartDate, end: period.EndDate})
+			}
+		}
+	}
+	return windows, nil
+}
+
+// splitAt splits the chunks straddling any of the boundaries, so that each
+// resulting piece lies wholly on one side of every boundary.
+func splitAt(chunks []*Chunk, boundaries []time.Time) []*Chunk {
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+
+	split := make([]*Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		rest := chunk
+		for _, boundary := range boundaries {
+			if boundary.After(rest.start) && boundary.Before(rest.end) {
+				head := *rest
+				head.end = boundary
+				split = append(split, &head)
+				tail := *rest
+				tail.start = boundary
+				rest = &tail
+			}
+		}
+		split = append(split, rest)
+	}
+	return split
+}
+
+// tagOnCall marks the chunks that fall inside an on-call window, splitting
+// chunks that straddle a window boundary so each piece is wholly on or off
+// call.
+func tagOnCall(chunks []*Chunk, windows []window) []*Chunk {
+	boundaries := make([]time.Time, 0, len(windows)*2)
+	for _, w := range windows {
+		boundaries = append(boundaries, w.start, w.end)
+	}
+
+	tagged := splitAt(chunks, boundaries)
+	for _, chunk := range tagged {
+		for _, w := range windows {
+			if !chunk.start.Before(w.start) && !chunk.end.After(w.end) {
+				chunk.onCall = true
+				chunk.explain("within an on-call shift")
+			}
+		}
+	}
+	return tagged
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_tagOnCall(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(12 * time.Hour), notes: ""},
+		{start: date.Add(12 * time.Hour), end: date.Add(17 * time.Hour), notes: "incident review"},
+	}
+	windows := []window{
+		{start: date.Add(16 * time.Hour), end: date.Add(24 * time.Hour)},
+		{start: date, end: date.Add(10 * time.Hour)},
+	}
+
+	tagged := tagOnCall(chunks, windows)
+
+	expected := []struct {
+		start  time.Time
+		end    time.Time
+		onCall bool
+	}{
+		{date.Add(9 * time.Hour), date.Add(10 * time.Hour), true},
+		{date.Add(10 * time.Hour), date.Add(12 * time.Hour), false},
+		{date.Add(12 * time.Hour), date.Add(16 * time.Hour), false},
+		{date.Add(16 * time.Hour), date.Add(17 * time.Hour), true},
+	}
+	if len(tagged) != len(expected) {
+		t.Fatalf("expected %d chunks, got %d", len(expected), len(tagged))
+	}
+	for i, chunk := range tagged {
+		if !chunk.start.Equal(expected[i].start) || !chunk.end.Equal(expected[i].end) || chunk.onCall != expected[i].onCall {
+			t.Errorf("expected chunk %d to be %s-%s on call %t, got %s-%s on call %t",
+				i, expected[i].start, expected[i].end, expected[i].onCall, chunk.start, chunk.end, chunk.onCall)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openProjectPusher creates OpenProject time entries on the work packages
+// referenced in chunk notes, updating or deleting those of an earlier push.
+type openProjectPusher struct {
+	entrySync
+	baseURL  *string
+	activity *string
+	token    string
+}
+
+func newOpenProjectPusher(fs *flag.FlagSet) pusher {
+	return &openProjectPusher{
+		baseURL:  fs.String("openproject-url", "", "Base URL of the OpenProject instance (API key from $OPENPROJECT_TOKEN)"),
+		activity: fs.String("openproject-activity", "", "Time entry activity ID (default the instance's default activity)"),
+	}
+}
+
+var workPackagePattern = regexp.MustCompile(`(?:^|[^\w/])#(\d+)\b`)
+
+// parseWorkPackages returns the IDs of the work packages referenced as #1234.
+func parseWorkPackages(text string) []string {
+	var ids []string
+	for _, m := range workPackagePattern.FindAllStringSubmatch(text, -1) {
+		ids = append(ids, m[1])
+	}
+	return ids
+}
+
+func (p *openProjectPusher) push(ctx context.Context, config *Config, date time.Time, chunks []*Chunk, dryRun bool) error {
+	if err := p.connect(dryRun); err != nil {
+		return err
+	}
+
+	sent := 0
+	keys := chunkKeys(chunks)
+	for i, chunk := range chunks {
+		comment := chunk.notes
+		if config.redacts("openproject", "notes") {
+			comment = ""
+		}
+		ids := parseWorkPackages(chunk.notes)
+		for _, id := range ids {
+			d := chunk.end.Sub(chunk.start) / time.Duration(len(ids))
+			body, err := p.timeEntry(id, d, date, comment)
+			if err != nil {
+				return partial(sent, err)
+			}
+			key := keys[i] + " on #" + id
+			entry, changed, pushed := p.lookup(key, body)
+			verb := "Logged"
+			switch {
+			case pushed && !changed:
+				p.keep(key, entry.ID, body)
+				continue
+			case pushed:
+				verb = "Updated"
+				if !dryRun {
+					if err := p.do(ctx, http.MethodPatch, "/api/v3/time_entries/"+entry.ID, body, nil); err != nil {
+						return partial(sent, err)
+					}
+					p.keep(key, entry.ID, body)
+					sent++
+				}
+			case !dryRun:
+				var created struct {
+					ID int `json:"id"`
+				}
+				if err := p.do(ctx, http.MethodPost, "/api/v3/time_entries", body, &created); err != nil {
+					return partial(sent, err)
+				}
+				p.keep(key, strconv.Itoa(created.ID), body)
+				sent++
+			}
+			fmt.Printf("%s%s %s on work package #%s\n", dryRunPrefix(dryRun), verb, formatSpent(d), id)
+		}
+	}
+
+	// the entries of chunks since removed or no longer referencing a work package
+	return p.deleteStale(dryRun, sent, "time entry", func(id string) error {
+		return p.deleteEntry(ctx, id)
+	})
+}
+
+// connect checks the URL and, unless dryRun, the API key the API calls use.
+func (p *openProjectPusher) connect(dryRun bool) error {
+	p.token = os.Getenv("OPENPROJECT_TOKEN")
+	if p.token == "" && !dryRun {
+		return errors.New("OPENPROJECT_TOKEN is not set")
+	}
+	if *p.baseURL == "" {
+		return errors.New("-openproject-url is required")
+	}
+	return nil
+}
+
+func (p *openProjectPusher) undo(ctx context.Context, dryRun bool) error {
+	if err := p.connect(dryRun); err != nil {
+		return err
+	}
+	return p.deleteStale(dryRun, 0, "time entry", func(id string) error {
+		return p.deleteEntry(ctx, id)
+	})
+}
+
+func (p *openProjectPusher) deleteEntry(ctx context.Context, id string) error {
+	return p.do(ctx, http.MethodDelete, "/api/v3/time_entries/"+id, nil, nil)
+}
+
+// timeEntry returns the body of a time entry of d on the work package.
+func (p *openProjectPusher) timeEntry(workPackage string, d time.Duration, date time.Time, comment string) ([]byte, error) {
+	type link struct {
+		Href string `json:"href"`
+	}
+	links := map[string]link{
+		"workPackage": {Href: "/api/v3/work_packages/" + workPackage},
+	}
+	if *p.activity != "" {
+		links["activity"] = link{Href: "/api/v3/time_entries/activities/" + *p.activity}
+	}
+	return json.Marshal(map[string]any{
+		"hours":   formatISODuration(d),
+		"spentOn": date.Format(dateLayout),
+		"comment": map[string]string{"raw": comment},
+		"_links":  links,
+	})
+}
+
+func (p *openProjectPusher) do(ctx context.Context, method, path string, body []byte, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(*p.baseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("apikey", p.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling OpenProject: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error calling OpenProject: %s %s %s", method, path, resp.Status)
+	}
+	if v != nil {
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+	return nil
+}
+
+// formatISODuration formats a duration as an ISO 8601 duration, e.g. PT1H30M.
+func formatISODuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case m == 0:
+		return fmt.Sprintf("PT%dH", h)
+	case h == 0:
+		return fmt.Sprintf("PT%dM", m)
+	default:
+		return fmt.Sprintf("PT%dH%dM", h, m)
+	}
+}
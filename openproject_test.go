@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_openProjectPusher(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	var logged []map[string]any
+	var changed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "apikey" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v3/time_entries":
+			entry := map[string]any{}
+			json.NewDecoder(r.Body).Decode(&entry)
+			logged = append(logged, entry)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 1000}`))
+		case "/api/v3/time_entries/5", "/api/v3/time_entries/6":
+			changed = append(changed, r.Method+" "+r.URL.Path)
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("OPENPROJECT_TOKEN", "secret")
+
+	newPusher := func() *openProjectPusher {
+		fs := flag.NewFlagSet("push openproject", flag.ContinueOnError)
+		p := newOpenProjectPusher(fs).(*openProjectPusher)
+		fs.Parse([]string{"-openproject-url", server.URL})
+		return p
+	}
+	config := &Config{}
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Estimate #12 and #13", Event: &Event{ID: "estimate"}},
+		{start: date.Add(10 * time.Hour), end: date.Add(11 * time.Hour), notes: "Lunch"},
+		{start: date.Add(13 * time.Hour), end: date.Add(14 * time.Hour), notes: "#14 review", Event: &Event{ID: "review"}},
+	}
+
+	first := newPusher()
+	if err := first.push(context.Background(), config, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(logged) != 3 {
+		t.Fatalf("expected 3 time entries, got %d", len(logged))
+	}
+	if logged[0]["hours"] != "PT30M" || logged[0]["spentOn"] != "2024-03-15" {
+		t.Errorf("expected half an hour on 2024-03-15, got %v", logged[0])
+	}
+
+	// pushing again updates the entry whose share of the chunk changed and
+	// deletes the removed work package's, leaving the unchanged one be
+	previous := first.tracked() // estimate #12, estimate #13, review #14
+	previous[0].ID, previous[1].ID = "5", "6"
+
+	logged, changed = nil, nil
+	chunks[0].notes = "Estimate #12"
+	second := newPusher()
+	second.track(previous)
+	if err := second.push(context.Background(), config, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(logged) != 0 {
+		t.Errorf("expected no new time entries, got %v", logged)
+	}
+	expected := []string{"PATCH /api/v3/time_entries/5", "DELETE /api/v3/time_entries/6"}
+	if len(changed) != len(expected) || changed[0] != expected[0] || changed[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, changed)
+	}
+	if tracked := second.tracked(); len(tracked) != 2 || tracked[0].ID != "5" || tracked[1].ID != "1000" {
+		t.Errorf("expected the entries 5 and 1000 tracked, got %v", tracked)
+	}
+
+	// undoing deletes what the push kept
+	changed = nil
+	third := newPusher()
+	third.track([]PushedEntry{{Chunk: "review on #14", ID: "5"}})
+	if err := third.undo(context.Background(), false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(changed) != 1 || changed[0] != "DELETE /api/v3/time_entries/5" {
+		t.Errorf("expected the entry deleted, got %v", changed)
+	}
+}
+
+func Test_parseWorkPackages(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{text: "#1234 Sprint planning", expected: []string{"1234"}},
+		{text: "Estimate #12 and #13", expected: []string{"12", "13"}},
+		{text: "Review group/api#45", expected: []string{}},
+		{text: "Lunch", expected: []string{}},
+	}
+
+	for _, test := range tests {
+		ids := parseWorkPackages(test.text)
+		if len(ids) != len(test.expected) {
+			t.Errorf("expected %d work packages in '%s', got %d", len(test.expected), test.text, len(ids))
+			continue
+		}
+		for i, id := range ids {
+			if id != test.expected[i] {
+				t.Errorf("expected work package '%s', got '%s'", test.expected[i], id)
+			}
+		}
+	}
+}
+
+func Test_formatISODuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                          "PT0H",
+		45 * time.Minute:           "PT45M",
+		2 * time.Hour:              "PT2H",
+		time.Hour + 30*time.Minute: "PT1H30M",
+	}
+	for d, expected := range tests {
+		if iso := formatISODuration(d); iso != expected {
+			t.Errorf("expected %s to format as '%s', got '%s'", d, expected, iso)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	formatCSV      = "csv"
+	formatTemplate = "template"
+)
+
+// outputOptions are the flags choosing how and where a report is written.
+type outputOptions struct {
+	format   string
+	template string
+	path     string
+}
+
+func (o *outputOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "", "Output format: csv, tty, html, svg, png, template, xlsx or ics (default tty on a terminal, else csv)")
+	fs.StringVar(&o.template, "template", "", "Path to the Go template for -format template")
+	fs.StringVar(&o.path, "o", "", "Path to write the report to, replaced atomically, e.g. report-{{date}}.csv for a file per day (default standard output)")
+}
+
+func (o *outputOptions) validate() error {
+	if o.format == "" {
+		o.format = formatCSV
+		if o.path == "" && isTerminal(os.Stdout) {
+			o.format = formatTTY
+		}
+	}
+	switch o.format {
+	case formatCSV, formatTTY, formatHTML, formatSVG, formatPNG, formatXLSX, formatICS:
+	case formatTemplate:
+		if o.template == "" {
+			return withExitCode(exitUsage, errors.New("-format template needs a -template file"))
+		}
+	default:
+		return withExitCode(exitUsage, fmt.Errorf("invalid -format %q: must be csv, tty, html, svg, png, template, xlsx or ics", o.format))
+	}
+	return nil
+}
+
+// dateVariable in an -o path is replaced with the report's date, e.g.
+// report-{{date}}.csv.
+const dateVariable = "{{date}}"
+
+// write renders the reports to standard output, or replaces the -o file with
+// them so readers never see it half written. A path with dateVariable gets a
+// file per day in the csv and template formats, and is named after the
+// first day in the others.
+func (o *outputOptions) write(reports []*Report, timeFormat string) error {
+	if o.path == "" {
+		return o.render(os.Stdout, reports, timeFormat)
+	}
+	if strings.Contains(o.path, dateVariable) && (o.format == formatCSV || o.format == formatTemplate) {
+		for _, r := range reports {
+			if err := o.writeFile(o.pathFor(r.Date), []*Report{r}, timeFormat); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	date := time.Now()
+	if len(reports) > 0 {
+		date = reports[0].Date
+	}
+	return o.writeFile(o.pathFor(date), reports, timeFormat)
+}
+
+// pathFor is the -o path of the report for date.
+func (o *outputOptions) pathFor(date time.Time) string {
+	return strings.ReplaceAll(o.path, dateVariable, date.Format(dateLayout))
+}
+
+func (o *outputOptions) writeFile(path string, reports []*Report, timeFormat string) error {
+	return replaceFile(path, "report", func(w io.Writer) error {
+		return o.render(w, reports, timeFormat)
+	})
+}
+
+// replaceFile writes the file at path, what it holds, e.g. "report", with
+// write into a temporary file renamed over it once complete, so readers never
+// see it half written and a failure leaves any earlier one be.
+func replaceFile(path, what string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating the %s directory: %v", what, err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating the %s file: %v", what, err)
+	}
+	defer os.Remove(f.Name())
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing the %s: %v", what, err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("error writing the %s: %v", what, err)
+	}
+	return nil
+}
+
+// render writes the reports in the -format: one after the other for csv and
+// template, together for tty, html, svg, png, xlsx and ics.
+func (o *outputOptions) render(w io.Writer, reports []*Report, timeFormat string) error {
+	switch o.format {
+	case formatTTY:
+		return writeTTY(w, reports, timeFormat, ttyWidth(), os.Getenv("NO_COLOR") == "")
+	case formatHTML:
+		return writeHTML(w, reports, timeFormat)
+	case formatSVG:
+		return writeSVG(w, reports, timeFormat)
+	case formatPNG:
+		return writePNG(w, reports)
+	case formatXLSX:
+		return writeXLSX(w, reports)
+	case formatICS:
+		_, err := fmt.Fprint(w, writeICS(reports, time.Now()))
+		return err
+	}
+	for _, report := range reports {
+		var err error
+		if o.format == formatTemplate {
+			err = report.template(w, o.template, timeFormat)
+		} else {
+			_, err = fmt.Fprint(w, report.csv(timeFormat))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Report is a day's chunks as the output formats see them. It is also what
+// -template files are executed with, so its fields are exported.
+type Report struct {
+	Date   time.Time
+	Rows   []Row
+	Total  time.Duration
+	OnCall time.Duration // zero unless on-call shifts are tracked
+	// NonBillable is the time not to bill; Total less it is billable.
+	NonBillable time.Duration
+	Cost        float64 // of the meetings, when Costed
+	Absence     string  // the type of absence the day was, if any
+	Location    string  // where most of the day was worked, if known
+	// Notes, Project, OnCallTracked and BillableTracked say which optional
+	// columns the report has; redacted ones are left empty in the rows.
+	Notes           bool
+	Project         bool
+	OnCallTracked   bool
+	BillableTracked bool
+	Explained       bool
+	RulesMatched    bool
+	Linked          bool // some rows have links
+	Tagged          bool // some rows have tags
+	Located         bool // the working location column is shown
+	Costed          bool
+}
+
+// Row is a single chunk of a Report.
+type Row struct {
+	Start    time.Time
+	End      time.Time
+	Notes    string
+	Project  string
+	OnCall   bool
+	Billable bool
+	Gap      bool     // time between events, though it may be named after one
+	Explain  []string // how the chunk was derived, with -explain
+	// MatchedRules are the config rules that classified the chunk, with
+	// -matched-rule.
+	MatchedRules []string
+	Links        []string // evidence of the work, such as PR URLs
+	Location     string   // the working location, e.g. "home"
+	EventID      string   // the calendar event's ID, "" for gaps
+	Tags         []string // tokens from the event's description, e.g. "#billable"
+	Cost         float64  // estimated from the attendees, when Costed
+	Color        string   // the project's palette color name, "" without one
+}
+
+// Duration is the length of the row.
+func (r Row) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// title names the row in calendars: its notes, or what kind of time it is if
+// they are empty or redacted.
+func (r Row) title() string {
+	switch {
+	case r.Notes != "":
+		return r.Notes
+	case r.Gap:
+		return "Unallocated"
+	default:
+		return "Busy"
+	}
+}
+
+// reportColumns are the optional columns a report shows.
+type reportColumns struct {
+	notes   bool
+	project bool
+	onCall  bool
+	explain bool
+	matched bool
+	links   bool
+	tags    bool
+	// location shows the working location column, and dayLocation the
+	// day's in the report's heading
+	location    bool
+	dayLocation bool
+	// projectColors are the configured colors of projects
+	projectColors map[string]string
+	// meetingCost is the hourly cost of an attendee, zero to leave costs out
+	meetingCost float64
+	// billable is set when billable rules are configured; [nb] tags show
+	// the column regardless
+	billable bool
+}
+
+func newReport(date time.Time, chunks []*Chunk, columns reportColumns) *Report {
+	report := &Report{
+		Date:            date,
+		Rows:            make([]Row, 0, len(chunks)),
+		Notes:           columns.notes,
+		OnCallTracked:   columns.onCall,
+		BillableTracked: columns.billable,
+		Explained:       columns.explain,
+		RulesMatched:    columns.matched,
+		Costed:          columns.meetingCost > 0,
+		Located:         columns.location,
+	}
+	if columns.dayLocation {
+		report.Location = dayLocation(chunks)
+	}
+	for _, chunk := range chunks {
+		row := Row{Start: chunk.start, End: chunk.end, Billable: !chunk.nonBillable, Gap: chunk.Event == nil}
+		if chunk.Event != nil {
+			row.EventID = chunk.Event.ID
+		}
+		if columns.notes {
+			row.Notes = chunk.notes
+		}
+		if columns.project && chunk.project != "" {
+			row.Project = chunk.project
+			report.Project = true
+		}
+		if row.Project != "" {
+			row.Color = projectColor(row.Project, columns.projectColors).name
+		}
+		if columns.explain {
+			row.Explain = chunk.why
+		}
+		if columns.matched {
+			row.MatchedRules = chunk.rules
+		}
+		if columns.location {
+			row.Location = chunk.location
+		}
+		if columns.links && len(chunk.links) > 0 {
+			row.Links = chunk.links
+			report.Linked = true
+		}
+		if columns.tags && len(chunk.tags) > 0 {
+			row.Tags = chunk.tags
+			report.Tagged = true
+		}
+		if columns.onCall {
+			row.OnCall = chunk.onCall
+			if chunk.onCall {
+				report.OnCall += row.Duration()
+			}
+		}
+		if chunk.Event != nil && columns.meetingCost > 0 {
+			row.Cost = float64(attendeeCount(chunk.Event)) * columns.meetingCost * row.Duration().Hours()
+			report.Cost += row.Cost
+		}
+		if chunk.nonBillable {
+			report.NonBillable += row.Duration()
+			report.BillableTracked = true
+		}
+		report.Total += row.Duration()
+		report.Rows = append(report.Rows, row)
+	}
+	return report
+}
+
+// csv renders the report as the summary line and CSV table.
+func (r *Report) csv(timeFormat string) string {
+	return fmt.Sprintf(`
+CSV report for the date: %s with a total of %s.
+
+%s`,
+		r.Date.Format(dateLayout),
+		r.totals(timeFormat),
+		r.table(timeFormat),
+	)
+}
+
+// totals describes the report's total time, and its on-call, billable,
+// cost, absence and location when it has them.
+func (r *Report) totals(timeFormat string) string {
+	summary := formatHours(timeFormat, r.Total)
+	if r.OnCallTracked {
+		summary += fmt.Sprintf(", %s of them on call", formatHours(timeFormat, r.OnCall))
+	}
+	if r.BillableTracked {
+		summary += fmt.Sprintf(" (%s billable, %s non-billable)", formatHours(timeFormat, r.Total-r.NonBillable), formatHours(timeFormat, r.NonBillable))
+	}
+	if r.Costed {
+		summary += fmt.Sprintf(" and meetings costing an estimated %.2f", r.Cost)
+	}
+	if r.Absence != "" {
+		summary += fmt.Sprintf(", absent (%s)", r.Absence)
+	}
+	if r.Location != "" {
+		summary += ", at " + r.Location
+	}
+	return summary
+}
+
+// table renders the report's rows as CSV with a header.
+func (r *Report) table(timeFormat string) string {
+	buf := strings.Builder{}
+	w := csv.NewWriter(&buf)
+
+	header := []string{"start", "end"}
+	if r.Notes {
+		header = append(header, "notes")
+	}
+	if r.Project {
+		header = append(header, "project")
+	}
+	if r.OnCallTracked {
+		header = append(header, "on_call")
+	}
+	if r.BillableTracked {
+		header = append(header, "billable")
+	}
+	if r.Explained {
+		header = append(header, "explain")
+	}
+	if r.RulesMatched {
+		header = append(header, "matched_rule")
+	}
+	if r.Linked {
+		header = append(header, "links")
+	}
+	if r.Tagged {
+		header = append(header, "tags")
+	}
+	if r.Located {
+		header = append(header, "location")
+	}
+	if r.Costed {
+		header = append(header, "cost")
+	}
+	w.Write(header)
+	for _, row := range r.Rows {
+		record := []string{
+			formatTimeAs(timeFormat, r.Date, row.Start),
+			formatTimeAs(timeFormat, r.Date, row.End),
+		}
+		if r.Notes {
+			record = append(record, row.Notes)
+		}
+		if r.Project {
+			record = append(record, row.Project)
+		}
+		if r.OnCallTracked {
+			record = append(record, strconv.FormatBool(row.OnCall))
+		}
+		if r.BillableTracked {
+			record = append(record, strconv.FormatBool(row.Billable))
+		}
+		if r.Explained {
+			record = append(record, strings.Join(row.Explain, "; "))
+		}
+		if r.RulesMatched {
+			record = append(record, strings.Join(row.MatchedRules, "; "))
+		}
+		if r.Linked {
+			record = append(record, strings.Join(row.Links, " "))
+		}
+		if r.Tagged {
+			record = append(record, strings.Join(row.Tags, " "))
+		}
+		if r.Located {
+			record = append(record, row.Location)
+		}
+		if r.Costed {
+			record = append(record, fmt.Sprintf("%.2f", row.Cost))
+		}
+		w.Write(record)
+	}
+	// writing to a strings.Builder doesn't fail
+	w.Flush()
+	return buf.String()
+}
+
+// template executes the Go template at path with the report. Besides the
+// built-in functions, templates can use "time" and "hours" to format times
+// and durations in the -time-format.
+func (r *Report) template(w io.Writer, path string, timeFormat string) error {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading the template: %v", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(template.FuncMap{
+		"time":  func(t time.Time) string { return formatTimeAs(timeFormat, r.Date, t) },
+		"hours": func(d time.Duration) string { return formatHours(timeFormat, d) },
+	}).Parse(string(text))
+	if err != nil {
+		return fmt.Errorf("error parsing the template: %v", err)
+	}
+	if err := tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("error executing the template: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testChunks(date time.Time) []*Chunk {
+	return []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Standup", project: "ACME"},
+		{start: date.Add(10 * time.Hour), end: date.Add(11*time.Hour + 30*time.Minute), notes: "", onCall: true},
+	}
+}
+
+func Test_Report_csv(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	report := newReport(date, testChunks(date), reportColumns{project: true, onCall: true})
+	output := report.csv(timeDecimal)
+
+	expected := `
+CSV report for the date: 2024-03-11 with a total of 2.50 hours, 1.50 hours of them on call.
+
+start,end,project,on_call
+09.00,10.00,ACME,false
+10.00,11.50,,true
+`
+	if output != expected {
+		t.Errorf("expected report:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func Test_Report_csv_quoting(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: `Review "v2", planning`, project: "ACME, Inc."},
+	}
+
+	report := newReport(date, chunks, reportColumns{notes: true, project: true})
+	output := report.csv(timeDecimal)
+
+	line := `09.00,10.00,"Review ""v2"", planning","ACME, Inc."` + "\n"
+	if !strings.Contains(output, line) {
+		t.Errorf("expected the report to contain %q, got:\n%s", line, output)
+	}
+}
+
+func Test_newReport_projectColumn(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	tagged := []*Chunk{{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), Event: &Event{Description: "#project:Globex"}}}
+	tagChunks(tagged, nil)
+
+	tests := []struct {
+		name     string
+		chunks   []*Chunk
+		columns  reportColumns
+		expected bool
+	}{
+		{name: "no projects", chunks: []*Chunk{{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour)}}, columns: reportColumns{project: true}},
+		{name: "project from a tag", chunks: tagged, columns: reportColumns{project: true}, expected: true},
+		{name: "redacted", chunks: tagged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := newReport(date, tt.chunks, tt.columns)
+			if report.Project != tt.expected {
+				t.Errorf("expected project column %v, got %v", tt.expected, report.Project)
+			}
+		})
+	}
+}
+
+func Test_Report_template(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "invoice.tmpl")
+	text := `Invoice for {{.Date.Format "02.01.2006"}}
+{{range .Rows}}{{time .Start}}-{{time .End}} {{hours .Duration}} {{or .Notes "Development"}}
+{{end}}Total: {{hours .Total}}`
+	if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	report := newReport(date, testChunks(date), reportColumns{notes: true})
+	buf := strings.Builder{}
+	if err := report.template(&buf, path, timeClock); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `Invoice for 11.03.2024
+09:00-10:00 1:00 Standup
+10:00-11:30 1:30 Development
+Total: 2:30`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func Test_Report_csv_matchedRule(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := testChunks(date)
+	classifyBillable(chunks, []BillableRule{{Keyword: "standup", Billable: false}})
+	classifyCapex(chunks, []CapexRule{{Capex: false}})
+
+	report := newReport(date, chunks, reportColumns{matched: true})
+	output := report.csv(timeDecimal)
+
+	for _, line := range []string{
+		"start,end,billable,matched_rule\n",
+		"09.00,10.00,false,billable #1; capex #1\n",
+		"10.00,11.50,true,capex #1\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected the report to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func Test_outputOptions_write(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	reports := []*Report{
+		newReport(date, testChunks(date), reportColumns{notes: true}),
+		newReport(date.AddDate(0, 0, 1), testChunks(date.AddDate(0, 0, 1)), reportColumns{notes: true}),
+	}
+
+	tests := []struct {
+		name     string
+		output   outputOptions
+		expected map[string]string // file name to the date heading it
+	}{
+		{
+			name:     "a file per day",
+			output:   outputOptions{format: formatCSV, path: filepath.Join(dir, "csv", "report-{{date}}.csv")},
+			expected: map[string]string{"csv/report-2024-03-11.csv": "2024-03-11", "csv/report-2024-03-12.csv": "2024-03-12"},
+		},
+		{
+			name:     "named after the first day",
+			output:   outputOptions{format: formatHTML, path: filepath.Join(dir, "html", "week-{{date}}.html")},
+			expected: map[string]string{"html/week-2024-03-11.html": "2024-03-12"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.output.write(reports, timeClock); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			entries, err := os.ReadDir(filepath.Dir(test.output.path))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(entries) != len(test.expected) {
+				t.Errorf("expected %d files, got %d", len(test.expected), len(entries))
+			}
+			for name, heading := range test.expected {
+				bytes, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("expected %s to be written, got %v", name, err)
+				}
+				if !strings.Contains(string(bytes), heading) {
+					t.Errorf("expected %s to contain %s", name, heading)
+				}
+			}
+		})
+	}
+}
+
+func Test_replaceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoice.html")
+	if err := os.WriteFile(path, []byte("earlier"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := replaceFile(path, "invoice", func(w io.Writer) error {
+		io.WriteString(w, "half")
+		return errors.New("template failed")
+	})
+	if err == nil {
+		t.Fatal("expected the write's error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "earlier" {
+		t.Errorf("expected a failed write to leave the earlier file, got %q", data)
+	}
+
+	err = replaceFile(path, "invoice", func(w io.Writer) error {
+		_, err := io.WriteString(w, "complete")
+		return err
+	})
+	if data, _ := os.ReadFile(path); err != nil || string(data) != "complete" {
+		t.Errorf("expected the file replaced, got %q (%v)", data, err)
+	}
+	if matches, _ := filepath.Glob(path + ".*.tmp"); len(matches) != 0 {
+		t.Errorf("expected no temporary files left, got %v", matches)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// runOverride implements the `override` subcommand, which relabels a single
+// event instance, such as one occurrence of a recurring meeting, without
+// editing the calendar.
+func runOverride(args []string) {
+	fs := flag.NewFlagSet("override", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	event := fs.String("event", "", "ID of the event instance, or a word from the summary of the only event of -date with it")
+	notes := fs.String("notes", "", "Notes to report the event with instead of its summary")
+	project := fs.String("project", "", "Project to report the event under")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+	if opts.freeBusy {
+		fatal(withExitCode(exitUsage, fmt.Errorf("-free-busy does not read the events to override")))
+	}
+	if *event == "" {
+		fatal(withExitCode(exitUsage, fmt.Errorf("-event is required")))
+	}
+
+	date, err := opts.reportDate()
+	if err != nil {
+		fatal(err)
+	}
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	items, err := opts.fetch(ctx, calendarService, date, date.AddDate(0, 0, 1))
+	if err != nil {
+		fatal(err)
+	}
+	e, err := findEvent(items, *event)
+	if err != nil {
+		fatal(err)
+	}
+
+	day := date.Format(dateLayout)
+	err = updateStore(opts.history, func(s *Store) {
+		s.Overrides[day] = setOverride(s.Overrides[day], Override{EventID: e.ID, Summary: e.Summary, Notes: *notes, Project: *project})
+	})
+	if err != nil {
+		fatal(err)
+	}
+	if *notes == "" && *project == "" {
+		fmt.Printf("Cleared the override of %q on %s.\n", e.Summary, day)
+		return
+	}
+	fmt.Printf("Overrode %q on %s.\n", e.Summary, day)
+}
+
+// findEvent returns the event with the ID, or else the only one whose summary
+// contains it.
+func findEvent(items []*Event, idOrSummary string) (*Event, error) {
+	var found []*Event
+	for _, e := range items {
+		if e.ID == idOrSummary {
+			return e, nil
+		}
+		if strings.Contains(strings.ToLower(e.Summary), strings.ToLower(idOrSummary)) {
+			found = append(found, e)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no event matches -event %q", idOrSummary)
+	case 1:
+		return found[0], nil
+	}
+	candidates := make([]string, 0, len(found))
+	for _, e := range found {
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", e.Summary, e.ID))
+	}
+	return nil, fmt.Errorf("-event %q matches several events, pass one's ID: %s", idOrSummary, strings.Join(candidates, ", "))
+}
+
+// setOverride replaces the event's override, removing it if it overrides
+// nothing.
+func setOverride(overrides []Override, override Override) []Override {
+	kept := make([]Override, 0, len(overrides)+1)
+	for _, o := range overrides {
+		if o.EventID != override.EventID {
+			kept = append(kept, o)
+		}
+	}
+	if override.Notes != "" || override.Project != "" {
+		kept = append(kept, override)
+	}
+	return kept
+}
+
+// applyOverrides sets the notes and project of the overridden events' chunks.
+func applyOverrides(chunks []*Chunk, overrides []Override) {
+	for _, o := range overrides {
+		for _, chunk := range chunks {
+			if chunk.Event == nil || chunk.Event.ID != o.EventID {
+				continue
+			}
+			if o.Notes != "" {
+				chunk.notes = o.Notes
+				chunk.explain("notes overridden as %q", o.Notes)
+			}
+			if o.Project != "" {
+				chunk.project = o.Project
+				chunk.explain("project overridden as %s", o.Project)
+			}
+		}
+	}
+}
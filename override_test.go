@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_findEvent(t *testing.T) {
+	items := []*Event{
+		{ID: "sync_20240311T090000Z", Summary: "Team sync"},
+		{ID: "sync2_20240311T140000Z", Summary: "Sync with design"},
+		{ID: "retro_20240311T160000Z", Summary: "Retro"},
+	}
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{value: "sync2_20240311T140000Z", expected: "sync2_20240311T140000Z"},
+		{value: "retro", expected: "retro_20240311T160000Z"},
+		{value: "sync", wantErr: true},
+		{value: "planning", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			e, err := findEvent(items, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && e.ID != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, e.ID)
+			}
+		})
+	}
+}
+
+func Test_applyOverrides(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	sync := &Event{ID: "sync_20240311T090000Z", Summary: "Sync"}
+	chunks := []*Chunk{
+		{Event: sync, start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "Sync", project: "ACME"},
+		{start: date.Add(10 * time.Hour), end: date.Add(11 * time.Hour)},
+	}
+	overrides := setOverride(nil, Override{EventID: "other", Notes: "Other"})
+	overrides = setOverride(overrides, Override{EventID: sync.ID, Notes: "Sync: hiring"})
+	overrides = setOverride(overrides, Override{EventID: sync.ID, Notes: "Sync: hiring", Project: "Recruiting"})
+
+	applyOverrides(chunks, overrides)
+
+	if len(overrides) != 2 {
+		t.Errorf("expected setting an override to replace the event's, got %v", overrides)
+	}
+	if chunks[0].notes != "Sync: hiring" || chunks[0].project != "Recruiting" {
+		t.Errorf("expected the sync relabeled, got %q under %q", chunks[0].notes, chunks[0].project)
+	}
+	if chunks[1].notes != "" {
+		t.Errorf("expected the gap left alone, got %q", chunks[1].notes)
+	}
+	if cleared := setOverride(overrides, Override{EventID: sync.ID}); len(cleared) != 1 {
+		t.Errorf("expected an empty override to clear the event's, got %v", cleared)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"strings"
+)
+
+// paletteColor is one of Google Calendar's event colors, with the CSS color
+// closest to it for formats that take CSS names, and its RGB for images.
+type paletteColor struct {
+	name string
+	id   string // the colorId of events
+	css  string
+	rgb  color.RGBA
+}
+
+var palette = []paletteColor{
+	{"lavender", "1", "mediumslateblue", color.RGBA{123, 104, 238, 255}},
+	{"sage", "2", "mediumseagreen", color.RGBA{60, 179, 113, 255}},
+	{"grape", "3", "mediumorchid", color.RGBA{186, 85, 211, 255}},
+	{"flamingo", "4", "lightcoral", color.RGBA{240, 128, 128, 255}},
+	{"banana", "5", "gold", color.RGBA{255, 215, 0, 255}},
+	{"tangerine", "6", "orangered", color.RGBA{255, 69, 0, 255}},
+	{"peacock", "7", "deepskyblue", color.RGBA{0, 191, 255, 255}},
+	{"graphite", "8", "gray", color.RGBA{128, 128, 128, 255}},
+	{"blueberry", "9", "royalblue", color.RGBA{65, 105, 225, 255}},
+	{"basil", "10", "seagreen", color.RGBA{46, 139, 87, 255}},
+	{"tomato", "11", "tomato", color.RGBA{255, 99, 71, 255}},
+}
+
+// findColor looks up a palette color by name.
+func findColor(name string) (paletteColor, bool) {
+	for _, c := range palette {
+		if c.name == strings.ToLower(name) {
+			return c, true
+		}
+	}
+	return paletteColor{}, false
+}
+
+// projectColor is the color assigned to the project, or else one picked by
+// hashing its name so it is the same on every run.
+func projectColor(project string, assigned map[string]string) paletteColor {
+	if c, ok := findColor(assigned[project]); ok {
+		return c
+	}
+	h := fnv.New32a()
+	h.Write([]byte(project))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+// validateProjectColors checks the assigned colors are in the palette.
+func validateProjectColors(assigned map[string]string) error {
+	for project, name := range assigned {
+		if _, ok := findColor(name); !ok {
+			names := make([]string, 0, len(palette))
+			for _, c := range palette {
+				names = append(names, c.name)
+			}
+			return fmt.Errorf("invalid color %q for project %q: must be one of %s", name, project, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func Test_projectColor(t *testing.T) {
+	assigned := map[string]string{"ACME": "Tomato"}
+
+	if c := projectColor("ACME", assigned); c.name != "tomato" || c.id != "11" {
+		t.Errorf("expected the assigned tomato, got %+v", c)
+	}
+	if a, b := projectColor("Internal", assigned), projectColor("Internal", nil); a != b {
+		t.Errorf("expected the same color on every run, got %+v and %+v", a, b)
+	}
+	if err := validateProjectColors(map[string]string{"ACME": "teal"}); err == nil {
+		t.Errorf("expected an error for a color outside the palette")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// runPlan implements the `plan` subcommand, which books focus-time events for
+// the configured priorities into the open slots of a day: the gap chunks.
+func runPlan(args []string) {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	opts := &reportOptions{scopes: []string{calendar.CalendarEventsScope}}
+	opts.register(fs)
+	tomorrow := fs.Bool("tomorrow", false, "Plan tomorrow instead of -date")
+	minSlot := fs.Duration("min-slot", time.Hour, "Shortest open slot worth booking")
+	yes := fs.Bool("yes", false, "Book every proposed block without asking")
+	dryRun := fs.Bool("dry-run", false, "Print the proposed blocks without booking them")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+
+	if len(opts.calendarIDs()) > 1 {
+		fatal(withExitCode(exitUsage, fmt.Errorf("plan books focus time on a single -calendar")))
+	}
+	if *dryRun {
+		// nothing is booked, so reading the calendar is enough
+		opts.scopes = nil
+	}
+	if *tomorrow {
+		today, err := opts.reportDate()
+		if err != nil {
+			fatal(err)
+		}
+		opts.date = today.AddDate(0, 0, 1).Format(dateLayout)
+	}
+
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	if len(config.Priorities) == 0 {
+		fatal(withExitCode(exitConfig, fmt.Errorf("no priorities in the config file to plan focus time for")))
+	}
+
+	ctx := signalContext()
+	date, chunks, err := opts.build(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	blocks := planFocus(chunks, config.Priorities, *minSlot)
+	if len(blocks) == 0 {
+		fmt.Printf("No open slots of %s or more on %s.\n", *minSlot, date.Format(dateLayout))
+		return
+	}
+
+	if *dryRun {
+		for _, block := range blocks {
+			fmt.Printf("(dry run) Book %s-%s %q\n", formatTime(block.start), formatTime(block.end), block.notes)
+		}
+		return
+	}
+
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	in := bufio.NewReader(os.Stdin)
+	for _, block := range blocks {
+		question := fmt.Sprintf("Book %s-%s %q?", formatTime(block.start), formatTime(block.end), block.notes)
+		if !*yes && !confirm(in, question) {
+			continue
+		}
+		_, err := calendarService.Events.Insert(opts.calendar, &calendar.Event{
+			Summary:   block.notes,
+			Start:     &calendar.EventDateTime{DateTime: block.start.Format(time.RFC3339)},
+			End:       &calendar.EventDateTime{DateTime: block.end.Format(time.RFC3339)},
+			EventType: "focusTime",
+			FocusTimeProperties: &calendar.EventFocusTimeProperties{
+				AutoDeclineMode: "declineNone",
+				ChatStatus:      "doNotDisturb",
+			},
+		}).Context(ctx).Do()
+		if err != nil {
+			fatal(withExitCode(exitAPI, fmt.Errorf("error booking focus time: %v", err)))
+		}
+		fmt.Printf("Booked %s-%s %q\n", formatTime(block.start), formatTime(block.end), block.notes)
+	}
+}
+
+// planFocus assigns the priorities, most important first, to the longest open
+// slots of at least min, returning the blocks in chronological order.
+func planFocus(chunks []*Chunk, priorities []string, min time.Duration) []*Chunk {
+	var slots []*Chunk
+	for _, chunk := range chunks {
+		if chunk.Event == nil && chunk.notes == "" && chunk.end.Sub(chunk.start) >= min {
+			slots = append(slots, chunk)
+		}
+	}
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].end.Sub(slots[i].start) > slots[j].end.Sub(slots[j].start)
+	})
+
+	blocks := make([]*Chunk, 0, len(priorities))
+	for i, slot := range slots {
+		if i == len(priorities) {
+			break
+		}
+		blocks = append(blocks, &Chunk{start: slot.start, end: slot.end, notes: priorities[i]})
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].start.Before(blocks[j].start) })
+	return blocks
+}
+
+// confirm asks a yes/no question on stdout, defaulting to no.
+func confirm(in *bufio.Reader, question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
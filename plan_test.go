@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_planFocus(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	standup := newEvent(date.Add(10*time.Hour), date.Add(10*time.Hour+30*time.Minute), "standup", "accepted", true)
+	lunch := newEvent(date.Add(12*time.Hour), date.Add(13*time.Hour), "lunch", "accepted", true)
+	review := newEvent(date.Add(16*time.Hour+30*time.Minute), date.Add(17*time.Hour), "review", "accepted", true)
+	// open slots: 9:00-10:00 (1h), 10:30-12:00 (1.5h), 13:00-16:30 (3.5h)
+	chunks := Chunkify(date, []*Event{standup, lunch, review}, Options{})
+
+	blocks := planFocus(chunks, []string{"design doc", "code review"}, time.Hour)
+
+	expected := []struct {
+		start time.Duration
+		end   time.Duration
+		notes string
+	}{
+		{10*time.Hour + 30*time.Minute, 12 * time.Hour, "code review"},
+		{13 * time.Hour, 16*time.Hour + 30*time.Minute, "design doc"},
+	}
+	if len(blocks) != len(expected) {
+		t.Fatalf("expected %d blocks, got %d", len(expected), len(blocks))
+	}
+	for i, block := range blocks {
+		if !block.start.Equal(date.Add(expected[i].start)) || !block.end.Equal(date.Add(expected[i].end)) || block.notes != expected[i].notes {
+			t.Errorf("expected block %d to be %s-%s '%s', got %s-%s '%s'", i,
+				date.Add(expected[i].start), date.Add(expected[i].end), expected[i].notes, block.start, block.end, block.notes)
+		}
+	}
+
+	if blocks := planFocus(chunks, []string{"design doc"}, 4*time.Hour); len(blocks) != 0 {
+		t.Errorf("expected no blocks when no slot is long enough, got %d", len(blocks))
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pluginPrefix prefixes the executables on the PATH that add push targets:
+// `chunkit push foo` runs chunkit-push-foo when foo is not built in.
+const pluginPrefix = "chunkit-push-"
+
+// pluginRequest is what plugins read on standard input and webhooks receive,
+// as JSON.
+type pluginRequest struct {
+	Date   string        `json:"date"`
+	DryRun bool          `json:"dryRun"`
+	Chunks []pluginChunk `json:"chunks"`
+}
+
+// pluginChunk is a chunk as plugins see it, with the columns redacted for
+// the plugin's target left empty.
+type pluginChunk struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Minutes  int       `json:"minutes"`
+	Notes    string    `json:"notes"`
+	Project  string    `json:"project"`
+	Billable bool      `json:"billable"`
+	OnCall   bool      `json:"onCall"`
+	Gap      bool      `json:"gap"`
+	Links    []string  `json:"links,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+}
+
+// pluginPusher pushes by running a plugin executable, passing it the
+// arguments after -- and the chunks on standard input. Its output is shown
+// as is, and it fails by exiting non-zero.
+type pluginPusher struct {
+	name string
+	path string
+	fs   *flag.FlagSet
+}
+
+// pluginTarget returns the constructor of the push target of the plugin at
+// path, named after it.
+func pluginTarget(path string) func(fs *flag.FlagSet) pusher {
+	return func(fs *flag.FlagSet) pusher {
+		return &pluginPusher{name: strings.TrimPrefix(filepath.Base(path), pluginPrefix), path: path, fs: fs}
+	}
+}
+
+func (p *pluginPusher) push(ctx context.Context, config *Config, date time.Time, chunks []*Chunk, dryRun bool) error {
+	body, err := json.Marshal(newPluginRequest(config, p.name, date, chunks, dryRun))
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, p.path, p.fs.Args()...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return withExitCode(exitAPI, fmt.Errorf("error running the %s plugin: %v", p.name, err))
+	}
+	return nil
+}
+
+func newPluginRequest(config *Config, target string, date time.Time, chunks []*Chunk, dryRun bool) pluginRequest {
+	request := pluginRequest{Date: date.Format(dateLayout), DryRun: dryRun, Chunks: make([]pluginChunk, 0, len(chunks))}
+	for _, chunk := range chunks {
+		c := pluginChunk{
+			Start:    chunk.start,
+			End:      chunk.end,
+			Minutes:  int(chunk.end.Sub(chunk.start).Minutes()),
+			Billable: !chunk.nonBillable,
+			Gap:      chunk.Event == nil,
+		}
+		if !config.redacts(target, "notes") {
+			c.Notes = chunk.notes
+		}
+		if !config.redacts(target, "project") {
+			c.Project = chunk.project
+		}
+		if !config.redacts(target, "on_call") {
+			c.OnCall = chunk.onCall
+		}
+		if !config.redacts(target, "links") {
+			c.Links = chunk.links
+		}
+		if !config.redacts(target, "tags") {
+			c.Tags = chunk.tags
+		}
+		request.Chunks = append(request.Chunks, c)
+	}
+	return request
+}
+
+// plugins lists the names of the plugins on the PATH.
+func plugins() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, pluginPrefix+"*"))
+		for _, match := range matches {
+			name := strings.TrimPrefix(filepath.Base(match), pluginPrefix)
+			if _, err := exec.LookPath(match); err != nil || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func Test_pluginPusher(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin is a shell script")
+	}
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, pluginPrefix+"intranet")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\ncat > \"$1\"\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if names := plugins(); !reflect.DeepEqual(names, []string{"intranet"}) {
+		t.Errorf("expected the intranet plugin, got %v", names)
+	}
+
+	fs := flag.NewFlagSet("push intranet", flag.ContinueOnError)
+	p := pluginTarget(path)(fs)
+	received := filepath.Join(dir, "received.json")
+	fs.Parse([]string{"--", received})
+
+	config := &Config{Redact: map[string][]string{"intranet": {"notes"}}}
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10*time.Hour + 30*time.Minute), notes: "ACME sync", project: "acme", Event: &Event{}},
+		{start: date.Add(10*time.Hour + 30*time.Minute), end: date.Add(11 * time.Hour), nonBillable: true},
+	}
+	if err := p.push(context.Background(), config, date, chunks, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(received)
+	if err != nil {
+		t.Fatalf("expected the plugin to receive the chunks, got %v", err)
+	}
+	var request pluginRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatalf("expected JSON, got %v", err)
+	}
+	if request.Date != "2024-03-15" || !request.DryRun || len(request.Chunks) != 2 {
+		t.Fatalf("expected a dry run of 2 chunks on 2024-03-15, got %+v", request)
+	}
+	first, second := request.Chunks[0], request.Chunks[1]
+	if first.Minutes != 90 || first.Notes != "" || first.Project != "acme" || first.Gap || !first.Billable {
+		t.Errorf("expected 90 billable minutes of acme with the notes redacted, got %+v", first)
+	}
+	if !second.Gap || second.Billable {
+		t.Errorf("expected a non-billable gap, got %+v", second)
+	}
+
+	failing := filepath.Join(dir, pluginPrefix+"broken")
+	if err := os.WriteFile(failing, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err = pluginTarget(failing)(flag.NewFlagSet("push broken", flag.ContinueOnError)).push(context.Background(), config, date, chunks, false)
+	if exitCode(err) != exitAPI {
+		t.Errorf("expected a failing plugin to exit with %d, got %v", exitAPI, err)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// pusher sends a day's chunks to an external time tracker, or with dryRun
+// only says what it would send.
+type pusher interface {
+	push(ctx context.Context, config *Config, date time.Time, chunks []*Chunk, dryRun bool) error
+}
+
+// calendarWriter is implemented by targets that write to Google Calendar, and
+// so need the events scope and the command's authorized client.
+type calendarWriter interface {
+	useCalendar(calendarService *calendar.Service)
+}
+
+// pushTargets maps the `push` targets to constructors, which register the
+// target's own flags on fs before it is parsed.
+var pushTargets = map[string]func(fs *flag.FlagSet) pusher{
+	"gitlab":      newGitLabPusher,
+	"calendar":    newCalendarPusher,
+	"kimai":       newKimaiPusher,
+	"odoo":        newOdooPusher,
+	"openproject": newOpenProjectPusher,
+	"webhook":     newWebhookPusher,
+}
+
+// runPush implements the `push` subcommand, sending a day's chunks to the
+// target named by the first argument, built in or a plugin on the PATH.
+func runPush(args []string) {
+	// allow `push -undo <target>` as well as `push <target> -undo`
+	if len(args) > 1 && (args[0] == "-undo" || args[0] == "--undo") {
+		args = append([]string{args[1], args[0]}, args[2:]...)
+	}
+	var newPusher func(fs *flag.FlagSet) pusher
+	if len(args) > 0 {
+		newPusher = pushTargets[args[0]]
+		if newPusher == nil {
+			if path, err := exec.LookPath(pluginPrefix + args[0]); err == nil {
+				newPusher = pluginTarget(path)
+			}
+		}
+	}
+	if newPusher == nil {
+		targets := make([]string, 0, len(pushTargets))
+		for name := range pushTargets {
+			targets = append(targets, name)
+		}
+		sort.Strings(targets)
+		fmt.Fprintf(os.Stderr, "usage: chunkit push [-undo] <target> [flags] [-- plugin args]\n\ntargets: %s\n", strings.Join(targets, ", "))
+		if names := plugins(); len(names) > 0 {
+			fmt.Fprintf(os.Stderr, "plugins: %s\n", strings.Join(names, ", "))
+		}
+		os.Exit(exitUsage)
+	}
+
+	fs := flag.NewFlagSet("push "+args[0], flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	p := newPusher(fs)
+	dryRun := fs.Bool("dry-run", false, "Print what would be sent without sending it")
+	lock := fs.Bool("lock", false, "Lock the day once pushed, so later runs warn if its calendar changes")
+	force := fs.Bool("force", false, "Push a locked day even though its calendar has changed")
+	undo := fs.Bool("undo", false, "Delete the entries the day's last push created instead of pushing")
+	if err := opts.parse(fs, args[1:]); err != nil {
+		fatal(err)
+	}
+	if *undo {
+		undoer, ok := p.(entryUndoer)
+		if !ok {
+			fatal(withExitCode(exitUsage, fmt.Errorf("push %s can't undo: it doesn't keep the IDs of what it pushes", args[0])))
+		}
+		if err := undoPush(signalContext(), undoer, args[0], opts, *dryRun); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	writer, writesCalendar := p.(calendarWriter)
+	if writesCalendar {
+		opts.scopes = []string{calendar.CalendarEventsScope}
+	}
+
+	ctx := signalContext()
+	date, chunks, err := opts.build(ctx)
+	if err != nil {
+		fatal(err)
+	}
+	if writesCalendar {
+		calendarService, err := opts.service(ctx)
+		if err != nil {
+			fatal(err)
+		}
+		writer.useCalendar(calendarService)
+	}
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	store, err := openStore(opts.history)
+	if err != nil {
+		fatal(err)
+	}
+	if drift := store.lockDrift(date, opts.dayFingerprint(date)); drift != "" {
+		if !*force {
+			fatal(withExitCode(exitUsage, fmt.Errorf("%s; check the calendar, then push with -force to update the timesheet", drift)))
+		}
+		log.Printf("warning: %s", drift)
+	}
+	tracker, tracks := p.(entryTracker)
+	if tracks {
+		tracker.track(store.Pushed[pushedKey(args[0], date)])
+	}
+	pushErr := p.push(ctx, config, date, chunks, *dryRun)
+	// record the entries even if the push failed midway, to update them next time
+	if tracks && !*dryRun {
+		err := updateStore(opts.history, func(s *Store) {
+			s.Pushed[pushedKey(args[0], date)] = tracker.tracked()
+		})
+		if err != nil {
+			fatal(err)
+		}
+	}
+	if pushErr != nil {
+		fatal(pushErr)
+	}
+	// a locked day pushed again is locked to its calendar as pushed
+	if _, locked := store.Locks[date.Format(dateLayout)]; (*lock || locked) && !*dryRun {
+		err := updateStore(opts.history, func(s *Store) {
+			s.Locks[date.Format(dateLayout)] = DayLock{At: time.Now(), Target: args[0], Fingerprint: opts.dayFingerprint(date)}
+		})
+		if err != nil {
+			fatal(err)
+		}
+	}
+}
+
+// undoPush deletes the entries of the -date's last push to target, and
+// unlocks the day if that push locked it.
+func undoPush(ctx context.Context, p entryUndoer, target string, opts *reportOptions, dryRun bool) error {
+	date, err := opts.reportDate()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(opts.history)
+	if err != nil {
+		return err
+	}
+	key := pushedKey(target, date)
+	if len(store.Pushed[key]) == 0 {
+		fmt.Printf("Nothing pushed to %s for %s to undo.\n", target, date.Format(dateLayout))
+		return nil
+	}
+	p.track(store.Pushed[key])
+	undoErr := p.undo(ctx, dryRun)
+	if dryRun {
+		return undoErr
+	}
+	// keep what couldn't be deleted, to retry
+	err = updateStore(opts.history, func(s *Store) {
+		if left := p.tracked(); len(left) > 0 {
+			s.Pushed[key] = left
+			return
+		}
+		delete(s.Pushed, key)
+		if s.Locks[date.Format(dateLayout)].Target == target {
+			delete(s.Locks, date.Format(dateLayout))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return undoErr
+}
+
+// dryRunPrefix marks the output of a dry run.
+func dryRunPrefix(dryRun bool) string {
+	if dryRun {
+		return "(dry run) "
+	}
+	return ""
+}
+
+// formatSpent formats a duration the way time trackers expect it, e.g. 1h30m.
+func formatSpent(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%dm", h, m)
+	}
+}
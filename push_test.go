@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_undoPush(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path == "/api/timesheets/7" {
+			http.Error(w, "not allowed", http.StatusForbidden)
+			return
+		}
+		deleted = append(deleted, r.URL.Path)
+	}))
+	defer server.Close()
+	t.Setenv("KIMAI_TOKEN", "secret")
+
+	history := filepath.Join(t.TempDir(), "history.json")
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	err := updateStore(history, func(s *Store) {
+		s.Pushed[pushedKey("kimai", date)] = []PushedEntry{{Chunk: "a", ID: "5"}, {Chunk: "b", ID: "6"}}
+		s.Pushed[pushedKey("odoo", date)] = []PushedEntry{{Chunk: "a", ID: "5"}}
+		s.Pushed[pushedKey("kimai", date.AddDate(0, 0, 1))] = []PushedEntry{{Chunk: "c", ID: "7"}}
+		s.Locks[date.Format(dateLayout)] = DayLock{Target: "kimai"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		date    string
+		dryRun  bool
+		deleted int
+		left    int
+		locked  bool
+		fails   bool
+	}{
+		{name: "dry run", date: "2024-03-15", dryRun: true, left: 2, locked: true},
+		{name: "undo", date: "2024-03-15", deleted: 2},
+		{name: "nothing left", date: "2024-03-15"},
+		{name: "failed delete kept", date: "2024-03-16", left: 1, fails: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted = nil
+			p := newKimaiPusher(flag.NewFlagSet("push kimai", flag.ContinueOnError)).(*kimaiPusher)
+			p.baseURL = &server.URL
+			opts := &reportOptions{date: tt.date, tz: "UTC", history: history}
+			err := undoPush(context.Background(), p, "kimai", opts, tt.dryRun)
+			if (err != nil) != tt.fails {
+				t.Fatalf("expected failure %v, got %v", tt.fails, err)
+			}
+			if len(deleted) != tt.deleted {
+				t.Errorf("expected %d deleted, got %v", tt.deleted, deleted)
+			}
+			store, err := openStore(history)
+			if err != nil {
+				t.Fatal(err)
+			}
+			day, _ := time.Parse(dateLayout, tt.date)
+			if left := len(store.Pushed[pushedKey("kimai", day)]); left != tt.left {
+				t.Errorf("expected %d entries left, got %d", tt.left, left)
+			}
+			if len(store.Pushed[pushedKey("odoo", date)]) != 1 {
+				t.Errorf("expected the odoo push untouched")
+			}
+			if _, locked := store.Locks["2024-03-15"]; locked != tt.locked {
+				t.Errorf("expected locked %v, got %v", tt.locked, locked)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// queries are the canned questions `chunkit query` answers from the history,
+// each a CSV of the recorded days between from and to.
+var queries = map[string]func(days map[string]*DayRecord) string{
+	"projects": queryProjects,
+	"weekdays": queryWeekdays,
+	"longest":  queryLongest,
+}
+
+// runQuery implements the `query` subcommand, answering a canned question
+// about the recorded days without exporting them first.
+func runQuery(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	historyPath := fs.String("history", filepath.Join(dataDir(), "history.json"), "Path to the local report history")
+	from := fs.String("from", "", "First date to include, YYYY-MM-DD; all recorded days if empty")
+	to := fs.String("to", "", "Last date to include, YYYY-MM-DD; all recorded days if empty")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: chunkit query [-history path] [-from date] [-to date] projects|weekdays|longest")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	query, ok := queries[fs.Arg(0)]
+	if fs.NArg() != 1 || !ok {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	for _, date := range []string{*from, *to} {
+		if _, err := time.Parse(dateLayout, date); date != "" && err != nil {
+			fatal(withExitCode(exitUsage, fmt.Errorf("invalid date %q: must be YYYY-MM-DD", date)))
+		}
+	}
+	store, err := openStore(*historyPath)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Print(query(daysBetween(store.Days, *from, *to)))
+}
+
+// daysBetween returns the records from the date from to the date to, both
+// included and either open if empty.
+func daysBetween(days map[string]*DayRecord, from, to string) map[string]*DayRecord {
+	selected := map[string]*DayRecord{}
+	for date, record := range days {
+		if (from == "" || date >= from) && (to == "" || date <= to) {
+			selected[date] = record
+		}
+	}
+	return selected
+}
+
+// queryProjects totals the days and hours of each project, the most hours
+// first.
+func queryProjects(days map[string]*DayRecord) string {
+	type total struct {
+		project string
+		days    int
+		hours   float64
+	}
+	totals := map[string]*total{}
+	for _, record := range days {
+		for project, hours := range record.Projects {
+			if totals[project] == nil {
+				totals[project] = &total{project: project}
+			}
+			totals[project].days++
+			totals[project].hours += hours
+		}
+	}
+	rows := make([]*total, 0, len(totals))
+	for _, t := range totals {
+		rows = append(rows, t)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].hours != rows[j].hours {
+			return rows[i].hours > rows[j].hours
+		}
+		return rows[i].project < rows[j].project
+	})
+	buf := strings.Builder{}
+	buf.WriteString("project,days,hours\n")
+	for _, row := range rows {
+		buf.WriteString(fmt.Sprintf("%s,%d,%.2f\n", row.project, row.days, row.hours))
+	}
+	return buf.String()
+}
+
+// queryWeekdays averages the hours of the days worked per weekday, Monday
+// first.
+func queryWeekdays(days map[string]*DayRecord) string {
+	var counts [7]int
+	var hours [7]float64
+	for date, record := range days {
+		day, err := time.Parse(dateLayout, date)
+		if err != nil || record.Hours == 0 {
+			continue
+		}
+		counts[day.Weekday()]++
+		hours[day.Weekday()] += record.Hours
+	}
+	buf := strings.Builder{}
+	buf.WriteString("weekday,days,average_hours\n")
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		if counts[day] > 0 {
+			buf.WriteString(fmt.Sprintf("%s,%d,%.2f\n", day, counts[day], hours[day]/float64(counts[day])))
+		}
+	}
+	return buf.String()
+}
+
+// queryLongest lists the ten days with the most hours.
+func queryLongest(days map[string]*DayRecord) string {
+	dates := make([]string, 0, len(days))
+	for date := range days {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		if days[dates[i]].Hours != days[dates[j]].Hours {
+			return days[dates[i]].Hours > days[dates[j]].Hours
+		}
+		return dates[i] < dates[j]
+	})
+	buf := strings.Builder{}
+	buf.WriteString("date,hours\n")
+	for _, date := range dates[:min(len(dates), 10)] {
+		buf.WriteString(fmt.Sprintf("%s,%.2f\n", date, days[date].Hours))
+	}
+	return buf.String()
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func Test_queries(t *testing.T) {
+	days := map[string]*DayRecord{
+		"2024-03-11": {Hours: 8, Projects: map[string]float64{"ACME": 6, "": 2}},
+		"2024-03-12": {Hours: 4, Projects: map[string]float64{"ACME": 4}},
+		"2024-03-18": {Hours: 6, Projects: map[string]float64{"Globex": 6}},
+		"2024-03-23": {Hours: 0},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		from, to string
+		expected string
+	}{
+		{
+			name:     "projects",
+			query:    "projects",
+			expected: "project,days,hours\nACME,2,10.00\nGlobex,1,6.00\n,1,2.00\n",
+		},
+		{
+			name:     "projects in range",
+			query:    "projects",
+			from:     "2024-03-12",
+			to:       "2024-03-17",
+			expected: "project,days,hours\nACME,1,4.00\n",
+		},
+		{
+			name:     "weekdays",
+			query:    "weekdays",
+			expected: "weekday,days,average_hours\nMonday,2,7.00\nTuesday,1,4.00\n",
+		},
+		{
+			name:     "longest",
+			query:    "longest",
+			expected: "date,hours\n2024-03-11,8.00\n2024-03-18,6.00\n2024-03-12,4.00\n2024-03-23,0.00\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := queries[tt.query](daysBetween(days, tt.from, tt.to))
+			if output != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, output)
+			}
+		})
+	}
+}
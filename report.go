@@ -0,0 +1,643 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// reportOptions are the flags shared by every command that builds a day's
+// chunks from the calendar.
+type reportOptions struct {
+	date               string
+	config             string
+	preset             string
+	credentials        string
+	token              string
+	tz                 string
+	attributeStart     bool
+	clampWorkday       bool
+	tentative          string
+	attendUnlisted     bool
+	calendar           string
+	freeBusy           bool
+	input              string
+	inputItems         []*Event // the -input events, once read
+	absent             *absences
+	printed            *fingerprints
+	exclude            string
+	includeOnly        string
+	minDuration        time.Duration
+	merge              bool
+	pagerDutyUser      string
+	pagerDutyIncidents bool
+	opsgenieSchedule   string
+	opsgenieUser       string
+	timeFormat         string
+	verbose            bool
+	explain            bool
+	matchedRule        bool
+	workingLocation    bool
+	history            string
+	logFormat          string
+	login              loginOptions
+	requestTimeout     time.Duration
+	workers            int
+	rateLimit          float64
+
+	// scopes are the OAuth scopes the command needs, defaultScope if empty
+	scopes []string
+	loaded *Config
+}
+
+func (o *reportOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.date, "date", "", "The date as YYYY-MM-DD, or relative: yesterday, monday, -3d, this-week, last-week (default today)")
+	fs.StringVar(&o.config, "config", filepath.Join(configDir(), "config.json"), "Path to the optional JSON config file")
+	fs.StringVar(&o.history, "history", filepath.Join(dataDir(), "history.json"), "Path to the local report history, with notes, annotations and overrides")
+	fs.StringVar(&o.preset, "preset", "", "Name of a preset of flag values from the config file")
+	fs.StringVar(&o.credentials, "credentials", filepath.Join(configDir(), "credentials.json"), "Path to the Google OAuth client credentials file")
+	fs.StringVar(&o.token, "token", filepath.Join(configDir(), "token.json"), "Path to the cached OAuth token file")
+	o.login.register(fs)
+	fs.DurationVar(&o.requestTimeout, "request-timeout", defaultRequestTimeout, "How long to wait for each request to Google and the export targets before giving up")
+	fs.IntVar(&o.workers, "workers", 4, "How many days of a range to fetch at once")
+	fs.Float64Var(&o.rateLimit, "rate-limit", 10, "Most days to start fetching per second, to stay under the API's quota; 0 for no limit")
+	fs.StringVar(&o.calendar, "calendar", primaryCalendar, "ID of the calendar to report on, e.g. the email of someone who shared theirs with you, or several separated by commas to merge")
+	fs.StringVar(&o.input, "input", "", "Read the events from a JSON file, or standard input for -, instead of Google Calendar")
+	fs.BoolVar(&o.freeBusy, "free-busy", false, "Only read when the calendar is busy, not its events, for orgs that don't allow granting access to event details")
+	fs.StringVar(&o.tz, "tz", "", "IANA timezone to report in, e.g. 'Europe/Berlin' (default the local timezone)")
+	fs.BoolVar(&o.attributeStart, "attribute-start", false, "Attribute events crossing midnight wholly to the day they started")
+	fs.BoolVar(&o.clampWorkday, "clamp-workday", false, "Cut events down to the workday, 9-5 unless the config's workdays say otherwise, instead of the whole day")
+	fs.StringVar(&o.tentative, "tentative", tentativeInclude, "What to do with tentative and unanswered events: include, exclude or flag")
+	fs.BoolVar(&o.attendUnlisted, "attend-unlisted", false, "Count events on the calendar that don't list you as an attendee, e.g. organizer-only invites with a room, as attended")
+	fs.StringVar(&o.exclude, "exclude", "", "Regular expression for event summaries to leave out, e.g. 'Focus time|Lunch'")
+	fs.StringVar(&o.includeOnly, "include-only", "", "Regular expression event summaries must match to be included, e.g. '\\[billable\\]'")
+	fs.DurationVar(&o.minDuration, "min-duration", 0, "Drop chunks shorter than this, e.g. 15m")
+	fs.BoolVar(&o.merge, "merge", false, "Merge adjacent gaps, and adjacent chunks of the same event or project")
+	fs.StringVar(&o.pagerDutyUser, "pagerduty-user", "", "PagerDuty user ID whose on-call shifts tag chunks (token from $PAGERDUTY_TOKEN)")
+	fs.BoolVar(&o.pagerDutyIncidents, "pagerduty-incidents", false, "Label gap time spent on -pagerduty-user's incidents with the incident ID")
+	fs.StringVar(&o.opsgenieSchedule, "opsgenie-schedule", "", "Opsgenie schedule name whose on-call shifts tag chunks (API key from $OPSGENIE_API_KEY)")
+	fs.StringVar(&o.opsgenieUser, "opsgenie-user", "", "Opsgenie user email to look for in -opsgenie-schedule")
+	fs.BoolVar(&o.matchedRule, "matched-rule", false, "Add a column naming the config rules that classified each chunk, to audit them")
+	fs.BoolVar(&o.workingLocation, "working-location", false, "Add a column with the working location, e.g. home, of each chunk from the calendar's working location events")
+	fs.BoolVar(&o.explain, "explain", false, "Add a column explaining how each chunk was derived: the event times, rounding, overlaps and rules")
+	fs.BoolVar(&o.verbose, "verbose", false, "Log the fetched events and how they were filtered, rounded and resolved into chunks")
+	fs.StringVar(&o.logFormat, "log-format", "text", "Format of the logs on standard error: text or json")
+	fs.StringVar(&o.timeFormat, "time-format", timeDecimal, "How to write times: decimal (15.50), hh:mm (15:30), iso8601 or duration since midnight (15h30m)")
+}
+
+// tracksOnCall reports whether chunks are tagged with on-call shifts.
+func (o *reportOptions) tracksOnCall() bool {
+	return o.pagerDutyUser != "" || o.opsgenieSchedule != ""
+}
+
+// columns are the optional columns of the report, less those the config
+// redacts from it.
+func (o *reportOptions) columns(config *Config) reportColumns {
+	return reportColumns{
+		notes:         !config.redacts("csv", "notes"),
+		project:       !config.redacts("csv", "project"),
+		onCall:        o.tracksOnCall() && !config.redacts("csv", "on_call"),
+		explain:       o.explain,
+		matched:       o.matchedRule,
+		links:         !config.redacts("csv", "links"),
+		tags:          !config.redacts("csv", "tags"),
+		location:      o.workingLocation && !config.redacts("csv", "location"),
+		dayLocation:   !config.redacts("csv", "location"),
+		meetingCost:   config.MeetingCost,
+		projectColors: config.ProjectColors,
+		billable:      len(config.Billable) > 0,
+	}
+}
+
+// parse parses the command line, applies the -preset and sets up logging.
+func (o *reportOptions) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := o.applyPreset(fs); err != nil {
+		return err
+	}
+	httpClient.Timeout = o.requestTimeout
+	return o.setupLogging()
+}
+
+// setupLogging replaces the default logger when -verbose or -log-format json
+// ask for more than the standard log lines.
+func (o *reportOptions) setupLogging() error {
+	level := slog.LevelInfo
+	if o.verbose {
+		level = slog.LevelDebug
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch o.logFormat {
+	case "text":
+		if o.verbose {
+			slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, handlerOpts)))
+		}
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, handlerOpts)))
+	default:
+		return fmt.Errorf("invalid -log-format %q: must be text or json", o.logFormat)
+	}
+	return nil
+}
+
+// applyPreset applies the -preset, whose values only fill in flags that were
+// not given explicitly.
+func (o *reportOptions) applyPreset(fs *flag.FlagSet) error {
+	if o.preset == "" {
+		return nil
+	}
+
+	config, err := o.loadConfig()
+	if err != nil {
+		return err
+	}
+	preset, ok := config.Presets[o.preset]
+	if !ok {
+		return fmt.Errorf("no preset named %q in the config file", o.preset)
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for name, value := range preset {
+		if name == "preset" || name == "config" {
+			return fmt.Errorf("invalid preset %q: cannot set -%s", o.preset, name)
+		}
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("invalid preset %q: no flag -%s", o.preset, name)
+		}
+		if explicit[name] {
+			continue
+		}
+		if err := fs.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("invalid preset %q: -%s: %v", o.preset, name, err)
+		}
+	}
+	return nil
+}
+
+// loadConfig reads the -config file once, returning the cached config on
+// later calls.
+func (o *reportOptions) loadConfig() (*Config, error) {
+	if o.loaded == nil {
+		config, err := loadConfig(o.config)
+		if err != nil {
+			return nil, withExitCode(exitConfig, err)
+		}
+		o.loaded = config
+	}
+	return o.loaded, nil
+}
+
+// summaryFilters compiles the -exclude and -include-only expressions, leaving
+// unset ones nil.
+func (o *reportOptions) summaryFilters() (exclude, includeOnly *regexp.Regexp, err error) {
+	if o.exclude != "" {
+		if exclude, err = regexp.Compile(o.exclude); err != nil {
+			return nil, nil, fmt.Errorf("invalid -exclude: %v", err)
+		}
+	}
+	if o.includeOnly != "" {
+		if includeOnly, err = regexp.Compile(o.includeOnly); err != nil {
+			return nil, nil, fmt.Errorf("invalid -include-only: %v", err)
+		}
+	}
+	return exclude, includeOnly, nil
+}
+
+// reportDate returns midnight of the -date in the -tz timezone, which must be
+// a single day.
+func (o *reportOptions) reportDate() (time.Time, error) {
+	from, days, err := o.reportRange()
+	if err != nil {
+		return from, err
+	}
+	if days > 1 {
+		return from, fmt.Errorf("-date %s is a range, this command needs a single day", o.date)
+	}
+	return from, nil
+}
+
+// reportRange returns midnight of the first day of the -date in the -tz
+// timezone, and how many days it covers.
+func (o *reportOptions) reportRange() (time.Time, int, error) {
+	loc, err := o.location()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	now := time.Now().In(loc)
+	if o.date == "" {
+		o.date = now.Format(dateLayout)
+	}
+	return parseDate(o.date, now)
+}
+
+// location is the -tz timezone, the local one if unset.
+func (o *reportOptions) location() (*time.Location, error) {
+	if o.tz == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(o.tz)
+}
+
+var relativeDatePattern = regexp.MustCompile(`^([+-]\d+)([dw])$`)
+
+// parseDate resolves a -date relative to now, returning midnight of its first
+// day and how many days it covers. Besides YYYY-MM-DD it takes today,
+// yesterday, tomorrow, a weekday name for the last such day, offsets like -3d
+// or +1w, and the ranges this-week and last-week (Monday to Sunday).
+func parseDate(value string, now time.Time) (time.Time, int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch value = strings.ToLower(value); value {
+	case "today":
+		return today, 1, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), 1, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), 1, nil
+	case "this-week":
+		return startOfWeek(today), 7, nil
+	case "last-week":
+		return startOfWeek(today).AddDate(0, 0, -7), 7, nil
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if value == strings.ToLower(day.String()) {
+			back := (int(today.Weekday()) - int(day) + 7) % 7
+			return today.AddDate(0, 0, -back), 1, nil
+		}
+	}
+
+	if m := relativeDatePattern.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "w" {
+			n *= 7
+		}
+		return today.AddDate(0, 0, n), 1, nil
+	}
+
+	date, err := time.ParseInLocation(dateLayout, value, now.Location())
+	if err != nil {
+		return date, 0, fmt.Errorf("invalid -date %q: must be YYYY-MM-DD, today, yesterday, a weekday, an offset like -3d, this-week or last-week", value)
+	}
+	return date, 1, nil
+}
+
+// defaultRequestTimeout bounds each HTTP request unless -request-timeout says
+// otherwise.
+const defaultRequestTimeout = time.Minute
+
+// httpClient makes the requests to the export targets and on-call services,
+// and the OAuth token requests.
+var httpClient = &http.Client{Timeout: defaultRequestTimeout}
+
+// service returns a Calendar API client authorized for the command's scopes.
+// With -input there is none unless the command writes to the calendar.
+func (o *reportOptions) service(ctx context.Context) (*calendar.Service, error) {
+	if o.input != "" && len(o.scopes) == 0 {
+		return nil, nil
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	scopes := o.scopes
+	if o.freeBusy {
+		scopes = append([]string{freeBusyScope}, scopes...)
+	}
+	oauth2Client, err := authenticateClient(ctx, o.credentials, o.token, o.login, scopes...)
+	if err != nil {
+		return nil, withExitCode(exitAuth, err)
+	}
+	oauth2Client.Timeout = o.requestTimeout
+	return calendar.NewService(ctx, option.WithHTTPClient(oauth2Client))
+}
+
+// fetch lists the events overlapping from-to from the -input, the busy times
+// with -free-busy, or the calendar's events.
+func (o *reportOptions) fetch(ctx context.Context, calendarService *calendar.Service, from, to time.Time) ([]*Event, error) {
+	switch {
+	case o.input != "":
+		return o.fetchInput(from, to)
+	case o.freeBusy:
+		return fetchBusy(ctx, calendarService, o.calendar, from, to)
+	}
+	if ids := o.calendarIDs(); len(ids) > 1 {
+		config, err := o.loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		return fetchCalendars(ctx, calendarService, ids, config.CalendarOwners, from, to)
+	}
+	return fetchEvents(ctx, calendarService, o.calendar, from, to)
+}
+
+// calendarIDs are the calendars of the -calendar list.
+func (o *reportOptions) calendarIDs() []string {
+	var ids []string
+	for _, id := range strings.Split(o.calendar, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// primaryCalendar is the ID of the signed in user's own calendar.
+const primaryCalendar = "primary"
+
+// fetchEvents lists the events of the calendar overlapping from-to in start
+// time order. Events of a calendar other than your primary one are seen as
+// its owner, calendarID, sees them. All-day events' dates are read in from's
+// location.
+func fetchEvents(ctx context.Context, calendarService *calendar.Service, calendarID string, from, to time.Time) ([]*Event, error) {
+	items, err := listEvents(ctx, calendarService, calendarID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if calendarID != primaryCalendar {
+		attendAs(items, calendarID)
+	}
+	return fromCalendar(items, calendarID, from.Location()), nil
+}
+
+// listEvents lists the events of the calendar overlapping from-to in start
+// time order, as the API returns them.
+func listEvents(ctx context.Context, calendarService *calendar.Service, calendarID string, from, to time.Time) ([]*calendar.Event, error) {
+	var items []*calendar.Event
+	err := calendarService.Events.List(calendarID).
+		ShowDeleted(false).
+		SingleEvents(true).
+		TimeMin(from.Format(time.RFC3339)).
+		TimeMax(to.Format(time.RFC3339)).
+		OrderBy("startTime").
+		Pages(ctx, func(page *calendar.Events) error {
+			items = append(items, page.Items...)
+			return nil
+		})
+	if err != nil {
+		return nil, withExitCode(exitAPI, fmt.Errorf("error fetching the calendar events: %w", err))
+	}
+	return items, nil
+}
+
+// fromCalendar converts the events fetched from the calendar, noting where
+// they came from.
+func fromCalendar(items []*calendar.Event, calendarID string, loc *time.Location) []*Event {
+	events := fromGoogleAll(items, loc)
+	for _, e := range events {
+		e.Calendar = calendarID
+		slog.Debug("fetched event", "calendar", calendarID, "summary", e.Summary, "start", e.Start, "end", e.End, "allDay", e.AllDay, "status", e.Status)
+	}
+	return events
+}
+
+// attendAs marks email's attendee, creator and organizer entries of the events
+// as Self rather than the signed in user's, so their attendance is resolved
+// from email's responses.
+func attendAs(items []*calendar.Event, email string) {
+	for _, e := range items {
+		for _, attendee := range e.Attendees {
+			attendee.Self = strings.EqualFold(attendee.Email, email)
+		}
+		if e.Creator != nil {
+			e.Creator.Self = strings.EqualFold(e.Creator.Email, email)
+		}
+		if e.Organizer != nil {
+			e.Organizer.Self = strings.EqualFold(e.Organizer.Email, email)
+		}
+	}
+}
+
+// build fetches the -date's calendar events and turns them into chunks.
+func (o *reportOptions) build(ctx context.Context) (time.Time, []*Chunk, error) {
+	date, err := o.reportDate()
+	if err != nil {
+		return date, nil, err
+	}
+	if err := o.validate(); err != nil {
+		return date, nil, err
+	}
+	calendarService, err := o.service(ctx)
+	if err != nil {
+		return date, nil, err
+	}
+	chunks, err := o.buildDay(ctx, calendarService, date)
+	return date, chunks, err
+}
+
+// validate checks the flags build relies on.
+func (o *reportOptions) validate() error {
+	switch o.tentative {
+	case tentativeInclude, tentativeExclude, tentativeFlag:
+	default:
+		return withExitCode(exitUsage, fmt.Errorf("invalid -tentative %q: must be include, exclude or flag", o.tentative))
+	}
+	switch o.timeFormat {
+	case timeDecimal, timeClock, timeISO, timeDuration:
+	default:
+		return withExitCode(exitUsage, fmt.Errorf("invalid -time-format %q: must be decimal, hh:mm, iso8601 or duration", o.timeFormat))
+	}
+	if o.freeBusy && len(o.calendarIDs()) > 1 {
+		return withExitCode(exitUsage, fmt.Errorf("-free-busy reads a single -calendar"))
+	}
+	_, _, err := o.summaryFilters()
+	return withExitCode(exitUsage, err)
+}
+
+// buildDays builds the chunks of the days from from, in date order. Up to
+// -workers days are fetched at once, starting at most -rate-limit a second,
+// and the first failure cancels the rest.
+func (o *reportOptions) buildDays(ctx context.Context, calendarService *calendar.Service, from time.Time, days int) ([][]*Chunk, error) {
+	// fill the caches buildDay would, before the workers share them
+	if _, err := o.loadConfig(); err != nil {
+		return nil, err
+	}
+	if o.input != "" {
+		if _, err := o.fetchInput(from, from); err != nil {
+			return nil, err
+		}
+	}
+	o.absences()
+	o.fingerprints()
+
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var limiter <-chan time.Time
+	if o.rateLimit > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / o.rateLimit))
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+
+	var (
+		chunks   = make([][]*Chunk, days)
+		next     = make(chan int)
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < max(o.workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				day, err := o.buildDay(workCtx, calendarService, from.AddDate(0, 0, i))
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					continue
+				}
+				chunks[i] = day
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < days; i++ {
+		if limiter != nil && i > 0 {
+			select {
+			case <-limiter:
+			case <-workCtx.Done():
+				break feed
+			}
+		}
+		select {
+		case next <- i:
+		case <-workCtx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// buildDay turns the calendar events of the day starting at date into chunks.
+func (o *reportOptions) buildDay(ctx context.Context, calendarService *calendar.Service, date time.Time) ([]*Chunk, error) {
+	config, err := o.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := o.fetch(ctx, calendarService, date, date.Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	o.fingerprints().set(date, fingerprint(items))
+
+	if o.exclude != "" || o.includeOnly != "" {
+		exclude, includeOnly, err := o.summaryFilters()
+		if err != nil {
+			return nil, err
+		}
+		items = filterSummaries(items, exclude, includeOnly)
+	}
+	if len(config.Colors) > 0 {
+		items = filterColors(items, config.Colors)
+	}
+	if o.attributeStart {
+		items = startedOn(date, items)
+	}
+	absence := absenceOf(items, config.Absences)
+	if lo, hi := config.Workdays.window(date); absence == "" && outOfOffice(items, lo, hi) {
+		absence = absenceOutOfOffice
+	}
+	if absence != "" {
+		o.absences().set(date, absence)
+		return nil, nil
+	}
+
+	chunks := Chunkify(date, items, Options{
+		ClampWorkday:   o.clampWorkday,
+		KeepOvernight:  o.attributeStart,
+		Tentative:      o.tentative,
+		AllDay:         config.AllDay,
+		Precedence:     config.Precedence,
+		Umbrella:       config.Umbrella,
+		AttendUnlisted: o.attendUnlisted,
+		Schedule:       config.Workdays,
+	})
+	if o.includeOnly != "" {
+		for _, chunk := range chunks {
+			if chunk.Event != nil {
+				chunk.explain("matched -include-only %q", o.includeOnly)
+			}
+		}
+	}
+	assignColorProjects(chunks, config.Colors)
+	tagChunks(chunks, config.Clients)
+	assignDomainProjects(chunks, config.AttendeeDomains, config.InternalDomains)
+	tagLocations(chunks, items)
+	store, err := openStore(o.history)
+	if err != nil {
+		return nil, err
+	}
+	applyOverrides(chunks, store.Overrides[date.Format(dateLayout)])
+	store.enrich(date, chunks)
+	fillGaps(chunks, config.Gap)
+	attachEventLinks(chunks)
+	classifyBillable(chunks, config.Billable)
+	classifyCapex(chunks, config.Capex)
+
+	// attribute firefighting between meetings to the incidents
+	if o.pagerDutyIncidents {
+		incidents, err := fetchPagerDutyIncidents(ctx, os.Getenv("PAGERDUTY_TOKEN"), o.pagerDutyUser, date, date.Add(24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		chunks = overlayIncidents(chunks, incidents)
+	}
+
+	// tag chunks worked during on-call shifts for differential pay
+	var onCall []window
+	if o.pagerDutyUser != "" {
+		windows, err := fetchPagerDutyOnCall(ctx, os.Getenv("PAGERDUTY_TOKEN"), o.pagerDutyUser, date, date.Add(24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		onCall = append(onCall, windows...)
+	}
+	if o.opsgenieSchedule != "" {
+		windows, err := fetchOpsgenieOnCall(ctx, os.Getenv("OPSGENIE_API_KEY"), o.opsgenieSchedule, o.opsgenieUser, date, date.Add(24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		onCall = append(onCall, windows...)
+	}
+	if o.tracksOnCall() {
+		chunks = tagOnCall(chunks, onCall)
+	}
+
+	// tidy up slivers left by rounding and splitting
+	if o.merge {
+		chunks = mergeAdjacent(chunks)
+	}
+	if o.minDuration > 0 {
+		chunks = dropShort(chunks, o.minDuration)
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func Test_reportOptions_parse(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(configPath, []byte(`{
+		"presets": {
+			"client-acme": {"tentative": "exclude", "clamp-workday": true, "tz": "Europe/Berlin"},
+			"broken": {"no-such-flag": 1}
+		}
+	}`), 0600)
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectErr    bool
+		tentative    string
+		clampWorkday bool
+		tz           string
+	}{
+		{
+			name:      "without a preset",
+			args:      []string{"-config", configPath},
+			tentative: tentativeInclude,
+		},
+		{
+			name:         "applies the preset",
+			args:         []string{"-config", configPath, "-preset", "client-acme"},
+			tentative:    tentativeExclude,
+			clampWorkday: true,
+			tz:           "Europe/Berlin",
+		},
+		{
+			name:         "explicit flags win over the preset",
+			args:         []string{"-config", configPath, "-preset", "client-acme", "-tz", "UTC"},
+			tentative:    tentativeExclude,
+			clampWorkday: true,
+			tz:           "UTC",
+		},
+		{
+			name:      "rejects unknown presets",
+			args:      []string{"-config", configPath, "-preset", "client-globex"},
+			expectErr: true,
+		},
+		{
+			name:      "rejects presets setting unknown flags",
+			args:      []string{"-config", configPath, "-preset", "broken"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			opts := &reportOptions{}
+			opts.register(fs)
+
+			err := opts.parse(fs, test.args)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if opts.tentative != test.tentative || opts.clampWorkday != test.clampWorkday || opts.tz != test.tz {
+				t.Errorf("expected tentative '%s', clamp-workday %t and tz '%s', got '%s', %t and '%s'",
+					test.tentative, test.clampWorkday, test.tz, opts.tentative, opts.clampWorkday, opts.tz)
+			}
+		})
+	}
+}
+
+func Test_parseDate(t *testing.T) {
+	now := time.Date(2024, 3, 13, 15, 4, 0, 0, time.UTC) // a Wednesday
+
+	tests := []struct {
+		value        string
+		expectedFrom string
+		expectedDays int
+	}{
+		{value: "2024-02-29", expectedFrom: "2024-02-29", expectedDays: 1},
+		{value: "today", expectedFrom: "2024-03-13", expectedDays: 1},
+		{value: "yesterday", expectedFrom: "2024-03-12", expectedDays: 1},
+		{value: "Monday", expectedFrom: "2024-03-11", expectedDays: 1},
+		{value: "wednesday", expectedFrom: "2024-03-13", expectedDays: 1},
+		{value: "thursday", expectedFrom: "2024-03-07", expectedDays: 1},
+		{value: "-3d", expectedFrom: "2024-03-10", expectedDays: 1},
+		{value: "+1w", expectedFrom: "2024-03-20", expectedDays: 1},
+		{value: "this-week", expectedFrom: "2024-03-11", expectedDays: 7},
+		{value: "last-week", expectedFrom: "2024-03-04", expectedDays: 7},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			from, days, err := parseDate(test.value, now)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if from.Format(dateLayout) != test.expectedFrom || days != test.expectedDays {
+				t.Errorf("expected %s for %d days, got %s for %d days", test.expectedFrom, test.expectedDays, from.Format(dateLayout), days)
+			}
+		})
+	}
+
+	if _, _, err := parseDate("next tuesday", now); err == nil {
+		t.Errorf("expected an error for an unknown date")
+	}
+}
+
+func Test_reportOptions_reportRange_timezone(t *testing.T) {
+	// pin the local timezone, as TZ=Europe/Berlin would
+	berlin := time.FixedZone("CET", 60*60)
+	local := time.Local
+	time.Local = berlin
+	t.Cleanup(func() { time.Local = local })
+
+	tests := []struct {
+		tz       string
+		expected *time.Location
+	}{
+		{tz: "", expected: berlin},
+		{tz: "UTC", expected: time.UTC},
+	}
+	for _, test := range tests {
+		opts := &reportOptions{date: "2024-03-11", tz: test.tz}
+		from, _, err := opts.reportRange()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if from.Location() != test.expected {
+			t.Errorf("expected -tz %q to report in %s, got %s", test.tz, test.expected, from.Location())
+		}
+	}
+}
+
+func Test_attendAs(t *testing.T) {
+	e := &calendar.Event{
+		Creator:   &calendar.EventCreator{Email: "ea@example.com", Self: true},
+		Organizer: &calendar.EventOrganizer{Email: "Exec@example.com"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "ea@example.com", Self: true, ResponseStatus: "accepted"},
+			{Email: "exec@example.com", ResponseStatus: "declined"},
+		},
+	}
+
+	attendAs([]*calendar.Event{e}, "exec@example.com")
+
+	if e.Creator.Self || !e.Organizer.Self {
+		t.Errorf("expected only the organizer to be self, got creator %t and organizer %t", e.Creator.Self, e.Organizer.Self)
+	}
+	if e.Attendees[0].Self || !e.Attendees[1].Self {
+		t.Errorf("expected only exec@example.com to be self")
+	}
+	if selfAttending(fromGoogle(e, time.UTC)) {
+		t.Errorf("expected the event declined by exec@example.com not to be attended")
+	}
+}
+
+func Test_reportOptions_buildDays(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.json")
+	var events []string
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 31; i++ {
+		day := from.AddDate(0, 0, i).Format(dateLayout)
+		events = append(events, `{"summary": "`+day+`", "start": "`+day+`T10:00:00Z", "end": "`+day+`T11:00:00Z"}`)
+	}
+	if err := os.WriteFile(path, []byte("["+strings.Join(events, ",")+"]"), 0600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	opts := &reportOptions{input: path, config: filepath.Join(dir, "config.json"), history: filepath.Join(dir, "history.json"), tentative: tentativeInclude, workers: 8}
+	days, err := opts.buildDays(context.Background(), nil, from, 31)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(days) != 31 {
+		t.Fatalf("expected 31 days, got %d", len(days))
+	}
+	for i, chunks := range days {
+		date := from.AddDate(0, 0, i)
+		expected := date.Format(dateLayout)
+		// weekends are off, so their events get no gaps around them
+		event := 1
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			event = 0
+		}
+		if len(chunks) != 2*event+1 || chunks[event].notes != expected {
+			t.Errorf("expected day %d to be %s's, got %d chunks", i, expected, len(chunks))
+		}
+	}
+
+	opts = &reportOptions{input: filepath.Join(dir, "missing.json"), config: opts.config, history: opts.history, tentative: tentativeInclude, workers: 8}
+	if _, err := opts.buildDays(context.Background(), nil, from, 31); err == nil {
+		t.Errorf("expected an error for missing events")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runRollup implements the `rollup` subcommand, totalling the recorded hours
+// of a fiscal quarter per project and month.
+func runRollup(args []string) {
+	fs := flag.NewFlagSet("rollup", flag.ExitOnError)
+	opts := &rollupOptions{}
+	opts.register(fs)
+	fs.Parse(args)
+
+	r, err := opts.rollUp()
+	if err != nil {
+		fatal(err)
+	}
+	if err := opts.write(r, r.csv()); err != nil {
+		fatal(err)
+	}
+}
+
+// rollupOptions are the flags of the subcommands rolling up the history of
+// a fiscal quarter.
+type rollupOptions struct {
+	historyPath string
+	configPath  string
+	quarter     string
+	format      string
+}
+
+func (o *rollupOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.historyPath, "history", filepath.Join(dataDir(), "history.json"), "Path to the local report history")
+	fs.StringVar(&o.configPath, "config", filepath.Join(configDir(), "config.json"), "Path to the optional JSON config file")
+	fs.StringVar(&o.quarter, "quarter", "", "Fiscal quarter to roll up, e.g. FY24Q3 or FY2024-Q3")
+	fs.StringVar(&o.format, "format", formatCSV, "Output format: csv or json")
+}
+
+// rollUp validates the flags and rolls up the -quarter.
+func (o *rollupOptions) rollUp() (*rollup, error) {
+	fy, q, err := parseQuarter(o.quarter)
+	if err != nil {
+		return nil, err
+	}
+	if o.format != formatCSV && o.format != "json" {
+		return nil, fmt.Errorf("invalid -format %q: must be csv or json", o.format)
+	}
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, withExitCode(exitConfig, err)
+	}
+	store, err := openStore(o.historyPath)
+	if err != nil {
+		return nil, err
+	}
+	return rollUp(store, config.Fiscal, fy, q), nil
+}
+
+// write prints v as JSON, or the csv rendering of it.
+func (o *rollupOptions) write(v any, csv string) error {
+	if o.format != "json" {
+		_, err := fmt.Print(csv)
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+var quarterPattern = regexp.MustCompile(`^FY(\d{2}|\d{4})-?Q([1-4])$`)
+
+// parseQuarter parses a fiscal quarter like FY24Q3 or FY2024-Q3.
+func parseQuarter(value string) (fy, quarter int, err error) {
+	m := quarterPattern.FindStringSubmatch(strings.ToUpper(value))
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid -quarter %q: must be like FY24Q3", value)
+	}
+	fy, _ = strconv.Atoi(m[1])
+	if fy < 100 {
+		fy += 2000
+	}
+	quarter, _ = strconv.Atoi(m[2])
+	return fy, quarter, nil
+}
+
+// rollup is a fiscal quarter's recorded hours per project, in total and per
+// fiscal month. Projects are keyed by name, "" for time without one.
+type rollup struct {
+	Quarter  string             `json:"quarter"`
+	Days     int                `json:"days"`
+	Total    float64            `json:"total"`
+	Projects map[string]float64 `json:"projects"`
+	Capex    map[string]float64 `json:"capex"` // the capitalizable part
+	Months   []rollupMonth      `json:"months"`
+}
+
+type rollupMonth struct {
+	Period   string             `json:"period"`
+	Projects map[string]float64 `json:"projects"`
+	Capex    map[string]float64 `json:"capex"`
+}
+
+func rollUp(store *Store, fiscal FiscalCalendar, fy, quarter int) *rollup {
+	r := &rollup{
+		Quarter:  fmt.Sprintf("FY%d-Q%d", fy, quarter),
+		Projects: map[string]float64{},
+		Capex:    map[string]float64{},
+		Months:   []rollupMonth{},
+	}
+	for _, date := range store.Dates() {
+		d, err := time.Parse(dateLayout, date)
+		if err != nil {
+			continue
+		}
+		if fiscal.label(d, true) != r.Quarter {
+			continue
+		}
+
+		day := store.Days[date]
+		projects := day.Projects
+		if projects == nil {
+			// recorded before projects were
+			projects = map[string]float64{"": day.Hours}
+		}
+		period := fiscal.label(d, false)
+		if len(r.Months) == 0 || r.Months[len(r.Months)-1].Period != period {
+			r.Months = append(r.Months, rollupMonth{Period: period, Projects: map[string]float64{}, Capex: map[string]float64{}})
+		}
+		month := r.Months[len(r.Months)-1]
+		for project, hours := range projects {
+			month.Projects[project] += hours
+			r.Projects[project] += hours
+		}
+		for project, hours := range day.Capex {
+			month.Capex[project] += hours
+			r.Capex[project] += hours
+		}
+		r.Days++
+		r.Total += day.Hours
+	}
+	return r
+}
+
+// csv renders the rollup with a row per month and project, then the
+// quarter's rows.
+func (r *rollup) csv() string {
+	buf := strings.Builder{}
+	buf.WriteString("period,project,hours\n")
+	for _, month := range r.Months {
+		for _, project := range sortedProjects(month.Projects) {
+			buf.WriteString(fmt.Sprintf("%s,%s,%.2f\n", month.Period, project, month.Projects[project]))
+		}
+	}
+	for _, project := range sortedProjects(r.Projects) {
+		buf.WriteString(fmt.Sprintf("%s,%s,%.2f\n", r.Quarter, project, r.Projects[project]))
+	}
+
+	return fmt.Sprintf(`
+Rollup of %s over %d recorded days: a total of %.2f hours.
+
+%s`,
+		r.Quarter,
+		r.Days,
+		r.Total,
+		buf.String(),
+	)
+}
+
+func sortedProjects(projects map[string]float64) []string {
+	names := make([]string, 0, len(projects))
+	for name := range projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_parseQuarter(t *testing.T) {
+	tests := []struct {
+		value   string
+		fy, q   int
+		wantErr bool
+	}{
+		{value: "FY24Q3", fy: 2024, q: 3},
+		{value: "FY2024-Q1", fy: 2024, q: 1},
+		{value: "fy25q4", fy: 2025, q: 4},
+		{value: "FY24Q5", wantErr: true},
+		{value: "2024Q1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			fy, q, err := parseQuarter(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if fy != tt.fy || q != tt.q {
+				t.Errorf("expected FY%d Q%d, got FY%d Q%d", tt.fy, tt.q, fy, q)
+			}
+		})
+	}
+}
+
+func Test_rollUp(t *testing.T) {
+	store := &Store{Days: map[string]*DayRecord{
+		"2024-06-28": {Hours: 8, Projects: map[string]float64{"ACME": 8}},
+		"2024-07-01": {Hours: 8, Projects: map[string]float64{"ACME": 6, "": 2}},
+		"2024-08-01": {Hours: 7},
+		"2024-09-30": {Hours: 4, Projects: map[string]float64{"Internal": 4}},
+		"2024-10-01": {Hours: 8, Projects: map[string]float64{"ACME": 8}},
+	}}
+
+	r := rollUp(store, FiscalCalendar{}, 2024, 3)
+
+	if r.Days != 3 || r.Total != 19 {
+		t.Errorf("expected 3 days and 19 hours, got %d days and %.2f hours", r.Days, r.Total)
+	}
+	if r.Projects["ACME"] != 6 || r.Projects[""] != 9 || r.Projects["Internal"] != 4 {
+		t.Errorf("expected ACME 6, unassigned 9 and Internal 4, got %v", r.Projects)
+	}
+	if len(r.Months) != 3 {
+		t.Fatalf("expected 3 months, got %d", len(r.Months))
+	}
+	for _, line := range []string{
+		"FY2024-P07,ACME,6.00\n",
+		"FY2024-P08,,7.00\n",
+		"FY2024-Q3,Internal,4.00\n",
+	} {
+		if output := r.csv(); !strings.Contains(output, line) {
+			t.Errorf("expected the rollup to contain %q, got:\n%s", line, output)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// runSite implements the `site` subcommand, rendering a month into a static
+// site to hand to a client: an index with the month's totals, and a page
+// with a timeline and table plus a CSV for each day.
+func runSite(args []string) {
+	fs := flag.NewFlagSet("site", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	month := fs.String("month", "", "Month to render as YYYY-MM (default last month)")
+	dir := fs.String("o", "report", "Directory to write the site to")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+
+	if err := opts.validate(); err != nil {
+		fatal(err)
+	}
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	loc, err := opts.location()
+	if err != nil {
+		fatal(err)
+	}
+	from, err := parseMonth(*month, time.Now().In(loc))
+	if err != nil {
+		fatal(err)
+	}
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+
+	var reports []*Report
+	for date := from; date.Before(from.AddDate(0, 1, 0)); date = date.AddDate(0, 0, 1) {
+		chunks, err := opts.buildDay(ctx, calendarService, date)
+		if err != nil {
+			fatal(err)
+		}
+		reports = append(reports, newReport(date, chunks, opts.columns(config)))
+	}
+
+	if err := writeSite(*dir, from, reports, opts.timeFormat); err != nil {
+		fatal(err)
+	}
+	fmt.Printf("Wrote the site for %s to %s.\n", from.Format("January 2006"), *dir)
+}
+
+// siteIndex is what the index page is executed with.
+type siteIndex struct {
+	Month    time.Time
+	Days     []*Report
+	Worked   int // days with any time
+	Total    time.Duration
+	Projects []siteProject
+}
+
+type siteProject struct {
+	Name  string
+	Total time.Duration
+	Color string // CSS color
+}
+
+// writeSite writes the index, and a page and CSV per day, to dir.
+func writeSite(dir string, month time.Time, reports []*Report, timeFormat string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating the site directory: %v", err)
+	}
+	tmpl, err := siteTemplate(timeFormat)
+	if err != nil {
+		return err
+	}
+
+	index := siteIndex{Month: month, Days: reports, Projects: projectTotals(reports)}
+	for _, r := range reports {
+		day := r.Date.Format(dateLayout)
+		if err := os.WriteFile(filepath.Join(dir, day+".csv"), []byte(r.table(timeFormat)), 0644); err != nil {
+			return fmt.Errorf("error writing the site: %v", err)
+		}
+		if err := writeSitePage(tmpl, filepath.Join(dir, day+".html"), "day", r); err != nil {
+			return err
+		}
+		if r.Total > 0 {
+			index.Worked++
+		}
+		index.Total += r.Total
+	}
+	return writeSitePage(tmpl, filepath.Join(dir, "index.html"), "index", index)
+}
+
+// siteTemplate parses the pages shared by the site and -format html.
+func siteTemplate(timeFormat string) (*template.Template, error) {
+	tmpl, err := template.New("site").Funcs(template.FuncMap{
+		"time":     func(date, t time.Time) string { return formatTimeAs(timeFormat, date, t) },
+		"hours":    func(d time.Duration) string { return formatHours(timeFormat, d) },
+		"day":      func(date time.Time) string { return date.Format(dateLayout) },
+		"timeline": timelineStyle,
+	}).Parse(siteTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the site templates: %v", err)
+	}
+	return tmpl, nil
+}
+
+// projectTotals totals the reports' time per project, the most first.
+func projectTotals(reports []*Report) []siteProject {
+	totals := map[string]time.Duration{}
+	colors := map[string]string{}
+	for _, r := range reports {
+		for _, row := range r.Rows {
+			totals[row.Project] += row.Duration()
+			if c, ok := findColor(row.Color); ok {
+				colors[row.Project] = c.css
+			}
+		}
+	}
+	projects := make([]siteProject, 0, len(totals))
+	for project, total := range totals {
+		projects = append(projects, siteProject{Name: project, Total: total, Color: colors[project]})
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Total > projects[j].Total })
+	return projects
+}
+
+func writeSitePage(tmpl *template.Template, path, name string, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error writing the site: %v", err)
+	}
+	defer f.Close()
+	if err := tmpl.ExecuteTemplate(f, name, data); err != nil {
+		return fmt.Errorf("error executing the site template: %v", err)
+	}
+	return nil
+}
+
+// timelineStyle places a row on its day's timeline, which runs from the
+// first row's start to the last one's end.
+func timelineStyle(r *Report, row Row) template.CSS {
+	from, to := r.Rows[0].Start, r.Rows[len(r.Rows)-1].End
+	span := to.Sub(from).Seconds()
+	left := row.Start.Sub(from).Seconds() / span * 100
+	width := row.Duration().Seconds() / span * 100
+	color := "#ddd"
+	if c, ok := findColor(row.Color); ok {
+		color = c.css
+	} else if !row.Gap {
+		color = "#999"
+	}
+	return template.CSS(fmt.Sprintf("left: %.2f%%; width: %.2f%%; background: %s", left, width, color))
+}
+
+const siteTemplates = `
+{{define "head"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.3em 0.6em; border-bottom: 1px solid #ccc; text-align: left; }
+.timeline { position: relative; height: 2em; margin: 1em 0; background: #f5f5f5; }
+.timeline div { position: absolute; top: 0; bottom: 0; border-right: 1px solid #fff; }
+.swatch { display: inline-block; width: 0.8em; height: 0.8em; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "index"}}{{template "head" (.Month.Format "January 2006")}}<h1>{{.Month.Format "January 2006"}}</h1>
+<p>{{hours .Total}} over {{.Worked}} days.</p>
+<h2>Projects</h2>
+<table>
+<tr><th>Project</th><th>Time</th></tr>
+{{range .Projects}}<tr><td>{{if .Color}}<span class="swatch" style="background: {{.Color}}"></span> {{end}}{{or .Name "(none)"}}</td><td>{{hours .Total}}</td></tr>
+{{end}}</table>
+<h2>Days</h2>
+<table>
+<tr><th>Date</th><th>Time</th><th></th></tr>
+{{range .Days}}<tr><td><a href="{{day .Date}}.html">{{.Date.Format "Mon Jan 2"}}</a></td><td>{{hours .Total}}</td><td><a href="{{day .Date}}.csv">CSV</a></td></tr>
+{{end}}</table>
+</body>
+</html>
+{{end}}
+
+{{define "day"}}{{template "head" (day .Date)}}<h1>{{.Date.Format "Monday, January 2 2006"}}</h1>
+<p>{{hours .Total}}. <a href="index.html">Back to the month</a> · <a href="{{day .Date}}.csv">CSV</a></p>
+{{template "chunks" .}}</body>
+</html>
+{{end}}
+
+{{define "chunks"}}{{$r := .}}{{if .Rows}}<div class="timeline">{{range .Rows}}<div style="{{timeline $r .}}" title="{{time $r.Date .Start}}-{{time $r.Date .End}} {{.Notes}}"></div>{{end}}</div>
+<table>
+<tr><th>Start</th><th>End</th><th>Time</th>{{if .Notes}}<th>Notes</th>{{end}}{{if .Project}}<th>Project</th>{{end}}{{if .Linked}}<th>Links</th>{{end}}</tr>
+{{range .Rows}}<tr><td>{{time $r.Date .Start}}</td><td>{{time $r.Date .End}}</td><td>{{hours .Duration}}</td>{{if $r.Notes}}<td>{{.Notes}}</td>{{end}}{{if $r.Project}}<td>{{.Project}}</td>{{end}}{{if $r.Linked}}<td>{{range .Links}}<a href="{{.}}">{{.}}</a> {{end}}</td>{{end}}</tr>
+{{end}}</table>
+{{else}}<p>Nothing recorded.</p>
+{{end}}{{end}}
+`
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_writeSite(t *testing.T) {
+	dir := t.TempDir()
+	month := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	reports := []*Report{
+		newReport(month, testChunks(month), reportColumns{notes: true, project: true}),
+		newReport(month.AddDate(0, 0, 1), nil, reportColumns{notes: true}),
+	}
+
+	if err := writeSite(dir, month, reports, timeClock); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string][]string{
+		"index.html":      {"May 2024", "2:30 over 1 days.", `<a href="2024-05-01.html">`, "ACME", `<a href="2024-05-02.csv">`},
+		"2024-05-01.html": {"Wednesday, May 1 2024", "left: 0.00%; width: 40.00%; background: " + projectColor("ACME", nil).css, "<td>Standup</td>"},
+		"2024-05-01.csv":  {"start,end,notes,project\n09:00,10:00,Standup,ACME\n"},
+		"2024-05-02.html": {"Nothing recorded."},
+	}
+	for name, contents := range expected {
+		bytes, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s to be written, got %v", name, err)
+		}
+		for _, s := range contents {
+			if !strings.Contains(string(bytes), s) {
+				t.Errorf("expected %s to contain %q, got:\n%s", name, s, bytes)
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runStats implements the `stats` subcommand, reporting on the local history,
+// or on the calendar's meeting load with -meetings.
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	flex := fs.Bool("flex", false, "Report the flex-time balance of recorded hours against the target")
+	target := fs.Float64("target", 8, "Target hours per recorded day, for the days recorded without the config's target")
+	by := fs.String("by", "", "Total the recorded hours by fiscal month or quarter, per the config's fiscal calendar, or count the days per working location and year")
+	meetings := fs.Bool("meetings", false, "Report the meeting and free hours, longest focus block and context switches of each day of -date")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+
+	if !*flex && *by == "" && !*meetings {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *meetings {
+		if err := opts.meetingStats(); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	store, err := openStore(opts.history)
+	if err != nil {
+		fatal(err)
+	}
+
+	if *by == "location" {
+		buf := strings.Builder{}
+		buf.WriteString("year,location,days,hours\n")
+		for _, row := range yearLocations(store) {
+			buf.WriteString(fmt.Sprintf("%s,%s,%d,%.2f\n", row.period, row.location, row.days, row.hours))
+		}
+		fmt.Printf("\nRecorded days by working location.\n\n%s", buf.String())
+		return
+	}
+	if *by != "" {
+		if *by != "month" && *by != "quarter" {
+			fatal(withExitCode(exitUsage, fmt.Errorf("invalid -by %q: must be month, quarter or location", *by)))
+		}
+		config, err := opts.loadConfig()
+		if err != nil {
+			fatal(err)
+		}
+		buf := strings.Builder{}
+		buf.WriteString("period,days,hours\n")
+		for _, row := range periodTotals(store, config.Fiscal, *by == "quarter") {
+			buf.WriteString(fmt.Sprintf("%s,%d,%.2f\n", row.period, row.days, row.hours))
+		}
+		fmt.Printf("\nRecorded hours by fiscal %s.\n\n%s", *by, buf.String())
+		return
+	}
+
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	rows := flexBalance(store, *target, config.Workdays)
+	buf := strings.Builder{}
+	buf.WriteString("date,hours,target,difference,balance,absence\n")
+	for _, row := range rows {
+		buf.WriteString(fmt.Sprintf("%s,%.2f,%.2f,%+.2f,%+.2f,%s\n", row.date, row.hours, row.target, row.hours-row.target, row.balance, row.absence))
+	}
+
+	balance := 0.0
+	if len(rows) > 0 {
+		balance = rows[len(rows)-1].balance
+	}
+
+	output := fmt.Sprintf(`
+Flex-time balance over %d recorded days: %+.2f hours.
+
+%s`,
+		len(rows),
+		balance,
+		buf.String(),
+	)
+	fmt.Print(output)
+}
+
+type flexRow struct {
+	date    string
+	hours   float64
+	target  float64
+	absence string
+	balance float64 // running total of hours over target up to this day
+}
+
+// flexBalance compares every recorded day with the target it was recorded
+// with, or else with target: none for absences and days the schedule
+// doesn't work.
+func flexBalance(store *Store, target float64, schedule Schedule) []flexRow {
+	rows := make([]flexRow, 0, len(store.Days))
+	balance := 0.0
+	for _, date := range store.Dates() {
+		row := flexRow{date: date, hours: store.Days[date].Hours, target: target, absence: store.Days[date].Absence}
+		d, err := time.Parse(dateLayout, date)
+		switch recorded := store.Days[date].Target; {
+		case recorded != nil:
+			row.target = *recorded
+		case row.absence != "" || (err == nil && !schedule.works(d.Weekday())):
+			row.target = 0
+		}
+		balance += row.hours - row.target
+		row.balance = balance
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+type periodRow struct {
+	period string
+	days   int
+	hours  float64
+}
+
+// periodTotals totals the recorded days by fiscal month or quarter, in
+// chronological order.
+func periodTotals(store *Store, fiscal FiscalCalendar, quarters bool) []periodRow {
+	var rows []periodRow
+	for _, date := range store.Dates() {
+		d, err := time.Parse(dateLayout, date)
+		if err != nil {
+			continue
+		}
+		period := fiscal.label(d, quarters)
+		if len(rows) == 0 || rows[len(rows)-1].period != period {
+			rows = append(rows, periodRow{period: period})
+		}
+		rows[len(rows)-1].days++
+		rows[len(rows)-1].hours += store.Days[date].Hours
+	}
+	return rows
+}
+
+// meetingStats prints the meeting load of each day of the -date, with weekly
+// totals and the busiest days.
+func (o *reportOptions) meetingStats() error {
+	from, days, err := o.reportRange()
+	if err != nil {
+		return err
+	}
+	if err := o.validate(); err != nil {
+		return err
+	}
+	config, err := o.loadConfig()
+	if err != nil {
+		return err
+	}
+	ctx := signalContext()
+	calendarService, err := o.service(ctx)
+	if err != nil {
+		return err
+	}
+
+	dayChunks, err := o.buildDays(ctx, calendarService, from, days)
+	if err != nil {
+		return err
+	}
+	rows := make([]loadRow, 0, days)
+	for i, chunks := range dayChunks {
+		rows = append(rows, meetingLoad(from.AddDate(0, 0, i), chunks, config.MeetingCost))
+	}
+
+	buf := strings.Builder{}
+	header := "period,meetings,free,longest_focus,context_switches"
+	if config.MeetingCost > 0 {
+		header += ",cost"
+	}
+	buf.WriteString(header + "\n")
+	write := func(row loadRow) {
+		line := fmt.Sprintf("%s,%s,%s,%s,%d", row.period, formatHours(o.timeFormat, row.meetings), formatHours(o.timeFormat, row.free), formatHours(o.timeFormat, row.focus), row.switches)
+		if config.MeetingCost > 0 {
+			line += fmt.Sprintf(",%.2f", row.cost)
+		}
+		buf.WriteString(line + "\n")
+	}
+	for _, row := range rows {
+		write(row)
+	}
+	if days > 1 {
+		for _, week := range weeklyLoad(rows) {
+			write(week)
+		}
+	}
+
+	busiest := make([]string, 0, 3)
+	for _, row := range busiestDays(rows, 3) {
+		busiest = append(busiest, fmt.Sprintf("%s (%s)", row.period, formatHours(o.timeFormat, row.meetings)))
+	}
+	summary := "no meetings"
+	if len(busiest) > 0 {
+		summary = "busiest days first: " + strings.Join(busiest, ", ")
+	}
+	fmt.Printf("\nMeeting load, %s.\n\n%s", summary, buf.String())
+	return nil
+}
+
+// loadRow is the meeting load of a day or week.
+type loadRow struct {
+	period   string
+	start    time.Time
+	meetings time.Duration
+	free     time.Duration
+	focus    time.Duration // the longest run of free time
+	switches int           // moves from one event or gap to another
+	cost     float64       // of the meetings, by their attendees
+}
+
+// meetingLoad sums up the day's chunks: event time is meetings, costing
+// meetingCost per attendee and hour, gaps are free, and adjacent gaps make one
+// focus block.
+func meetingLoad(date time.Time, chunks []*Chunk, meetingCost float64) loadRow {
+	row := loadRow{period: date.Format(dateLayout), start: date}
+	var block time.Duration
+	for i, chunk := range chunks {
+		d := chunk.end.Sub(chunk.start)
+		if chunk.Event == nil {
+			row.free += d
+			block += d
+			if block > row.focus {
+				row.focus = block
+			}
+		} else {
+			row.meetings += d
+			row.cost += float64(attendeeCount(chunk.Event)) * meetingCost * d.Hours()
+			block = 0
+		}
+		if i > 0 && !sameActivity(chunks[i-1], chunk) {
+			row.switches++
+		}
+	}
+	return row
+}
+
+// sameActivity reports whether two adjacent chunks are both free time or
+// both the same event.
+func sameActivity(a, b *Chunk) bool {
+	return a.Event == b.Event
+}
+
+// weeklyLoad totals the days by Monday to Sunday week, keeping the longest
+// focus block.
+func weeklyLoad(rows []loadRow) []loadRow {
+	var weeks []loadRow
+	for _, row := range rows {
+		start := startOfWeek(row.start)
+		if len(weeks) == 0 || !weeks[len(weeks)-1].start.Equal(start) {
+			weeks = append(weeks, loadRow{period: "week of " + start.Format(dateLayout), start: start})
+		}
+		week := &weeks[len(weeks)-1]
+		week.meetings += row.meetings
+		week.free += row.free
+		week.switches += row.switches
+		week.cost += row.cost
+		if row.focus > week.focus {
+			week.focus = row.focus
+		}
+	}
+	return weeks
+}
+
+// busiestDays are the n days with the most meetings, the busiest first.
+func busiestDays(rows []loadRow, n int) []loadRow {
+	busiest := make([]loadRow, 0, len(rows))
+	for _, row := range rows {
+		if row.meetings > 0 {
+			busiest = append(busiest, row)
+		}
+	}
+	sort.SliceStable(busiest, func(i, j int) bool { return busiest[i].meetings > busiest[j].meetings })
+	if len(busiest) > n {
+		busiest = busiest[:n]
+	}
+	return busiest
+}
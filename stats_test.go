@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_flexBalance(t *testing.T) {
+	store := &Store{Days: map[string]*DayRecord{
+		"2024-03-13": {Hours: 6.5},
+		"2024-03-11": {Hours: 9},
+		"2024-03-12": {Hours: 8.25},
+		"2024-03-14": {Absence: "pto"},
+		"2024-03-16": {Hours: 1}, // Saturday
+		"2024-03-18": {Hours: 7, Target: new(float64)},
+	}}
+
+	rows := flexBalance(store, 8, nil)
+
+	expected := []flexRow{
+		{date: "2024-03-11", hours: 9, target: 8, balance: 1},
+		{date: "2024-03-12", hours: 8.25, target: 8, balance: 1.25},
+		{date: "2024-03-13", hours: 6.5, target: 8, balance: -0.25},
+		{date: "2024-03-14", absence: "pto", balance: -0.25},
+		{date: "2024-03-16", hours: 1, balance: 0.75},
+		{date: "2024-03-18", hours: 7, balance: 7.75},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i, row := range rows {
+		if row != expected[i] {
+			t.Errorf("expected row %d to be %+v, got %+v", i, expected[i], row)
+		}
+	}
+}
+
+func Test_periodTotals(t *testing.T) {
+	store := &Store{Days: map[string]*DayRecord{
+		"2024-03-29": {Hours: 8},
+		"2024-04-01": {Hours: 6},
+		"2024-03-28": {Hours: 7.5},
+	}}
+
+	rows := periodTotals(store, FiscalCalendar{StartMonth: 4}, true)
+
+	expected := []periodRow{
+		{period: "FY2024-Q4", days: 2, hours: 15.5},
+		{period: "FY2025-Q1", days: 1, hours: 6},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i, row := range rows {
+		if row != expected[i] {
+			t.Errorf("expected row %d to be %+v, got %+v", i, expected[i], row)
+		}
+	}
+}
+
+func Test_meetingLoad(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	at := func(h float64) time.Time { return date.Add(time.Duration(h * float64(time.Hour))) }
+	standup := newEvent(at(9), at(9.5), "Standup", "accepted", true)
+	review := newEvent(at(12), at(13), "Review", "accepted", true)
+	chunks := []*Chunk{
+		{Event: standup, start: at(9), end: at(9.5)},
+		{start: at(9.5), end: at(10.5)},
+		{start: at(10.5), end: at(12), notes: "Standup"},
+		{Event: review, start: at(12), end: at(12.5)},
+		{Event: review, start: at(12.5), end: at(13)},
+		{start: at(13), end: at(17)},
+	}
+
+	row := meetingLoad(date, chunks, 100)
+
+	expected := loadRow{period: "2024-03-11", start: date, meetings: 90 * time.Minute, free: 390 * time.Minute, focus: 4 * time.Hour, switches: 3, cost: 150}
+	if row != expected {
+		t.Errorf("expected %+v, got %+v", expected, row)
+	}
+}
+
+func Test_weeklyLoad(t *testing.T) {
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	rows := []loadRow{
+		{period: "2024-03-10", start: monday.AddDate(0, 0, -1), meetings: time.Hour},
+		{period: "2024-03-11", start: monday, meetings: 3 * time.Hour, focus: time.Hour, switches: 4},
+		{period: "2024-03-12", start: monday.AddDate(0, 0, 1), meetings: 5 * time.Hour, focus: 2 * time.Hour, switches: 6},
+		{period: "2024-03-13", start: monday.AddDate(0, 0, 2)},
+	}
+
+	weeks := weeklyLoad(rows)
+	if len(weeks) != 2 || weeks[1].period != "week of 2024-03-11" || weeks[1].meetings != 8*time.Hour || weeks[1].focus != 2*time.Hour || weeks[1].switches != 10 {
+		t.Errorf("expected the week of 2024-03-11 with 8h of meetings, got %+v", weeks)
+	}
+
+	busiest := busiestDays(rows, 2)
+	if len(busiest) != 2 || busiest[0].period != "2024-03-12" || busiest[1].period != "2024-03-11" {
+		t.Errorf("expected 2024-03-12 and 2024-03-11 to be the busiest, got %+v", busiest)
+	}
+}
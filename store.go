@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store is the local history of generated reports, kept as a JSON file in
+// the data directory so totals survive between runs.
+type Store struct {
+	path string
+	Days map[string]*DayRecord `json:"days"` // keyed by date in dateLayout
+	// Notes are captured with `chunkit note`, keyed by date in dateLayout.
+	Notes map[string][]TimedNote `json:"notes,omitempty"`
+	// Links are added with `chunkit link`, keyed by date in dateLayout.
+	Links map[string][]TimedNote `json:"links,omitempty"`
+	// Annotations name gaps after the fact with `chunkit annotate`, keyed
+	// by date in dateLayout.
+	Annotations map[string][]TimedNote `json:"annotations,omitempty"`
+	// Overrides relabel single event instances with `chunkit override`,
+	// keyed by date in dateLayout.
+	Overrides map[string][]Override `json:"overrides,omitempty"`
+	// Invoices are the numbers issued by `chunkit invoice`.
+	Invoices []InvoiceRecord `json:"invoices,omitempty"`
+	// Locks are the days pushed with `chunkit push -lock`, keyed by date in
+	// dateLayout.
+	Locks map[string]DayLock `json:"locks,omitempty"`
+	// Pushed are the entries pushes created in time trackers, keyed by
+	// pushedKey.
+	Pushed map[string][]PushedEntry `json:"pushed,omitempty"`
+}
+
+// DayRecord is what we remember about a single reported day.
+type DayRecord struct {
+	Hours float64 `json:"hours"`
+	// Allocated are the hours of the day's events, its gaps aside.
+	Allocated float64 `json:"allocated,omitempty"`
+	// Projects are the hours per project, "" for those without one.
+	Projects map[string]float64 `json:"projects,omitempty"`
+	// Capex are the capitalizable hours per project.
+	Capex map[string]float64 `json:"capex,omitempty"`
+	// Location is where most of the day was worked, e.g. "home".
+	Location string `json:"location,omitempty"`
+	// Absence is the type of absence the day was, such as "sick".
+	Absence string `json:"absence,omitempty"`
+	// Target is the config's target hours for the day, if one was set.
+	Target *float64 `json:"target,omitempty"`
+	// Balance is the running total of hours over target up to the day,
+	// across the days recorded with one.
+	Balance float64 `json:"balance,omitempty"`
+}
+
+// newDayRecord totals the day's chunks, with its target under config. Absent
+// days have none.
+func newDayRecord(date time.Time, chunks []*Chunk, config *Config, absence string) *DayRecord {
+	total, allocated := time.Duration(0), time.Duration(0)
+	for _, chunk := range chunks {
+		total += chunk.end.Sub(chunk.start)
+		if chunk.Event != nil {
+			allocated += chunk.end.Sub(chunk.start)
+		}
+	}
+	record := &DayRecord{
+		Hours:     total.Hours(),
+		Allocated: allocated.Hours(),
+		Projects:  projectHours(chunks),
+		Capex:     projectHours(capitalized(chunks)),
+		Location:  dayLocation(chunks),
+		Absence:   absence,
+	}
+	if config.Target.set() {
+		target := 0.0
+		if absence == "" {
+			target = config.Target.hours(date, config.Workdays)
+		}
+		record.Target = &target
+	}
+	return record
+}
+
+// InvoiceRecord is an invoice number issued for a client's month, reused when
+// the invoice is rendered again.
+type InvoiceRecord struct {
+	Number string `json:"number"`
+	Client string `json:"client"`
+	Month  string `json:"month"` // YYYY-MM
+}
+
+// invoiceNumber returns the number of the client's invoice for month,
+// issuing the next one of the month's year, e.g. 2024-007, if there is none.
+func (s *Store) invoiceNumber(client, month string) string {
+	issued := 0
+	for _, invoice := range s.Invoices {
+		if invoice.Client == client && invoice.Month == month {
+			return invoice.Number
+		}
+		if strings.HasPrefix(invoice.Month, month[:4]) {
+			issued++
+		}
+	}
+	number := fmt.Sprintf("%s-%03d", month[:4], issued+1)
+	s.Invoices = append(s.Invoices, InvoiceRecord{Number: number, Client: client, Month: month})
+	return number
+}
+
+// Override replaces the notes or project, whichever are set, of the event
+// with EventID. Summary is the event's at the time, for reference.
+type Override struct {
+	EventID string `json:"eventId"`
+	Summary string `json:"summary"`
+	Notes   string `json:"notes,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// TimedNote is free text noted at a point in time.
+type TimedNote struct {
+	At   time.Time `json:"at"`
+	Text string    `json:"text"`
+}
+
+// dataDir returns the directory holding the local history, following the
+// XDG base directory spec: $XDG_DATA_HOME/chunkit, falling back to
+// ~/.local/share/chunkit.
+func dataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "chunkit")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(home, ".local", "share", "chunkit")
+}
+
+// openStore reads the store at path, returning an empty one if the file does
+// not exist yet.
+func openStore(path string) (*Store, error) {
+	s := &Store{path: path, Days: map[string]*DayRecord{}, Notes: map[string][]TimedNote{}, Links: map[string][]TimedNote{}, Annotations: map[string][]TimedNote{}, Overrides: map[string][]Override{}, Locks: map[string]DayLock{}, Pushed: map[string][]PushedEntry{}}
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the history file: %v", err)
+	}
+	if err := json.Unmarshal(bytes, s); err != nil {
+		return nil, fmt.Errorf("error decoding the history file %s: %v", path, err)
+	}
+	if s.Days == nil {
+		s.Days = map[string]*DayRecord{}
+	}
+	if s.Notes == nil {
+		s.Notes = map[string][]TimedNote{}
+	}
+	if s.Links == nil {
+		s.Links = map[string][]TimedNote{}
+	}
+	if s.Annotations == nil {
+		s.Annotations = map[string][]TimedNote{}
+	}
+	if s.Overrides == nil {
+		s.Overrides = map[string][]Override{}
+	}
+	if s.Locks == nil {
+		s.Locks = map[string]DayLock{}
+	}
+	if s.Pushed == nil {
+		s.Pushed = map[string][]PushedEntry{}
+	}
+	return s, nil
+}
+
+// Save writes the store to a temporary file and renames it over the history
+// file, so readers never see it half written.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("error creating the history directory: %v", err)
+	}
+	bytes, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding the history: %v", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing the history file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(bytes); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing the history file: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing the history file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing the history file: %v", err)
+	}
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		return fmt.Errorf("error writing the history file: %v", err)
+	}
+	return nil
+}
+
+// updateStore applies update to the store at path and saves it, holding a
+// lock so concurrent runs don't overwrite each other's days.
+func updateStore(path string, update func(s *Store)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating the history directory: %v", err)
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := openStore(path)
+	if err != nil {
+		return err
+	}
+	update(s)
+	return s.Save()
+}
+
+// enrich adds the day's gap annotations, and the notes and links captured
+// during it, to its chunks.
+func (s *Store) enrich(date time.Time, chunks []*Chunk) {
+	day := date.Format(dateLayout)
+	annotateGaps(chunks, s.Annotations[day])
+	attachNotes(chunks, s.Notes[day])
+	attachLinks(chunks, s.Links[day])
+}
+
+// rebalance recomputes the running flex balance of the days recorded with
+// a target, for when days are recorded out of order or again.
+func (s *Store) rebalance() {
+	balance := 0.0
+	for _, date := range s.Dates() {
+		day := s.Days[date]
+		if day == nil || day.Target == nil {
+			continue
+		}
+		balance += day.Hours - *day.Target
+		day.Balance = balance
+	}
+}
+
+// balance returns the running flex balance as of the last day recorded with
+// a target, and whether there is one.
+func (s *Store) balance() (float64, bool) {
+	dates := s.Dates()
+	for i := len(dates) - 1; i >= 0; i-- {
+		if day := s.Days[dates[i]]; day != nil && day.Target != nil {
+			return day.Balance, true
+		}
+	}
+	return 0, false
+}
+
+// Check returns the problems found in the store's records, if any.
+func (s *Store) Check() []string {
+	var problems []string
+	for _, date := range s.Dates() {
+		day := s.Days[date]
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: not a YYYY-MM-DD date", date))
+		}
+		switch {
+		case day == nil:
+			problems = append(problems, fmt.Sprintf("%s: empty record", date))
+		case math.IsNaN(day.Hours) || day.Hours < 0:
+			problems = append(problems, fmt.Sprintf("%s: invalid hours %v", date, day.Hours))
+		}
+	}
+	return problems
+}
+
+// Dates returns the recorded dates in chronological order.
+func (s *Store) Dates() []string {
+	dates := make([]string, 0, len(s.Days))
+	for date := range s.Days {
+		dates = append(dates, date)
+	}
+	// dateLayout sorts lexically in date order
+	sort.Strings(dates)
+	return dates
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func Test_updateStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	// concurrent runs must not drop each other's days
+	wg := sync.WaitGroup{}
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(day int) {
+			defer wg.Done()
+			err := updateStore(path, func(s *Store) {
+				s.Days[fmt.Sprintf("2024-03-%02d", day)] = &DayRecord{Hours: 8}
+			})
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	store, err := openStore(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(store.Days) != 20 {
+		t.Errorf("expected 20 recorded days, got %d", len(store.Days))
+	}
+}
+
+func Test_Store_Check(t *testing.T) {
+	store := &Store{Days: map[string]*DayRecord{
+		"2024-03-11": {Hours: 8},
+		"2024-13-01": {Hours: 8},
+		"2024-03-12": {Hours: -1},
+		"2024-03-13": {Hours: math.NaN()},
+		"2024-03-14": nil,
+	}}
+
+	problems := store.Check()
+
+	if len(problems) != 4 {
+		t.Errorf("expected 4 problems, got %d: %v", len(problems), problems)
+	}
+}
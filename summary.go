@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runSummary implements the `summary` subcommand, totalling the time per
+// project, organizer or attendee domain for status reports.
+func runSummary(args []string) {
+	fs := flag.NewFlagSet("summary", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	week := fs.Bool("week", false, "Summarize the Monday to Sunday week of -date, like -date this-week does for the current one")
+	narrative := fs.Bool("narrative", false, "Write a paragraph per project instead of a table")
+	narrateCmd := fs.String("narrate-cmd", "", "Shell command to rewrite the narrative, e.g. an LLM CLI; it gets the paragraphs on stdin")
+	by := fs.String("by", "project", "Group the time by project, organizer, domain of the attendees' emails, or working location, counting days")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+	var keys func(*Chunk) []string
+	switch *by {
+	case "project":
+	case "organizer":
+		keys = organizerOf
+	case "domain":
+		keys = domainsOf
+	case "location":
+	default:
+		fatal(withExitCode(exitUsage, fmt.Errorf("invalid -by %q: must be project, organizer, domain or location", *by)))
+	}
+	if *by != "project" && *narrative {
+		fatal(withExitCode(exitUsage, fmt.Errorf("-narrative only summarizes by project")))
+	}
+
+	from, days, err := opts.reportRange()
+	if err != nil {
+		fatal(err)
+	}
+	if *week {
+		from, days = startOfWeek(from), 7
+	}
+	if err := opts.validate(); err != nil {
+		fatal(err)
+	}
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+
+	dayChunks, err := opts.buildDays(ctx, calendarService, from, days)
+	if err != nil {
+		fatal(err)
+	}
+	var chunks []*Chunk
+	for _, day := range dayChunks {
+		chunks = append(chunks, day...)
+	}
+	records := make([]*DayRecord, len(dayChunks))
+	for i, day := range dayChunks {
+		date := from.AddDate(0, 0, i)
+		records[i] = newDayRecord(date, day, config, opts.absence(date))
+	}
+	flex := ""
+	if config.Target.set() {
+		if flex, err = recordFlex(opts.history, from, records); err != nil {
+			fatal(err)
+		}
+	}
+
+	period := "the date: " + from.Format(dateLayout)
+	if days > 1 {
+		period = fmt.Sprintf("%s to %s", from.Format(dateLayout), from.AddDate(0, 0, days-1).Format(dateLayout))
+	}
+	if *by == "location" {
+		buf := strings.Builder{}
+		buf.WriteString("location,days,hours\n")
+		for _, row := range countLocations(records) {
+			buf.WriteString(fmt.Sprintf("%s,%d,%s\n", row.location, row.days, formatHours(opts.timeFormat, time.Duration(row.hours*float64(time.Hour)))))
+		}
+		fmt.Printf("\nDays by working location for %s.\n\n%s%s", period, buf.String(), flex)
+		return
+	}
+	if keys != nil {
+		buf := strings.Builder{}
+		buf.WriteString(*by + ",hours\n")
+		for _, group := range totalBy(chunks, keys) {
+			buf.WriteString(fmt.Sprintf("%s,%s\n", group.name, formatHours(opts.timeFormat, group.total)))
+		}
+		fmt.Printf("\nSummary by %s for %s.\n\n%s%s", *by, period, buf.String(), flex)
+		return
+	}
+
+	projects := summarize(chunks)
+	earning := len(config.Rates) > 0
+	total := 0.0
+	for _, p := range projects {
+		total += config.earned(p.project, p.total)
+	}
+	if !*narrative {
+		buf := strings.Builder{}
+		header := "project,hours"
+		if earning {
+			header += ",earned"
+		}
+		buf.WriteString(header + "\n")
+		for _, p := range projects {
+			line := fmt.Sprintf("%s,%s", p.project, formatHours(opts.timeFormat, p.total))
+			if earning {
+				line += fmt.Sprintf(",%.2f", config.earned(p.project, p.total))
+			}
+			buf.WriteString(line + "\n")
+		}
+		if earning {
+			period += ", earning " + config.money(total)
+		}
+		fmt.Printf("\nSummary for %s.\n\n%s%s", period, buf.String(), flex)
+		return
+	}
+
+	paragraphs := make([]string, 0, len(projects))
+	for _, p := range projects {
+		paragraph := p.narrative()
+		if earning {
+			paragraph += fmt.Sprintf(" Earned %s.", config.money(config.earned(p.project, p.total)))
+		}
+		paragraphs = append(paragraphs, paragraph)
+	}
+	if earning {
+		paragraphs = append(paragraphs, fmt.Sprintf("Earned %s in total.", config.money(total)))
+	}
+	text := strings.Join(paragraphs, "\n\n") + "\n"
+	if *narrateCmd != "" {
+		if text, err = narrate(ctx, *narrateCmd, text); err != nil {
+			fatal(err)
+		}
+	}
+	fmt.Print(text + flex)
+}
+
+// projectSummary is the time spent on a project and what it was spent on.
+type projectSummary struct {
+	project string
+	total   time.Duration
+	notes   []string // distinct notes, most time first
+	counts  map[string]int
+	spent   map[string]time.Duration
+}
+
+// summarize groups chunks by project, the projects with the most time first.
+func summarize(chunks []*Chunk) []*projectSummary {
+	byProject := map[string]*projectSummary{}
+	var projects []*projectSummary
+	for _, chunk := range chunks {
+		p, ok := byProject[chunk.project]
+		if !ok {
+			p = &projectSummary{project: chunk.project, counts: map[string]int{}, spent: map[string]time.Duration{}}
+			byProject[chunk.project] = p
+			projects = append(projects, p)
+		}
+		d := chunk.end.Sub(chunk.start)
+		p.total += d
+		if chunk.notes == "" {
+			continue
+		}
+		if p.counts[chunk.notes] == 0 {
+			p.notes = append(p.notes, chunk.notes)
+		}
+		p.counts[chunk.notes]++
+		p.spent[chunk.notes] += d
+	}
+
+	for _, p := range projects {
+		sort.SliceStable(p.notes, func(i, j int) bool { return p.spent[p.notes[i]] > p.spent[p.notes[j]] })
+	}
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].total > projects[j].total })
+	return projects
+}
+
+// narrative describes the project's time in a sentence, e.g. "Spent 9h30m on
+// ACME: Sprint planning, Design review (x2)."
+func (p *projectSummary) narrative() string {
+	on := "on " + p.project
+	if p.project == "" {
+		on = "without a project"
+	}
+	if len(p.notes) == 0 {
+		return fmt.Sprintf("Spent %s %s.", formatSpent(p.total), on)
+	}
+
+	items := make([]string, 0, len(p.notes))
+	for _, note := range p.notes {
+		if n := p.counts[note]; n > 1 {
+			note = fmt.Sprintf("%s (x%d)", note, n)
+		}
+		items = append(items, note)
+	}
+	return fmt.Sprintf("Spent %s %s: %s.", formatSpent(p.total), on, strings.Join(items, ", "))
+}
+
+// projectHours totals the chunks' hours per project, "" for those without one.
+func projectHours(chunks []*Chunk) map[string]float64 {
+	hours := map[string]float64{}
+	for _, chunk := range chunks {
+		hours[chunk.project] += chunk.end.Sub(chunk.start).Hours()
+	}
+	return hours
+}
+
+// narrate pipes the narrative through a shell command, such as an LLM CLI
+// prompted to polish it, and returns what it prints.
+func narrate(ctx context.Context, command, text string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running -narrate-cmd: %v", err)
+	}
+	return string(out), nil
+}
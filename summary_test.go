@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_summarize(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunk := func(start, end time.Duration, notes, project string) *Chunk {
+		return &Chunk{start: date.Add(start), end: date.Add(end), notes: notes, project: project}
+	}
+	chunks := []*Chunk{
+		chunk(9*time.Hour, 10*time.Hour, "Design review", "ACME"),
+		chunk(10*time.Hour, 12*time.Hour, "Sprint planning", "ACME"),
+		chunk(12*time.Hour, 13*time.Hour, "", ""),
+		chunk(13*time.Hour, 14*time.Hour+30*time.Minute, "Design review", "ACME"),
+		chunk(14*time.Hour+30*time.Minute, 17*time.Hour, "", "ACME"),
+	}
+
+	projects := summarize(chunks)
+
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+	expected := []string{
+		"Spent 7h on ACME: Design review (x2), Sprint planning.",
+		"Spent 1h without a project.",
+	}
+	for i, p := range projects {
+		if narrative := p.narrative(); narrative != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], narrative)
+		}
+	}
+}
+
+func Test_narrate(t *testing.T) {
+	text, err := narrate(context.Background(), "sed s/Spent/Worked/", "Spent 7h on ACME.\n")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if text != "Worked 7h on ACME.\n" {
+		t.Errorf("expected the command's output, got '%s'", text)
+	}
+}
+
+func Test_Config_earned(t *testing.T) {
+	config := &Config{Rates: map[string]float64{"ACME": 120, "default": 80}, Currency: "EUR"}
+	tests := []struct {
+		project  string
+		expected string
+	}{
+		{project: "ACME", expected: "EUR 180.00"},
+		{project: "Internal", expected: "EUR 120.00"},
+		{project: "", expected: "EUR 120.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.project, func(t *testing.T) {
+			if earned := config.money(config.earned(tt.project, 90*time.Minute)); earned != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, earned)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tagPattern matches the tokens of event descriptions chunks are tagged
+// with: hashtags such as #billable or #project:acme, issue keys such as
+// JIRA-123, and clients such as @acme.
+var tagPattern = regexp.MustCompile(`(?:^|[\s(\[,;])(#[A-Za-z][\w-]*(?::[\w.-]+)?|@[A-Za-z][\w.-]*|[A-Z][A-Z0-9]+-\d+)\b`)
+
+// The tags with a meaning of their own: #project:acme sets the project, and
+// the others override the billable rules.
+const (
+	projectHashtag     = "#project:"
+	billableHashtag    = "#billable"
+	nonBillableHashtag = "#nonbillable"
+)
+
+// extractTags returns the distinct tokens in text, in order.
+func extractTags(text string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, m := range tagPattern.FindAllStringSubmatch(text, -1) {
+		if tag := strings.TrimRight(m[1], ".-"); !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// tagChunks tags event chunks with the tokens in their event's description.
+// A #project: tag sets the chunk's project over its color's, and an @client
+// naming a client with a single project sets that one if there's none yet.
+func tagChunks(chunks []*Chunk, clients map[string]Client) {
+	for _, chunk := range chunks {
+		if chunk.Event == nil {
+			continue
+		}
+		chunk.tags = extractTags(chunk.Event.Description)
+		for _, tag := range chunk.tags {
+			if project, ok := strings.CutPrefix(tag, projectHashtag); ok {
+				chunk.project = project
+				chunk.explain("project %s by the %s tag", project, tag)
+			}
+		}
+		for _, tag := range chunk.tags {
+			name, ok := strings.CutPrefix(tag, "@")
+			if !ok || chunk.project != "" {
+				continue
+			}
+			for client, c := range clients {
+				if strings.EqualFold(client, name) && len(c.Projects) == 1 {
+					chunk.project = c.Projects[0]
+					chunk.explain("project %s of client %s by the %s tag", c.Projects[0], client, tag)
+				}
+			}
+		}
+	}
+}
+
+// hasTag reports whether the chunk is tagged with tag, case-insensitively.
+func (c *Chunk) hasTag(tag string) bool {
+	for _, t := range c.tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_extractTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "none", text: "Weekly sync, agenda in the doc"},
+		{name: "hashtags", text: "#project:acme.web and #billable", expected: []string{"#project:acme.web", "#billable"}},
+		{name: "issue keys", text: "Fixes JIRA-123, (OPS-7) and JIRA-123 again", expected: []string{"JIRA-123", "OPS-7"}},
+		{name: "clients", text: "Prep for @acme.", expected: []string{"@acme"}},
+		{name: "emails and urls", text: "me@example.com https://example.com/#section", expected: nil},
+		{name: "work package numbers", text: "Review #1234", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := extractTags(tt.text)
+			if strings.Join(tags, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("expected tags %v, got %v", tt.expected, tags)
+			}
+		})
+	}
+}
+
+func Test_tagChunks(t *testing.T) {
+	clients := map[string]Client{
+		"ACME":    {Projects: []string{"ACME Web"}},
+		"Initech": {Projects: []string{"TPS", "Printers"}},
+	}
+
+	tests := []struct {
+		name        string
+		chunk       *Chunk
+		project     string
+		nonBillable bool
+	}{
+		{
+			name:    "project tag wins over the color's",
+			chunk:   &Chunk{project: "Internal", Event: &Event{Description: "#project:Globex"}},
+			project: "Globex",
+		},
+		{
+			name:    "client with one project",
+			chunk:   &Chunk{Event: &Event{Description: "with @acme"}},
+			project: "ACME Web",
+		},
+		{
+			name:  "client with several projects",
+			chunk: &Chunk{Event: &Event{Description: "with @initech"}},
+		},
+		{
+			name:    "client after a project",
+			chunk:   &Chunk{project: "Internal", Event: &Event{Description: "with @acme"}},
+			project: "Internal",
+		},
+		{
+			name:        "non-billable",
+			chunk:       &Chunk{Event: &Event{Description: "#nonbillable"}},
+			nonBillable: true,
+		},
+		{
+			name:  "billable over the rules",
+			chunk: &Chunk{notes: "Internal sync", Event: &Event{Description: "#billable"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := []*Chunk{tt.chunk}
+			tagChunks(chunks, clients)
+			classifyBillable(chunks, []BillableRule{{Keyword: "internal", Billable: false}})
+			if tt.chunk.project != tt.project {
+				t.Errorf("expected project %q, got %q", tt.project, tt.chunk.project)
+			}
+			if tt.chunk.nonBillable != tt.nonBillable {
+				t.Errorf("expected non-billable %v, got %v", tt.nonBillable, tt.chunk.nonBillable)
+			}
+		})
+	}
+}
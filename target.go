@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Target is the hours you aim to work, per workday or spread evenly over the
+// workdays of a week, e.g. {"week": 20} for a part-time job. Days off have
+// none, so time worked on them is overtime.
+type Target struct {
+	Day  float64 `json:"day"`
+	Week float64 `json:"week"`
+}
+
+func (t Target) set() bool {
+	return t.Day > 0 || t.Week > 0
+}
+
+func (t Target) validate() error {
+	if t.Day < 0 || t.Week < 0 {
+		return fmt.Errorf("invalid target: hours must not be negative")
+	}
+	if t.Day > 0 && t.Week > 0 {
+		return fmt.Errorf("invalid target: set either day or week hours, not both")
+	}
+	return nil
+}
+
+// hours returns the target of date under the schedule.
+func (t Target) hours(date time.Time, schedule Schedule) float64 {
+	if !schedule.works(date.Weekday()) {
+		return 0
+	}
+	if t.Day > 0 {
+		return t.Day
+	}
+	workdays := 0
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if schedule.works(day) {
+			workdays++
+		}
+	}
+	return t.Week / float64(workdays)
+}
+
+// variance compares the hours of each day recorded, from the first, with its
+// target.
+func variance(from time.Time, records []*DayRecord) []flexRow {
+	rows := make([]flexRow, 0, len(records))
+	balance := 0.0
+	for i, record := range records {
+		row := flexRow{date: from.AddDate(0, 0, i).Format(dateLayout), hours: record.Hours, absence: record.Absence}
+		if record.Target != nil {
+			row.target = *record.Target
+		}
+		balance += row.hours - row.target
+		row.balance = balance
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// recordFlex records the days from the first in the history, for the
+// running flex balance, and describes their hours against the target.
+func recordFlex(history string, from time.Time, records []*DayRecord) (string, error) {
+	var store *Store
+	err := updateStore(history, func(s *Store) {
+		for i, record := range records {
+			s.Days[from.AddDate(0, 0, i).Format(dateLayout)] = record
+		}
+		s.rebalance()
+		store = s
+	})
+	if err != nil {
+		return "", err
+	}
+
+	rows := variance(from, records)
+	worked, target := 0.0, 0.0
+	buf := strings.Builder{}
+	buf.WriteString("date,hours,target,variance,balance,absence\n")
+	for _, row := range rows {
+		worked += row.hours
+		target += row.target
+		buf.WriteString(fmt.Sprintf("%s,%.2f,%.2f,%+.2f,%+.2f,%s\n", row.date, row.hours, row.target, row.hours-row.target, row.balance, row.absence))
+	}
+	result := "on target"
+	switch {
+	case worked > target:
+		result = fmt.Sprintf("%.2f hours of overtime", worked-target)
+	case worked < target:
+		result = fmt.Sprintf("%.2f hours of undertime", target-worked)
+	}
+	if absent := absenceCounts(records); absent != "" {
+		result += ", with " + absent
+	}
+	balance, _ := store.balance()
+	return fmt.Sprintf("\nWorked %.2f of the target %.2f hours, %s, for a running flex balance of %+.2f hours.\n\n%s", worked, target, result, balance, buf.String()), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Target_hours(t *testing.T) {
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	fridaysOff := Schedule{"fri": {Off: true}}
+
+	tests := []struct {
+		name     string
+		target   Target
+		schedule Schedule
+		date     time.Time
+		expected float64
+	}{
+		{name: "per day", target: Target{Day: 6}, date: monday, expected: 6},
+		{name: "per day on the weekend", target: Target{Day: 6}, date: monday.AddDate(0, 0, 5), expected: 0},
+		{name: "week over five days", target: Target{Week: 20}, date: monday, expected: 4},
+		{name: "week over four days", target: Target{Week: 20}, schedule: fridaysOff, date: monday, expected: 5},
+		{name: "week on a day off", target: Target{Week: 20}, schedule: fridaysOff, date: monday.AddDate(0, 0, 4), expected: 0},
+		{name: "week with a worked saturday", target: Target{Week: 24}, schedule: Schedule{"sat": {Start: "09:00", End: "13:00"}}, date: monday.AddDate(0, 0, 5), expected: 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if hours := test.target.hours(test.date, test.schedule); hours != test.expected {
+				t.Errorf("expected %.2f hours, got %.2f", test.expected, hours)
+			}
+		})
+	}
+}
+
+func Test_Target_validate(t *testing.T) {
+	if err := (Target{Day: 8, Week: 40}).validate(); err == nil {
+		t.Errorf("expected an error for both day and week targets")
+	}
+	if err := (Target{Week: -1}).validate(); err == nil {
+		t.Errorf("expected an error for a negative target")
+	}
+}
+
+func Test_variance(t *testing.T) {
+	friday := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	config := &Config{Target: Target{Day: 8}}
+	records := []*DayRecord{
+		newDayRecord(friday, day(friday, 9), config, ""),
+		newDayRecord(friday.AddDate(0, 0, 1), day(friday.AddDate(0, 0, 1), 2), config, ""),
+		newDayRecord(friday.AddDate(0, 0, 3), nil, config, "sick"),
+	}
+
+	rows := variance(friday, records)
+
+	expected := []flexRow{
+		{date: "2024-03-15", hours: 9, target: 8, balance: 1},
+		{date: "2024-03-16", hours: 2, target: 0, balance: 3},
+		{date: "2024-03-17", hours: 0, target: 0, absence: "sick", balance: 3},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i, row := range rows {
+		if row != expected[i] {
+			t.Errorf("expected row %d to be %+v, got %+v", i, expected[i], row)
+		}
+	}
+}
+
+func Test_recordFlex(t *testing.T) {
+	history := filepath.Join(t.TempDir(), "history.json")
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	config := &Config{Target: Target{Week: 20}}
+	tuesday, wednesday, thursday := monday.AddDate(0, 0, 1), monday.AddDate(0, 0, 2), monday.AddDate(0, 0, 3)
+
+	text, err := recordFlex(history, monday, []*DayRecord{newDayRecord(monday, day(monday, 5), config, ""), newDayRecord(tuesday, day(tuesday, 6), config, "")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(text, "Worked 11.00 of the target 8.00 hours, 3.00 hours of overtime, for a running flex balance of +3.00 hours.") {
+		t.Errorf("expected 3 hours of overtime, got %q", text)
+	}
+
+	// the next day is undertime, and the balance carries over
+	text, err = recordFlex(history, wednesday, []*DayRecord{newDayRecord(wednesday, day(wednesday, 2), config, ""), newDayRecord(thursday, nil, config, "pto")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(text, "Worked 2.00 of the target 4.00 hours, 2.00 hours of undertime, with 1 day of pto, for a running flex balance of +1.00 hours.") {
+		t.Errorf("expected 2 hours of undertime, a day of PTO and a balance of +1, got %q", text)
+	}
+
+	store, err := openStore(history)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if balance := store.Days["2024-03-12"].Balance; balance != 3 {
+		t.Errorf("expected a recorded balance of +3 on Tuesday, got %+.2f", balance)
+	}
+}
+
+func Test_recordFlex_week(t *testing.T) {
+	history := filepath.Join(t.TempDir(), "history.json")
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	config := &Config{Target: Target{Day: 8}}
+
+	// a week without events: workdays are all gap, the weekend nothing
+	var records []*DayRecord
+	for i := range 7 {
+		date := monday.AddDate(0, 0, i)
+		records = append(records, newDayRecord(date, Chunkify(date, nil, Options{Schedule: config.Workdays}), config, ""))
+	}
+	if _, err := recordFlex(history, monday, records); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	store, err := openStore(history)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if saturday := store.Days["2024-03-16"]; saturday.Hours != 0 || *saturday.Target != 0 {
+		t.Errorf("expected no hours against no target on Saturday, got %.2f of %.2f", saturday.Hours, *saturday.Target)
+	}
+	if balance := store.Days["2024-03-17"].Balance; balance != 0 {
+		t.Errorf("expected a balance of 0 after the week, got %+.2f", balance)
+	}
+}
+
+// day is a chunk of hours from 9 on date.
+func day(date time.Time, hours int) []*Chunk {
+	return []*Chunk{{start: date.Add(9 * time.Hour), end: date.Add(time.Duration(9+hours) * time.Hour)}}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// runTeam implements the `team` subcommand, totalling the hours of each
+// member of the configured team per project for their manager.
+func runTeam(args []string) {
+	fs := flag.NewFlagSet("team", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	serviceAccount := fs.String("service-account", "", "Path to a service account key with domain-wide delegation to read each member's calendar as them, instead of the calendars they shared with you")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+	from, days, err := opts.reportRange()
+	if err != nil {
+		fatal(err)
+	}
+	if err := opts.validate(); err != nil {
+		fatal(err)
+	}
+	config, err := opts.loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	if len(config.Team) == 0 {
+		fatal(withExitCode(exitConfig, fmt.Errorf("no team in the config file")))
+	}
+
+	ctx := signalContext()
+	var totals []teamTotal
+	for _, member := range config.Team {
+		chunks, err := opts.memberChunks(ctx, member, *serviceAccount, from, days)
+		if err != nil {
+			fatal(err)
+		}
+		totals = append(totals, memberTotals(member.name(), chunks)...)
+	}
+
+	period := "the date: " + from.Format(dateLayout)
+	if days > 1 {
+		period = fmt.Sprintf("%s to %s", from.Format(dateLayout), from.AddDate(0, 0, days-1).Format(dateLayout))
+	}
+	fmt.Printf("\nTeam report for %s.\n\n%s", period, teamCSV(totals, opts.timeFormat))
+}
+
+// memberChunks builds the member's chunks of the days from from, reading
+// their calendar as them with a service account key, or as shared with you.
+func (o *reportOptions) memberChunks(ctx context.Context, member TeamMember, serviceAccount string, from time.Time, days int) ([]*Chunk, error) {
+	// the history's notes and overrides are yours, not theirs
+	opts := *o
+	opts.history = ""
+	opts.calendar = member.Email
+
+	var (
+		calendarService *calendar.Service
+		err             error
+	)
+	if serviceAccount != "" {
+		opts.calendar = primaryCalendar
+		calendarService, err = impersonate(ctx, serviceAccount, member.Email)
+	} else {
+		calendarService, err = opts.service(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dayChunks, err := opts.buildDays(ctx, calendarService, from, days)
+	if err != nil {
+		return nil, fmt.Errorf("error building the days of %s: %w", member.name(), err)
+	}
+	var chunks []*Chunk
+	for _, day := range dayChunks {
+		chunks = append(chunks, day...)
+	}
+	return chunks, nil
+}
+
+// impersonate returns a Calendar API client reading email's calendar as
+// them, through a service account with domain-wide delegation.
+func impersonate(ctx context.Context, keyPath, email string) (*calendar.Service, error) {
+	bytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the service account key: %v", err)
+	}
+	jwt, err := google.JWTConfigFromJSON(bytes, defaultScope)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the service account key: %v", err)
+	}
+	jwt.Subject = email
+	client := jwt.Client(context.WithValue(ctx, oauth2.HTTPClient, httpClient))
+	client.Timeout = httpClient.Timeout
+	return calendar.NewService(ctx, option.WithHTTPClient(client))
+}
+
+// teamTotal is a member's time on a project, and how much of it is billable.
+type teamTotal struct {
+	person   string
+	project  string
+	total    time.Duration
+	billable time.Duration
+}
+
+// memberTotals totals the person's chunks per project, the most time first.
+func memberTotals(person string, chunks []*Chunk) []teamTotal {
+	byProject := map[string]*teamTotal{}
+	for _, chunk := range chunks {
+		t, ok := byProject[chunk.project]
+		if !ok {
+			t = &teamTotal{person: person, project: chunk.project}
+			byProject[chunk.project] = t
+		}
+		d := chunk.end.Sub(chunk.start)
+		t.total += d
+		if !chunk.nonBillable {
+			t.billable += d
+		}
+	}
+
+	totals := make([]teamTotal, 0, len(byProject))
+	for _, t := range byProject {
+		totals = append(totals, *t)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].total != totals[j].total {
+			return totals[i].total > totals[j].total
+		}
+		return totals[i].project < totals[j].project
+	})
+	return totals
+}
+
+func teamCSV(totals []teamTotal, timeFormat string) string {
+	buf := strings.Builder{}
+	buf.WriteString("person,project,hours,billable\n")
+	for _, t := range totals {
+		buf.WriteString(fmt.Sprintf("%s,%s,%s,%s\n", t.person, t.project, formatHours(timeFormat, t.total), formatHours(timeFormat, t.billable)))
+	}
+	return buf.String()
+}
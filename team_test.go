@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_memberTotals(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunk := func(start, end time.Duration, project string, nonBillable bool) *Chunk {
+		return &Chunk{start: date.Add(start), end: date.Add(end), project: project, nonBillable: nonBillable}
+	}
+	chunks := []*Chunk{
+		chunk(9*time.Hour, 10*time.Hour, "ACME", false),
+		chunk(10*time.Hour, 11*time.Hour, "", true),
+		chunk(11*time.Hour, 13*time.Hour, "ACME", true),
+		chunk(13*time.Hour, 14*time.Hour, "Initech", false),
+	}
+
+	csv := teamCSV(memberTotals("Ada", chunks), timeDecimal)
+
+	expected := "person,project,hours,billable\nAda,ACME,3.00 hours,1.00 hours\nAda,,1.00 hours,0.00 hours\nAda,Initech,1.00 hours,1.00 hours\n"
+	if csv != expected {
+		t.Errorf("expected '%s', got '%s'", expected, csv)
+	}
+}
+
+func Test_TeamMember_name(t *testing.T) {
+	if name := (TeamMember{Email: "ada@example.com"}).name(); name != "ada@example.com" {
+		t.Errorf("expected the email without a name, got '%s'", name)
+	}
+	if name := (TeamMember{Name: "Ada", Email: "ada@example.com"}).name(); name != "Ada" {
+		t.Errorf("expected the name, got '%s'", name)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const formatTTY = "tty"
+
+// isTerminal reports whether f is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ttyWidth is how many columns the timeline takes: the terminal's $COLUMNS
+// less room for the times either side, 60 if it isn't set.
+func ttyWidth() int {
+	columns, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || columns < 40 {
+		return 60
+	}
+	return columns - 20
+}
+
+// writeTTY draws each report as a timeline of block characters, colored by
+// project unless $NO_COLOR is set, with gaps shaded, above a table of its
+// chunks, then a legend of the projects.
+func writeTTY(w io.Writer, reports []*Report, timeFormat string, width int, colored bool) error {
+	paint := func(c paletteColor, s string) string {
+		return ansi(colored, fmt.Sprintf("38;2;%d;%d;%d", c.rgb.R, c.rgb.G, c.rgb.B), s)
+	}
+
+	buf := strings.Builder{}
+	for _, r := range reports {
+		buf.WriteString(fmt.Sprintf("\n%s  %s\n", r.Date.Format("Mon 2006-01-02"), formatHours(timeFormat, r.Total)))
+		if len(r.Rows) == 0 {
+			buf.WriteString("Nothing recorded.\n")
+			continue
+		}
+		from, to := r.Rows[0].Start, r.Rows[len(r.Rows)-1].End
+		step := to.Sub(from) / time.Duration(width)
+		line := strings.Builder{}
+		for i := 0; i < width; i++ {
+			at := from.Add(step*time.Duration(i) + step/2)
+			for _, row := range r.Rows {
+				if at.Before(row.Start) || !at.Before(row.End) {
+					continue
+				}
+				block := "█"
+				if row.Gap && row.Color == "" {
+					block = "░"
+				}
+				line.WriteString(paint(rowColor(row), block))
+				break
+			}
+		}
+		buf.WriteString(fmt.Sprintf("%s %s %s\n\n", formatTimeAs(timeFormat, r.Date, from), line.String(), formatTimeAs(timeFormat, r.Date, to)))
+		buf.WriteString(ttyTable(r, timeFormat, colored))
+	}
+
+	// the legend: the projects, the most time first, then the time without one
+	colors := map[string]paletteColor{}
+	var other, unallocated time.Duration
+	for _, r := range reports {
+		for _, row := range r.Rows {
+			switch {
+			case row.Project != "":
+				colors[row.Project] = rowColor(row)
+			case row.Gap:
+				unallocated += row.Duration()
+			default:
+				other += row.Duration()
+			}
+		}
+	}
+	buf.WriteString("\n")
+	for _, p := range projectTotals(reports) {
+		if p.Name != "" {
+			buf.WriteString(fmt.Sprintf("%s %s %s\n", paint(colors[p.Name], "█"), p.Name, formatHours(timeFormat, p.Total)))
+		}
+	}
+	if other > 0 {
+		buf.WriteString(fmt.Sprintf("%s no project %s\n", paint(ganttEventColor, "█"), formatHours(timeFormat, other)))
+	}
+	if unallocated > 0 {
+		buf.WriteString(fmt.Sprintf("%s unallocated %s\n", paint(ganttGapColor, "░"), formatHours(timeFormat, unallocated)))
+	}
+	_, err := fmt.Fprint(w, buf.String())
+	return err
+}
+
+// ttyTable lays the report's rows out in aligned columns with their
+// durations, gaps dimmed, and a footer of the totals.
+func ttyTable(r *Report, timeFormat string, colored bool) string {
+	header := []string{"start", "end", "time"}
+	if r.Notes {
+		header = append(header, "notes")
+	}
+	if r.Project {
+		header = append(header, "project")
+	}
+	if r.OnCallTracked {
+		header = append(header, "on call")
+	}
+	if r.BillableTracked {
+		header = append(header, "billable")
+	}
+	if r.Located {
+		header = append(header, "location")
+	}
+	if r.Linked {
+		header = append(header, "links")
+	}
+	if r.Tagged {
+		header = append(header, "tags")
+	}
+	lines := [][]string{header}
+	for _, row := range r.Rows {
+		cells := []string{
+			formatTimeAs(timeFormat, r.Date, row.Start),
+			formatTimeAs(timeFormat, r.Date, row.End),
+			formatHours(timeFormat, row.Duration()),
+		}
+		if r.Notes {
+			cells = append(cells, row.title())
+		}
+		if r.Project {
+			cells = append(cells, row.Project)
+		}
+		if r.OnCallTracked {
+			cells = append(cells, yesNo(row.OnCall))
+		}
+		if r.BillableTracked {
+			cells = append(cells, yesNo(row.Billable))
+		}
+		if r.Located {
+			cells = append(cells, row.Location)
+		}
+		if r.Linked {
+			cells = append(cells, strings.Join(row.Links, " "))
+		}
+		if r.Tagged {
+			cells = append(cells, strings.Join(row.Tags, " "))
+		}
+		lines = append(lines, cells)
+	}
+
+	widths := make([]int, len(header))
+	for _, cells := range lines {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	buf := strings.Builder{}
+	for n, cells := range lines {
+		line := strings.Builder{}
+		for i, cell := range cells {
+			line.WriteString(cell)
+			if i < len(cells)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+			}
+		}
+		text := strings.TrimRight(line.String(), " ")
+		switch {
+		case n == 0:
+			text = ansi(colored, "1", text)
+		case r.Rows[n-1].Gap:
+			text = ansi(colored, "2", text)
+		}
+		buf.WriteString(text + "\n")
+	}
+	buf.WriteString(ansi(colored, "1", "Total "+r.totals(timeFormat)) + "\n")
+	return buf.String()
+}
+
+// ansi wraps s in the SGR escape code, e.g. "1" for bold, when colored.
+func ansi(colored bool, code, s string) string {
+	if !colored {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
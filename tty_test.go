@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_writeTTY(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	reports := []*Report{
+		newReport(date, testChunks(date), reportColumns{notes: true, project: true}),
+		newReport(date.AddDate(0, 0, 1), nil, reportColumns{notes: true}),
+	}
+
+	tests := []struct {
+		name     string
+		colored  bool
+		expected []string
+	}{
+		{
+			name: "plain",
+			expected: []string{
+				"Wed 2024-05-01  2:30\n09:00 ████░░░░░░ 11:30\n",
+				"Thu 2024-05-02  0:00\nNothing recorded.\n",
+				"█ ACME 1:00\n░ unallocated 1:30\n",
+			},
+		},
+		{
+			name:     "colored",
+			colored:  true,
+			expected: []string{"\x1b[38;2;221;221;221m░\x1b[0m unallocated 1:30\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := strings.Builder{}
+			if err := writeTTY(&buf, reports, timeClock, 10, test.colored); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			for _, s := range test.expected {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("expected the output to contain %q, got:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_ttyTable(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	report := newReport(date, testChunks(date), reportColumns{notes: true, project: true, onCall: true})
+
+	expected := "start  end    time  notes        project  on call\n" +
+		"09:00  10:00  1:00  Standup      ACME     no\n" +
+		"10:00  11:30  1:30  Unallocated           yes\n" +
+		"Total 2:30, 1:30 of them on call\n"
+	if table := ttyTable(report, timeClock, false); table != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, table)
+	}
+
+	colored := ttyTable(report, timeClock, true)
+	if !strings.Contains(colored, "\x1b[2m10:00  11:30  1:30  Unallocated           yes\x1b[0m\n") {
+		t.Errorf("expected the gap dimmed, got %q", colored)
+	}
+}
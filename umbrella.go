@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// span is an event's time as Chunkify sees it, before later events cut it
+// short.
+type span struct {
+	event      *Event
+	notes      string
+	start, end time.Time
+	rule       int // the umbrella rule it matched, -1 for none
+}
+
+func (s span) contains(other span) bool {
+	return !s.start.After(other.start) && !s.end.Before(other.end) && s.end.Sub(s.start) > other.end.Sub(other.start)
+}
+
+// umbrellas are the spans matching an umbrella rule, an empty keyword
+// matching events that contain another one.
+func umbrellas(spans []span, rules []UmbrellaRule) []span {
+	var matched []span
+	for _, s := range spans {
+		for i, rule := range rules {
+			if rule.Keyword == "" && !containsAny(s, spans) {
+				continue
+			}
+			if strings.Contains(strings.ToLower(s.event.Summary), strings.ToLower(rule.Keyword)) {
+				s.rule = i
+				matched = append(matched, s)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func containsAny(s span, spans []span) bool {
+	for _, other := range spans {
+		if s.contains(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// fillUmbrellas names the unnamed gaps after the innermost umbrella spanning
+// them, splitting the gaps that only partly overlap one.
+func fillUmbrellas(chunks []*Chunk, umbrellas []span) []*Chunk {
+	if len(umbrellas) == 0 {
+		return chunks
+	}
+	filled := make([]*Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.Event != nil || chunk.notes != "" {
+			filled = append(filled, chunk)
+			continue
+		}
+
+		cuts := []time.Time{chunk.start, chunk.end}
+		for _, u := range umbrellas {
+			for _, t := range []time.Time{u.start, u.end} {
+				if t.After(chunk.start) && t.Before(chunk.end) {
+					cuts = append(cuts, t)
+				}
+			}
+		}
+		sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+
+		for i := 0; i+1 < len(cuts); i++ {
+			if !cuts[i+1].After(cuts[i]) {
+				continue
+			}
+			piece := *chunk
+			piece.start, piece.end = cuts[i], cuts[i+1]
+			if u, ok := innermost(umbrellas, piece.start, piece.end); ok {
+				piece.notes = u.notes
+				piece.explain("named after %q which spans it", u.notes)
+				piece.matched(fmt.Sprintf("umbrella #%d", u.rule+1))
+			}
+			filled = append(filled, &piece)
+		}
+	}
+	return filled
+}
+
+// innermost is the shortest umbrella spanning start to end, the later one on
+// a tie.
+func innermost(umbrellas []span, start, end time.Time) (span, bool) {
+	var (
+		best  span
+		found bool
+	)
+	for _, u := range umbrellas {
+		if u.start.After(start) || u.end.Before(end) {
+			continue
+		}
+		if !found || u.end.Sub(u.start) <= best.end.Sub(best.start) {
+			best, found = u, true
+		}
+	}
+	return best, found
+}
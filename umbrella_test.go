@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Chunkify_umbrella(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	items := []*Event{
+		newEvent(date.Add(9*time.Hour), date.Add(17*time.Hour), "Team offsite", "accepted", true),
+		newEvent(date.Add(10*time.Hour), date.Add(13*time.Hour), "Design workshop", "accepted", true),
+		newEvent(date.Add(11*time.Hour), date.Add(11*time.Hour+30*time.Minute), "Breakout", "accepted", true),
+	}
+
+	tests := []struct {
+		name          string
+		umbrella      []UmbrellaRule
+		expectedNotes []string
+		expectedRules []string
+	}{
+		{
+			name:          "gaps stay unallocated without rules",
+			expectedNotes: []string{"Team offsite", "Design workshop", "Breakout", ""},
+			expectedRules: []string{"", "", "", ""},
+		},
+		{
+			name:          "innermost of nested umbrellas fills the gap",
+			umbrella:      []UmbrellaRule{{Keyword: "offsite"}, {Keyword: "workshop"}},
+			expectedNotes: []string{"Team offsite", "Design workshop", "Breakout", "Design workshop", "Team offsite"},
+			expectedRules: []string{"", "", "", "umbrella #2", "umbrella #1"},
+		},
+		{
+			name:          "gap is split where the umbrella ends",
+			umbrella:      []UmbrellaRule{{Keyword: "workshop"}},
+			expectedNotes: []string{"Team offsite", "Design workshop", "Breakout", "Design workshop", ""},
+			expectedRules: []string{"", "", "", "umbrella #1", ""},
+		},
+		{
+			name:          "empty keyword detects events containing others",
+			umbrella:      []UmbrellaRule{{}},
+			expectedNotes: []string{"Team offsite", "Design workshop", "Breakout", "Design workshop", "Team offsite"},
+			expectedRules: []string{"", "", "", "umbrella #1", "umbrella #1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := Chunkify(date, items, Options{Umbrella: test.umbrella})
+
+			if len(chunks) != len(test.expectedNotes) {
+				t.Fatalf("expected %d chunks, got %d", len(test.expectedNotes), len(chunks))
+			}
+			for i, chunk := range chunks {
+				if chunk.notes != test.expectedNotes[i] {
+					t.Errorf("expected chunk notes to be '%s', got '%s'", test.expectedNotes[i], chunk.notes)
+				}
+				if rules := strings.Join(chunk.rules, "; "); rules != test.expectedRules[i] {
+					t.Errorf("expected chunk %d to match '%s', got '%s'", i, test.expectedRules[i], rules)
+				}
+				if i > 0 && !chunk.start.Equal(chunks[i-1].end) {
+					t.Errorf("expected chunk %d to start at %s, got %s", i, chunks[i-1].end, chunk.start)
+				}
+			}
+		})
+	}
+}
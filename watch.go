@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// runWatch implements the `watch` subcommand, which keeps rewriting today's
+// report up to the current time.
+func runWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	opts := &reportOptions{}
+	opts.register(fs)
+	output := &outputOptions{}
+	output.register(fs)
+	every := fs.Duration("every", 15*time.Minute, "How often to refresh the report")
+	metricsAddr := fs.String("metrics-addr", "", "Address to serve Prometheus metrics of today's meeting load and the refreshes on, at /metrics, e.g. localhost:9090")
+	if err := opts.parse(fs, args); err != nil {
+		fatal(err)
+	}
+	if err := opts.validate(); err != nil {
+		fatal(err)
+	}
+	if err := output.validate(); err != nil {
+		fatal(err)
+	}
+	if output.path == "" {
+		fatal(withExitCode(exitUsage, fmt.Errorf("-o is required")))
+	}
+	if *every < time.Minute {
+		fatal(withExitCode(exitUsage, fmt.Errorf("-every must be at least a minute")))
+	}
+
+	ctx := signalContext()
+	calendarService, err := opts.service(ctx)
+	if err != nil {
+		fatal(err)
+	}
+
+	metrics := &watchMetrics{}
+	if *metricsAddr != "" {
+		if err := serveMetrics(ctx, *metricsAddr, metrics); err != nil {
+			fatal(err)
+		}
+	}
+
+	ticker := time.NewTicker(*every)
+	defer ticker.Stop()
+	for {
+		// always today, which moves on at midnight
+		opts.date = ""
+		started := time.Now()
+		chunks, err := opts.refresh(ctx, calendarService, output)
+		metrics.observe(chunks, time.Since(started), err, time.Now())
+		if err != nil {
+			log.Printf("error refreshing the report: %v", err)
+		} else {
+			date, _ := opts.reportDate()
+			log.Printf("updated %s", output.pathFor(date))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh writes today's report up to now, returning the whole day's chunks.
+func (o *reportOptions) refresh(ctx context.Context, calendarService *calendar.Service, output *outputOptions) ([]*Chunk, error) {
+	date, err := o.reportDate()
+	if err != nil {
+		return nil, err
+	}
+	config, err := o.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := o.buildDay(ctx, calendarService, date)
+	if err != nil {
+		return nil, err
+	}
+	report := newReport(date, untilNow(chunks, time.Now()), o.columns(config))
+	return chunks, output.write([]*Report{report}, o.timeFormat)
+}
+
+// untilNow drops the chunks that have not started yet and cuts the one under
+// way short at now.
+func untilNow(chunks []*Chunk, now time.Time) []*Chunk {
+	kept := make([]*Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if !chunk.start.Before(now) {
+			continue
+		}
+		if chunk.end.After(now) {
+			cut := *chunk
+			cut.end = now
+			chunk = &cut
+		}
+		kept = append(kept, chunk)
+	}
+	return kept
+}
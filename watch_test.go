@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_untilNow(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []*Chunk{
+		{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour)},
+		{start: date.Add(10 * time.Hour), end: date.Add(12 * time.Hour)},
+		{start: date.Add(12 * time.Hour), end: date.Add(17 * time.Hour)},
+	}
+	now := date.Add(11 * time.Hour)
+
+	kept := untilNow(chunks, now)
+
+	if len(kept) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(kept))
+	}
+	if !kept[1].end.Equal(now) {
+		t.Errorf("expected the current chunk to end now, got %s", formatTime(kept[1].end))
+	}
+	if !chunks[1].end.Equal(date.Add(12 * time.Hour)) {
+		t.Errorf("expected the original chunk to be left alone")
+	}
+}
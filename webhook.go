@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// signatureHeader carries the hex HMAC-SHA256 of the webhook's body, keyed
+// with $CHUNKIT_WEBHOOK_SECRET, as "sha256=<hex>".
+const signatureHeader = "X-Chunkit-Signature"
+
+// webhookPusher POSTs the day's chunks as JSON to a URL, in the format
+// plugins read.
+type webhookPusher struct {
+	url     *string
+	headers headerFlags
+}
+
+func newWebhookPusher(fs *flag.FlagSet) pusher {
+	p := &webhookPusher{
+		url: fs.String("url", "", "URL to POST the chunks to (signed with $CHUNKIT_WEBHOOK_SECRET if set)"),
+	}
+	fs.Var(&p.headers, "header", "Header to send as `name: value`, e.g. an Authorization header; can be repeated")
+	return p
+}
+
+// headerFlags collects repeated -header flags.
+type headerFlags []string
+
+func (h *headerFlags) String() string { return strings.Join(*h, ", ") }
+
+func (h *headerFlags) Set(value string) error {
+	name, _, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("must be name: value, got %q", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+func (p *webhookPusher) push(ctx context.Context, config *Config, date time.Time, chunks []*Chunk, dryRun bool) error {
+	if *p.url == "" {
+		return errors.New("-url is required")
+	}
+	body, err := json.Marshal(newPluginRequest(config, "webhook", date, chunks, dryRun))
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Printf("(dry run) POST %d chunks to %s\n", len(chunks), *p.url)
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, header := range p.headers {
+		name, value, _ := strings.Cut(header, ":")
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if secret := os.Getenv("CHUNKIT_WEBHOOK_SECRET"); secret != "" {
+		req.Header.Set(signatureHeader, "sha256="+sign(secret, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return withExitCode(exitAPI, fmt.Errorf("error calling the webhook: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return withExitCode(exitAPI, fmt.Errorf("error calling the webhook: %s", resp.Status))
+	}
+	fmt.Printf("Sent %d chunks to %s\n", len(chunks), *p.url)
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of body keyed with secret.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_webhookPusher(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	var (
+		body      []byte
+		signature string
+		auth      string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(signatureHeader)
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	t.Setenv("CHUNKIT_WEBHOOK_SECRET", "secret")
+
+	fs := flag.NewFlagSet("push webhook", flag.ContinueOnError)
+	p := newWebhookPusher(fs)
+	if err := fs.Parse([]string{"-url", server.URL, "-header", "Authorization: Bearer token"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	chunks := []*Chunk{{start: date.Add(9 * time.Hour), end: date.Add(10 * time.Hour), notes: "ACME sync"}}
+	if err := p.push(context.Background(), &Config{}, date, chunks, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(string(body), `"notes":"ACME sync"`) {
+		t.Errorf("expected the chunks as JSON, got %s", body)
+	}
+	if expected := "sha256=" + sign("secret", body); signature != expected {
+		t.Errorf("expected signature '%s', got '%s'", expected, signature)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("expected the Authorization header 'Bearer token', got '%s'", auth)
+	}
+
+	if err := fs.Parse([]string{"-header", "no colon"}); err == nil {
+		t.Errorf("expected an error for a header without a value")
+	}
+}
+
+func Test_sign(t *testing.T) {
+	// RFC 4231 test case 2
+	expected := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got := sign("Jefe", []byte("what do ya want for nothing?")); got != expected {
+		t.Errorf("expected '%s', got '%s'", expected, got)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule is the workday window per weekday, keyed by a day such as
+// "friday" or "fri", or a range such as "mon-thu". Weekdays it leaves out
+// keep the default 9 to 5, and the weekend stays off.
+type Schedule map[string]Workday
+
+// Workday is a day's window as HH:MM times, or Off for days not worked.
+// Events on days off still count, but no unallocated time is invented.
+type Workday struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Off   bool   `json:"off"`
+}
+
+var weekdayNames = map[string]time.Weekday{}
+
+func init() {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		weekdayNames[name] = day
+		weekdayNames[name[:3]] = day
+	}
+}
+
+// weekdays resolves a Schedule key to its days.
+func weekdays(key string) ([]time.Weekday, error) {
+	from, to, isRange := strings.Cut(strings.ToLower(key), "-")
+	first, ok := weekdayNames[strings.TrimSpace(from)]
+	if !ok {
+		return nil, fmt.Errorf("invalid workday %q: must be a weekday such as friday or fri, or a range such as mon-thu", key)
+	}
+	if !isRange {
+		return []time.Weekday{first}, nil
+	}
+	last, ok := weekdayNames[strings.TrimSpace(to)]
+	if !ok {
+		return nil, fmt.Errorf("invalid workday %q: must be a weekday such as friday or fri, or a range such as mon-thu", key)
+	}
+	days := []time.Weekday{first}
+	for day := first; day != last; {
+		day = (day + 1) % 7
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func (s Schedule) validate() error {
+	seen := map[time.Weekday]string{}
+	for key, workday := range s {
+		days, err := weekdays(key)
+		if err != nil {
+			return err
+		}
+		for _, day := range days {
+			if other, ok := seen[day]; ok {
+				return fmt.Errorf("invalid workday %q: %s is also in %q", key, day, other)
+			}
+			seen[day] = key
+		}
+		if workday.Off {
+			continue
+		}
+		start, err := parseClock(workday.Start)
+		if err != nil {
+			return fmt.Errorf("invalid start of workday %q: %v", key, err)
+		}
+		end, err := parseClock(workday.End)
+		if err != nil {
+			return fmt.Errorf("invalid end of workday %q: %v", key, err)
+		}
+		if end <= start {
+			return fmt.Errorf("invalid workday %q: must end after it starts", key)
+		}
+	}
+	return nil
+}
+
+// window returns when the workday of date, midnight, starts and ends. Days
+// off, those works says aren't worked, start and end at midnight.
+func (s Schedule) window(date time.Time) (time.Time, time.Time) {
+	for key, workday := range s {
+		days, _ := weekdays(key)
+		for _, day := range days {
+			if day != date.Weekday() {
+				continue
+			}
+			if workday.Off {
+				return date, date
+			}
+			start, _ := parseClock(workday.Start)
+			end, _ := parseClock(workday.End)
+			return date.Add(start), date.Add(end)
+		}
+	}
+	if !s.works(date.Weekday()) {
+		return date, date
+	}
+	return date.Add(startOfDay * time.Hour), date.Add(endOfDay * time.Hour)
+}
+
+// works reports whether day is worked: Monday to Friday, unless the schedule
+// gives a window for the weekend or takes a weekday off.
+func (s Schedule) works(day time.Weekday) bool {
+	for key, workday := range s {
+		days, _ := weekdays(key)
+		for _, d := range days {
+			if d == day {
+				return !workday.Off
+			}
+		}
+	}
+	return day != time.Saturday && day != time.Sunday
+}
+
+// parseClock parses HH:MM as the time since midnight, up to 24:00.
+func parseClock(value string) (time.Duration, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(value, "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("%q must be HH:MM", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
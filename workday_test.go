@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Schedule_window(t *testing.T) {
+	schedule := Schedule{
+		"mon-thu":  {Start: "08:00", End: "17:00"},
+		"fri":      {Start: "08:00", End: "13:00"},
+		"saturday": {Off: true},
+	}
+	if err := schedule.validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		date          time.Time
+		expectedStart string
+		expectedEnd   string
+	}{
+		{date: monday, expectedStart: "08:00", expectedEnd: "17:00"},
+		{date: monday.AddDate(0, 0, 3), expectedStart: "08:00", expectedEnd: "17:00"},
+		{date: monday.AddDate(0, 0, 4), expectedStart: "08:00", expectedEnd: "13:00"},
+		{date: monday.AddDate(0, 0, 5), expectedStart: "00:00", expectedEnd: "00:00"},
+		{date: monday.AddDate(0, 0, 6), expectedStart: "00:00", expectedEnd: "00:00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.date.Weekday().String(), func(t *testing.T) {
+			start, end := schedule.window(test.date)
+			if start.Format("15:04") != test.expectedStart || end.Format("15:04") != test.expectedEnd {
+				t.Errorf("expected %s-%s, got %s-%s", test.expectedStart, test.expectedEnd, start.Format("15:04"), end.Format("15:04"))
+			}
+		})
+	}
+}
+
+func Test_Schedule_window_default(t *testing.T) {
+	saturday := time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		date          time.Time
+		expectedStart string
+		expectedEnd   string
+	}{
+		{date: saturday, expectedStart: "00:00", expectedEnd: "00:00"},
+		{date: saturday.AddDate(0, 0, 1), expectedStart: "00:00", expectedEnd: "00:00"},
+		{date: saturday.AddDate(0, 0, 2), expectedStart: "09:00", expectedEnd: "17:00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.date.Weekday().String(), func(t *testing.T) {
+			start, end := Schedule(nil).window(test.date)
+			if start.Format("15:04") != test.expectedStart || end.Format("15:04") != test.expectedEnd {
+				t.Errorf("expected %s-%s, got %s-%s", test.expectedStart, test.expectedEnd, start.Format("15:04"), end.Format("15:04"))
+			}
+		})
+	}
+}
+
+func Test_Schedule_validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+	}{
+		{name: "unknown day", schedule: Schedule{"someday": {Off: true}}},
+		{name: "overlapping days", schedule: Schedule{"mon-fri": {Off: true}, "friday": {Off: true}}},
+		{name: "invalid time", schedule: Schedule{"mon": {Start: "8am", End: "17:00"}}},
+		{name: "ends before it starts", schedule: Schedule{"mon": {Start: "17:00", End: "08:00"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.schedule.validate(); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func Test_Chunkify_schedule(t *testing.T) {
+	friday := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	schedule := Schedule{"fri": {Start: "08:00", End: "13:00"}, "sat-sun": {Off: true}}
+
+	chunks := Chunkify(friday, []*Event{newEvent(friday.Add(10*time.Hour), friday.Add(11*time.Hour), "Retro", "accepted", true)}, Options{Schedule: schedule})
+	total := time.Duration(0)
+	for _, chunk := range chunks {
+		total += chunk.end.Sub(chunk.start)
+	}
+	if total != 5*time.Hour {
+		t.Errorf("expected the half-day Friday to total 5 hours, got %s", total)
+	}
+
+	saturday := friday.AddDate(0, 0, 1)
+	if chunks := Chunkify(saturday, nil, Options{Schedule: schedule}); len(chunks) != 0 {
+		t.Errorf("expected no chunks on a day off without events, got %d", len(chunks))
+	}
+	chunks = Chunkify(saturday, []*Event{newEvent(saturday.Add(10*time.Hour), saturday.Add(12*time.Hour), "Release", "accepted", true)}, Options{Schedule: schedule})
+	if len(chunks) != 1 || chunks[0].notes != "Release" {
+		t.Errorf("expected only the event on a day off, got %d chunks", len(chunks))
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const formatXLSX = "xlsx"
+
+// cell styles, indexes into cellXfs in xlsxStyles
+const (
+	styleDefault = iota
+	styleHeader
+	styleClock
+	styleDuration
+)
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="2"><numFmt numFmtId="164" formatCode="hh:mm"/><numFmt numFmtId="165" formatCode="[h]:mm"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+</cellXfs>
+</styleSheet>`
+
+// xlsxCell is a spreadsheet cell: a string, a bool, or a float64 shown with
+// style, which for times and durations is a fraction of a day.
+type xlsxCell struct {
+	value any
+	style int
+}
+
+// writeXLSX writes the reports as a workbook with one sheet per day and a
+// summary sheet totalling the time per project.
+func writeXLSX(w io.Writer, reports []*Report) error {
+	var names []string
+	var sheets [][][]xlsxCell
+	totals := map[string]time.Duration{}
+	colors := map[string]string{} // the legend of the projects' colors
+	var total time.Duration
+
+	for _, r := range reports {
+		header := []xlsxCell{{"Start", styleHeader}, {"End", styleHeader}, {"Duration", styleHeader}}
+		if r.Notes {
+			header = append(header, xlsxCell{"Notes", styleHeader})
+		}
+		if r.Project {
+			header = append(header, xlsxCell{"Project", styleHeader})
+		}
+		if r.OnCallTracked {
+			header = append(header, xlsxCell{"On call", styleHeader})
+		}
+		if r.Explained {
+			header = append(header, xlsxCell{"Explanation", styleHeader})
+		}
+		if r.RulesMatched {
+			header = append(header, xlsxCell{"Matched rule", styleHeader})
+		}
+		if r.Linked {
+			header = append(header, xlsxCell{"Links", styleHeader})
+		}
+		if r.Located {
+			header = append(header, xlsxCell{"Location", styleHeader})
+		}
+		if r.Costed {
+			header = append(header, xlsxCell{"Cost", styleHeader})
+		}
+		rows := [][]xlsxCell{header}
+		for _, row := range r.Rows {
+			cells := []xlsxCell{
+				{days(row.Start.Sub(r.Date)), styleClock},
+				{days(row.End.Sub(r.Date)), styleClock},
+				{days(row.Duration()), styleDuration},
+			}
+			if r.Notes {
+				cells = append(cells, xlsxCell{row.Notes, styleDefault})
+			}
+			if r.Project {
+				cells = append(cells, xlsxCell{row.Project, styleDefault})
+			}
+			if r.OnCallTracked {
+				cells = append(cells, xlsxCell{row.OnCall, styleDefault})
+			}
+			if r.Explained {
+				cells = append(cells, xlsxCell{strings.Join(row.Explain, "; "), styleDefault})
+			}
+			if r.RulesMatched {
+				cells = append(cells, xlsxCell{strings.Join(row.MatchedRules, "; "), styleDefault})
+			}
+			if r.Linked {
+				cells = append(cells, xlsxCell{strings.Join(row.Links, " "), styleDefault})
+			}
+			if r.Located {
+				cells = append(cells, xlsxCell{row.Location, styleDefault})
+			}
+			if r.Costed {
+				cells = append(cells, xlsxCell{row.Cost, styleDefault})
+			}
+			rows = append(rows, cells)
+			totals[row.Project] += row.Duration()
+			colors[row.Project] = row.Color
+		}
+		total += r.Total
+		names = append(names, r.Date.Format(dateLayout))
+		sheets = append(sheets, rows)
+	}
+
+	projects := make([]string, 0, len(totals))
+	for project := range totals {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	summary := [][]xlsxCell{{{"Project", styleHeader}, {"Duration", styleHeader}, {"Color", styleHeader}}}
+	for _, project := range projects {
+		name := project
+		if name == "" {
+			name = "(none)"
+		}
+		summary = append(summary, []xlsxCell{{name, styleDefault}, {days(totals[project]), styleDuration}, {colors[project], styleDefault}})
+	}
+	summary = append(summary, []xlsxCell{{"Total", styleHeader}, {days(total), styleDuration}})
+	names = append([]string{"Summary"}, names...)
+	sheets = append([][][]xlsxCell{summary}, sheets...)
+
+	return writeWorkbook(w, names, sheets)
+}
+
+// days converts a duration to the fraction of a day spreadsheets count time in.
+func days(d time.Duration) float64 {
+	return d.Hours() / 24
+}
+
+func writeWorkbook(w io.Writer, names []string, sheets [][][]xlsxCell) error {
+	z := zip.NewWriter(w)
+
+	var overrides, workbook, rels strings.Builder
+	for i, name := range names {
+		n := i + 1
+		overrides.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n))
+		workbook.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), n, n))
+		rels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n))
+	}
+	rels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(names)+1))
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` + overrides.String() + `</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + workbook.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, rows := range sheets {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(rows)})
+	}
+
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("error writing the spreadsheet: %v", err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return fmt.Errorf("error writing the spreadsheet: %v", err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		return fmt.Errorf("error writing the spreadsheet: %v", err)
+	}
+	return nil
+}
+
+func worksheet(rows [][]xlsxCell) string {
+	buf := strings.Builder{}
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, cells := range rows {
+		buf.WriteString(fmt.Sprintf(`<row r="%d">`, i+1))
+		for j, cell := range cells {
+			ref := fmt.Sprintf("%c%d", 'A'+j, i+1)
+			switch v := cell.value.(type) {
+			case string:
+				buf.WriteString(fmt.Sprintf(`<c r="%s" s="%d" t="inlineStr"><is><t>%s</t></is></c>`, ref, cell.style, xmlEscape(v)))
+			case bool:
+				b := 0
+				if v {
+					b = 1
+				}
+				buf.WriteString(fmt.Sprintf(`<c r="%s" s="%d" t="b"><v>%d</v></c>`, ref, cell.style, b))
+			case float64:
+				buf.WriteString(fmt.Sprintf(`<c r="%s" s="%d"><v>%g</v></c>`, ref, cell.style, v))
+			}
+		}
+		buf.WriteString(`</row>`)
+	}
+	buf.WriteString(`</sheetData></worksheet>`)
+	return buf.String()
+}
+
+func xmlEscape(s string) string {
+	buf := bytes.Buffer{}
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_writeXLSX(t *testing.T) {
+	date := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	report := newReport(date, testChunks(date), reportColumns{notes: true, project: true})
+
+	buf := bytes.Buffer{}
+	if err := writeXLSX(&buf, []*Report{report}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("expected a zip file, got %v", err)
+	}
+	parts := map[string]string{}
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		parts[f.Name] = string(content)
+
+		// every part must be well-formed XML
+		d := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("expected %s to be valid XML, got %v", f.Name, err)
+			}
+		}
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="2024-03-11" sheetId="2" r:id="rId2"/>`) {
+		t.Errorf("expected a summary sheet and a sheet for the day, got %s", parts["xl/workbook.xml"])
+	}
+	// 1.5 hours of unassigned time, 1 hour on ACME, 2.5 hours in total
+	summary := parts["xl/worksheets/sheet1.xml"]
+	for _, cell := range []string{"(none)</t></is></c><c r=\"B2\" s=\"3\"><v>0.0625</v>", "ACME</t></is></c><c r=\"B3\" s=\"3\"><v>0.041666666666666664</v>", "<v>0.10416666666666667</v>"} {
+		if !strings.Contains(summary, cell) {
+			t.Errorf("expected summary to contain %s, got %s", cell, summary)
+		}
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet2.xml"], "<t>Standup</t>") {
+		t.Errorf("expected the day's sheet to have the notes, got %s", parts["xl/worksheets/sheet2.xml"])
+	}
+}